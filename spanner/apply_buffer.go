@@ -0,0 +1,239 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ApplyBuffer accumulates mutations on the client and applies them with
+// ApplyAtLeastOnce in a single commit when the buffer is flushed. This reduces
+// the number of commits for applications that would otherwise execute many
+// small ApplyAtLeastOnce calls.
+//
+// The buffer is flushed when:
+//   - the number of buffered mutations reaches the configured maximum,
+//   - the flush interval has elapsed since the last flush, or
+//   - Flush or Close is called.
+//
+// Atomicity and ordering: all mutations that are buffered at the time of a
+// flush are committed atomically in one commit. Mutations from different Add
+// calls may therefore be committed together, but the mutations of a single Add
+// call are never split across commits. Commits are executed one at a time in
+// the order in which the mutations were added. As the mutations are applied
+// with ApplyAtLeastOnce, a commit may be executed more than once, and
+// applications should only add idempotent mutations, for example
+// InsertOrUpdate, Replace or Delete.
+//
+// If a flush fails, the mutations of that flush are removed from the buffer
+// and returned in an *ApplyBufferFlushError, so a batch that can never be
+// committed does not block the buffer. The application can add the mutations
+// again to retry them. Errors of flushes that were started by the flush
+// interval are returned by the next call to Add, Flush or Close.
+//
+// An ApplyBuffer is safe for concurrent use by multiple goroutines.
+type ApplyBuffer struct {
+	c             *Client
+	flushInterval time.Duration
+	maxMutations  int
+
+	// flushMu ensures that only one commit is executed at any time, so that
+	// commits are executed in the order in which the mutations were added.
+	flushMu sync.Mutex
+
+	mu     sync.Mutex
+	buf    []*Mutation
+	err    error
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// ApplyBufferFlushError is returned by an ApplyBuffer when a flush fails.
+// Mutations contains the mutations that were not applied, in the order in
+// which they were added.
+type ApplyBufferFlushError struct {
+	// Mutations are the mutations that were removed from the buffer without
+	// being applied.
+	Mutations []*Mutation
+	// Err is the error that was returned for the commit.
+	Err error
+}
+
+// Error implements error.Error.
+func (e *ApplyBufferFlushError) Error() string {
+	return fmt.Sprintf("spanner: failed to apply %d buffered mutations: %v", len(e.Mutations), e.Err)
+}
+
+// Unwrap returns the error that was returned for the commit.
+func (e *ApplyBufferFlushError) Unwrap() error {
+	return e.Err
+}
+
+// errApplyBufferClosed returns error for adding mutations to or flushing an
+// ApplyBuffer that has been closed.
+func errApplyBufferClosed() error {
+	return spannerErrorf(codes.FailedPrecondition, "apply buffer is closed")
+}
+
+// NewApplyBuffer returns an ApplyBuffer that applies the added mutations with
+// ApplyAtLeastOnce. The buffer is flushed every flushInterval, and whenever the
+// number of buffered mutations reaches maxMutations. A flushInterval or
+// maxMutations that is zero or negative disables the corresponding trigger.
+//
+// The returned buffer must be closed with Close to flush any remaining
+// mutations and to stop the background flush.
+func (c *Client) NewApplyBuffer(flushInterval time.Duration, maxMutations int) *ApplyBuffer {
+	b := &ApplyBuffer{
+		c:             c,
+		flushInterval: flushInterval,
+		maxMutations:  maxMutations,
+		done:          make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		b.wg.Add(1)
+		go b.flushPeriodically()
+	}
+	return b
+}
+
+// flushPeriodically flushes the buffer every flushInterval until the buffer is
+// closed.
+func (b *ApplyBuffer) flushPeriodically() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			if err := b.flush(context.Background()); err != nil {
+				b.mu.Lock()
+				b.setErrLocked(err)
+				b.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Add adds the given mutations to the buffer. If the number of buffered
+// mutations reaches the maximum of the buffer, the buffer is flushed before
+// Add returns, and any error of that flush is returned.
+func (b *ApplyBuffer) Add(ctx context.Context, ms ...*Mutation) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errApplyBufferClosed()
+	}
+	if err := b.takeErrLocked(); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	b.buf = append(b.buf, ms...)
+	full := b.maxMutations > 0 && len(b.buf) >= b.maxMutations
+	b.mu.Unlock()
+	if full {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+// Flush applies all buffered mutations in a single commit.
+func (b *ApplyBuffer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errApplyBufferClosed()
+	}
+	err := b.takeErrLocked()
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return b.flush(ctx)
+}
+
+// Close stops the background flush and applies all mutations that are still
+// in the buffer. The buffer cannot be used after it has been closed.
+func (b *ApplyBuffer) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+	close(b.done)
+	b.wg.Wait()
+
+	b.mu.Lock()
+	err := b.takeErrLocked()
+	b.mu.Unlock()
+	if flushErr := b.flush(ctx); flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// takeErrLocked returns and clears the error of the last background flush.
+func (b *ApplyBuffer) takeErrLocked() error {
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// setErrLocked records the error of a background flush. The mutations of a
+// failed flush are added to the error that has not yet been returned, so that
+// none of them are lost if more than one background flush fails.
+func (b *ApplyBuffer) setErrLocked(err error) {
+	prev, ok := b.err.(*ApplyBufferFlushError)
+	next, nextOk := err.(*ApplyBufferFlushError)
+	if !ok || !nextOk {
+		b.err = err
+		return
+	}
+	b.err = &ApplyBufferFlushError{
+		Mutations: append(prev.Mutations, next.Mutations...),
+		Err:       next.Err,
+	}
+}
+
+// flush applies all buffered mutations. The mutations are removed from the
+// buffer and returned in an *ApplyBufferFlushError if the commit fails.
+func (b *ApplyBuffer) flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	ms := b.buf
+	b.buf = nil
+	b.mu.Unlock()
+	if len(ms) == 0 {
+		return nil
+	}
+	if _, err := b.c.Apply(ctx, ms, ApplyAtLeastOnce()); err != nil {
+		return &ApplyBufferFlushError{Mutations: ms, Err: err}
+	}
+	return nil
+}
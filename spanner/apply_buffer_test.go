@@ -0,0 +1,165 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+)
+
+func commitRequestsFromServer(server *MockedSpannerInMemTestServer) []*sppb.CommitRequest {
+	var commits []*sppb.CommitRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if commit, ok := req.(*sppb.CommitRequest); ok {
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
+
+func TestApplyBuffer_FlushOnMaxMutations(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	b := client.NewApplyBuffer(0, 3)
+	for i := int64(0); i < 2; i++ {
+		if err := b.Add(ctx, InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{i})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g, w := len(commitRequestsFromServer(server)), 0; g != w {
+		t.Fatalf("commit count mismatch before reaching max mutations\nGot: %d\nWant: %d", g, w)
+	}
+	if err := b.Add(ctx, InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(2)})); err != nil {
+		t.Fatal(err)
+	}
+	commits := commitRequestsFromServer(server)
+	if g, w := len(commits), 1; g != w {
+		t.Fatalf("commit count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(commits[0].Mutations), 3; g != w {
+		t.Fatalf("mutation count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if commits[0].GetSingleUseTransaction() == nil {
+		t.Fatal("buffered mutations were not applied with a single-use transaction")
+	}
+
+	// Close flushes the remaining mutations.
+	if err := b.Add(ctx, Delete("Accounts", Key{int64(1)})); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	commits = commitRequestsFromServer(server)
+	if g, w := len(commits), 1; g != w {
+		t.Fatalf("commit count mismatch after close\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(commits[0].Mutations), 1; g != w {
+		t.Fatalf("mutation count mismatch after close\nGot: %d\nWant: %d", g, w)
+	}
+	if err := b.Add(ctx, Delete("Accounts", Key{int64(1)})); ErrCode(err) != codes.FailedPrecondition {
+		t.Fatalf("error mismatch for Add after Close\nGot: %v\nWant: %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestApplyBuffer_FlushOnInterval(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	b := client.NewApplyBuffer(20*time.Millisecond, 0)
+	defer b.Close(ctx)
+	for i := int64(0); i < 4; i++ {
+		if err := b.Add(ctx, InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{i})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var commits []*sppb.CommitRequest
+	deadline := time.Now().Add(5 * time.Second)
+	for len(commits) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		commits = commitRequestsFromServer(server)
+	}
+	if g, w := len(commits), 1; g != w {
+		t.Fatalf("commit count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(commits[0].Mutations), 4; g != w {
+		t.Fatalf("mutation count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+func TestApplyBuffer_FailedFlushIsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	b := client.NewApplyBuffer(0, 0)
+	defer b.Close(ctx)
+	failed := []*Mutation{
+		InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(1)}),
+		InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(2)}),
+	}
+	if err := b.Add(ctx, failed...); err != nil {
+		t.Fatal(err)
+	}
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.InvalidArgument, "invalid mutation")},
+	})
+	err := b.Flush(ctx)
+	var flushErr *ApplyBufferFlushError
+	if !errors.As(err, &flushErr) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %T", err, flushErr)
+	}
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := flushErr.Mutations, failed; !testEqual(g, w) {
+		t.Fatalf("failed mutations mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	commitRequestsFromServer(server)
+
+	// The failed mutations are not included in the next flush.
+	if err := b.Add(ctx, Delete("Accounts", Key{int64(3)})); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	commits := commitRequestsFromServer(server)
+	if g, w := len(commits), 1; g != w {
+		t.Fatalf("commit count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := len(commits[0].Mutations), 1; g != w {
+		t.Fatalf("mutation count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
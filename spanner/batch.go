@@ -20,13 +20,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
+	"io"
 	"log"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/internal/trace"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/googleapis/gax-go/v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 )
@@ -69,6 +73,17 @@ type Partition struct {
 	pt   []byte
 	qreq *sppb.ExecuteSqlRequest
 	rreq *sppb.ReadRequest
+	// opt contains the options that were used to create this partition.
+	opt PartitionOptions
+
+	// EstimatedBytes is the estimated amount of data that the partition will
+	// read, as returned by Cloud Spanner. It can be used to assign partitions
+	// to workers in proportion to their size. EstimatedBytes is zero if Cloud
+	// Spanner did not return an estimate for the partition. Cloud Spanner
+	// currently does not return size estimates for partitions, so the field is
+	// zero for all partitions that are returned by PartitionRead and
+	// PartitionQuery, unless it is set by the application.
+	EstimatedBytes int64
 }
 
 // PartitionOptions specifies options for a PartitionQueryRequest and
@@ -80,12 +95,22 @@ type PartitionOptions struct {
 	PartitionBytes int64
 	// The desired maximum number of partitions to return.
 	MaxPartitions int64
+	// TargetBytesPerPartition is the amount of data that each partition
+	// should read, for example the number of rows that a worker should
+	// process multiplied by the size of a row. It is sent to Cloud Spanner as
+	// the desired data size of each partition if PartitionBytes is not set.
+	// Like PartitionBytes, it is a hint that Cloud Spanner may ignore.
+	TargetBytesPerPartition int64
 }
 
 // toProto converts a spanner.PartitionOptions into a sppb.PartitionOptions
 func (opt PartitionOptions) toProto() *sppb.PartitionOptions {
+	size := opt.PartitionBytes
+	if size == 0 {
+		size = opt.TargetBytesPerPartition
+	}
 	return &sppb.PartitionOptions{
-		PartitionSizeBytes: opt.PartitionBytes,
+		PartitionSizeBytes: size,
 		MaxPartitions:      opt.MaxPartitions,
 	}
 }
@@ -169,6 +194,7 @@ func (t *BatchReadOnlyTransaction) PartitionReadUsingIndexWithOptions(ctx contex
 		partitions = append(partitions, &Partition{
 			pt:   p.PartitionToken,
 			rreq: req,
+			opt:  opt,
 		})
 	}
 	return partitions, err
@@ -193,6 +219,14 @@ func (t *BatchReadOnlyTransaction) partitionQuery(ctx context.Context, statement
 		return nil, err
 	}
 	sid, client := sh.getID(), sh.getClient()
+	if err := t.statementFilter.check(statement.SQL); err != nil {
+		return nil, err
+	}
+	if t.rejectZeroTime {
+		if err := statement.checkZeroTime(); err != nil {
+			return nil, err
+		}
+	}
 	params, paramTypes, err := statement.convertParams()
 	if err != nil {
 		return nil, err
@@ -239,6 +273,7 @@ func (t *BatchReadOnlyTransaction) partitionQuery(ctx context.Context, statement
 		partitions = append(partitions, &Partition{
 			pt:   p.PartitionToken,
 			qreq: r,
+			opt:  opt,
 		})
 	}
 	return partitions, err
@@ -304,7 +339,8 @@ func (t *BatchReadOnlyTransaction) Cleanup(ctx context.Context) {
 }
 
 // Execute runs a single Partition obtained from PartitionRead or
-// PartitionQuery.
+// PartitionQuery. The iterator returns an error that wraps ErrStalePartition
+// if the partition token is no longer valid.
 func (t *BatchReadOnlyTransaction) Execute(ctx context.Context, p *Partition) *RowIterator {
 	var (
 		sh  *sessionHandle
@@ -348,7 +384,7 @@ func (t *BatchReadOnlyTransaction) Execute(ctx context.Context, p *Partition) *R
 			if metricErr := recordGFELatencyMetricsOT(ctx, md, "Execute", t.otConfig); metricErr != nil {
 				trace.TracePrintf(ctx, nil, "Error in recording GFE Latency through OpenTelemetry. Error: %v", metricErr)
 			}
-			return client, err
+			return &stalePartitionStream{client}, err
 		}
 	} else {
 		rpc = func(ctx context.Context, resumeToken []byte) (streamingReceiver, error) {
@@ -378,15 +414,103 @@ func (t *BatchReadOnlyTransaction) Execute(ctx context.Context, p *Partition) *R
 			if metricErr := recordGFELatencyMetricsOT(ctx, md, "Execute", t.otConfig); metricErr != nil {
 				trace.TracePrintf(ctx, nil, "Error in recording GFE Latency through OpenTelemetry. Error: %v", metricErr)
 			}
-			return client, err
+			return &stalePartitionStream{client}, err
 		}
 	}
-	return stream(
+	iter := stream(
 		contextWithOutgoingMetadata(ctx, sh.getMetadata(), t.disableRouteToLeader),
 		sh.session.logger,
 		rpc,
 		t.setTimestamp,
 		t.release)
+	iter.rowd.rowConfig = &rowConfig{
+		numericDecodeMode:  t.numericDecodeMode,
+		ignoreUnmappedCols: t.ignoreUnmappedCols,
+	}
+	iter.streamd.retryClassifier = t.retryClassifier
+	return iter
+}
+
+// ExecutePartitions executes the given partitions one after another and calls
+// f for each row that is returned, together with the index of the partition in
+// partitions. It returns the number of rows that each partition returned,
+// keyed by the index of the partition.
+//
+// If f or the execution of a partition returns an error, ExecutePartitions
+// stops and returns the error together with the row counts of the partitions
+// that were executed so far. The count of the partition that failed contains
+// the rows for which f was called without an error.
+//
+// To execute partitions in parallel, call Execute for each partition from a
+// separate goroutine or process instead.
+func (t *BatchReadOnlyTransaction) ExecutePartitions(ctx context.Context, partitions []*Partition, f func(partition int, r *Row) error) (map[int]int64, error) {
+	counts := make(map[int]int64, len(partitions))
+	for i, p := range partitions {
+		counts[i] = 0
+		if err := t.Execute(ctx, p).Do(func(r *Row) error {
+			if err := f(i, r); err != nil {
+				return err
+			}
+			counts[i]++
+			return nil
+		}); err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+// ErrStalePartition is wrapped in the error that is returned when Cloud
+// Spanner reports that the partition token of a partition that is executed is
+// no longer valid, for example because it has expired. The partition cannot be
+// executed again; the read or query must be re-partitioned in a new
+// BatchReadOnlyTransaction. Use errors.Is to check for this error.
+var ErrStalePartition = errors.New("spanner: stale partition")
+
+// stalePartitionTokenMsg is the message of the FAILED_PRECONDITION error that
+// Cloud Spanner returns for a partition token that is no longer valid.
+const stalePartitionTokenMsg = "Partition token is stale"
+
+// isStalePartitionError returns true if the given error is the error that
+// Cloud Spanner returns for a partition token that is no longer valid. Other
+// FAILED_PRECONDITION errors are not considered stale partition errors.
+func isStalePartitionError(err error) bool {
+	if err == nil || ErrCode(err) != codes.FailedPrecondition {
+		return false
+	}
+	return strings.Contains(err.Error(), stalePartitionTokenMsg)
+}
+
+// errStalePartition returns error for executing a partition with a partition
+// token that is no longer valid.
+func errStalePartition(err error) error {
+	return &Error{
+		Code: codes.FailedPrecondition,
+		err:  ErrStalePartition,
+		Desc: ErrDesc(err),
+	}
+}
+
+// partitionStream is the stream of an executed partition.
+type partitionStream interface {
+	Recv() (*sppb.PartialResultSet, error)
+	Context() context.Context
+}
+
+// stalePartitionStream wraps the stream of an executed partition and replaces
+// the error for a stale partition token with an error that wraps
+// ErrStalePartition.
+type stalePartitionStream struct {
+	partitionStream
+}
+
+// Recv implements streamingReceiver.Recv for stalePartitionStream.
+func (s *stalePartitionStream) Recv() (*sppb.PartialResultSet, error) {
+	res, err := s.partitionStream.Recv()
+	if isStalePartitionError(err) {
+		return res, errStalePartition(err)
+	}
+	return res, err
 }
 
 // MarshalBinary implements BinaryMarshaler.
@@ -442,6 +566,9 @@ func (p Partition) MarshalBinary() (data []byte, err error) {
 	if err := enc.Encode(data); err != nil {
 		return nil, err
 	}
+	if err := enc.Encode(p.EstimatedBytes); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
@@ -469,7 +596,26 @@ func (p *Partition) UnmarshalBinary(data []byte) error {
 		p.qreq = &sppb.ExecuteSqlRequest{}
 		err = proto.Unmarshal(d, p.qreq)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	// Partitions that were serialized by older versions of the client do not
+	// contain an estimated size.
+	if err := dec.Decode(&p.EstimatedBytes); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// GetPartitionOptions returns the options that were used to create the
+// partition. The options are not included in the binary encoding of a
+// partition, and are empty for a partition that was decoded with
+// UnmarshalBinary.
+func (p *Partition) GetPartitionOptions() PartitionOptions {
+	if p != nil {
+		return p.opt
+	}
+	return PartitionOptions{}
 }
 
 // GetPartitionToken returns partition token
@@ -17,12 +17,20 @@ limitations under the License.
 package spanner
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 
 	. "cloud.google.com/go/spanner/internal/testutil"
 )
@@ -32,6 +40,7 @@ func TestPartitionRoundTrip(t *testing.T) {
 	for i, want := range []Partition{
 		{rreq: &sppb.ReadRequest{Table: "t"}},
 		{qreq: &sppb.ExecuteSqlRequest{Sql: "sql"}},
+		{qreq: &sppb.ExecuteSqlRequest{Sql: "sql"}, EstimatedBytes: 1 << 20},
 	} {
 		got := serdesPartition(t, i, &want)
 		if !testEqual(got, want) {
@@ -40,6 +49,30 @@ func TestPartitionRoundTrip(t *testing.T) {
 	}
 }
 
+func TestPartitionUnmarshalWithoutEstimatedBytes(t *testing.T) {
+	t.Parallel()
+	// Encode a partition in the format of older versions of the client.
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	req, err := proto.Marshal(&sppb.ExecuteSqlRequest{Sql: "sql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{[]byte("token"), false, req} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got Partition
+	if err := got.UnmarshalBinary(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	want := Partition{pt: []byte("token"), qreq: &sppb.ExecuteSqlRequest{Sql: "sql"}}
+	if !testEqual(got, want) {
+		t.Errorf("got: %#v\nwant:%#v", got, want)
+	}
+}
+
 func TestBROTIDRoundTrip(t *testing.T) {
 	t.Parallel()
 	tm := time.Now()
@@ -101,9 +134,9 @@ func TestPartitionQuery_QueryOptions(t *testing.T) {
 			defer txn.Cleanup(ctx)
 
 			if tt.query.Options == nil {
-				ps, err = txn.PartitionQuery(ctx, stmt, PartitionOptions{0, 3})
+				ps, err = txn.PartitionQuery(ctx, stmt, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3})
 			} else {
-				ps, err = txn.PartitionQueryWithOptions(ctx, stmt, PartitionOptions{0, 3}, tt.query)
+				ps, err = txn.PartitionQueryWithOptions(ctx, stmt, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3}, tt.query)
 			}
 			if err != nil {
 				t.Fatal(err)
@@ -163,9 +196,9 @@ func TestPartitionQuery_ReadOptions(t *testing.T) {
 			defer txn.Cleanup(ctx)
 
 			if tt.read == nil {
-				ps, err = txn.PartitionRead(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, PartitionOptions{0, 3})
+				ps, err = txn.PartitionRead(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3})
 			} else {
-				ps, err = txn.PartitionReadWithOptions(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, PartitionOptions{0, 3}, *tt.read)
+				ps, err = txn.PartitionReadWithOptions(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3}, *tt.read)
 			}
 			if err != nil {
 				t.Fatal(err)
@@ -192,6 +225,76 @@ func TestPartitionQuery_ReadOptions(t *testing.T) {
 	}
 }
 
+func TestPartitionQuery_TargetBytesPerPartition(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	for _, tt := range []struct {
+		name string
+		opts PartitionOptions
+		want int64
+	}{
+		{"target", PartitionOptions{TargetBytesPerPartition: 64 << 20}, 64 << 20},
+		{"partition bytes take precedence", PartitionOptions{PartitionBytes: 1 << 20, TargetBytesPerPartition: 64 << 20}, 1 << 20},
+		{"unset", PartitionOptions{}, 0},
+	} {
+		txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		txn.Cleanup(ctx)
+		var got *sppb.PartitionOptions
+		for _, req := range drainRequestsFromServer(server.TestSpanner) {
+			if r, ok := req.(*sppb.PartitionQueryRequest); ok {
+				got = r.PartitionOptions
+			}
+		}
+		if got == nil {
+			t.Fatalf("%s: missing PartitionQueryRequest", tt.name)
+		}
+		if g, w := got.PartitionSizeBytes, tt.want; g != w {
+			t.Fatalf("%s: PartitionSizeBytes mismatch\nGot: %v\nWant: %v", tt.name, g, w)
+		}
+	}
+}
+
+func TestPartitionQuery_EstimatedBytes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Cleanup(ctx)
+	opts := PartitionOptions{MaxPartitions: 10, TargetBytesPerPartition: 64 << 20}
+	partitions, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partitions) == 0 {
+		t.Fatal("no partitions returned")
+	}
+	for i, p := range partitions {
+		// The mock server does not return size estimates.
+		if g, w := p.EstimatedBytes, int64(0); g != w {
+			t.Errorf("partition %d: EstimatedBytes mismatch\nGot: %v\nWant: %v", i, g, w)
+		}
+		if g, w := p.GetPartitionOptions(), opts; g != w {
+			t.Errorf("partition %d: options mismatch\nGot: %v\nWant: %v", i, g, w)
+		}
+	}
+}
+
 func TestPartitionQuery_Parallel(t *testing.T) {
 	ctx := context.Background()
 	server, client, teardown := setupMockedTestServer(t)
@@ -202,7 +305,7 @@ func TestPartitionQuery_Parallel(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer txn.Cleanup(ctx)
-	ps, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{0, 10})
+	ps, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{PartitionBytes: 0, MaxPartitions: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -242,3 +345,135 @@ func TestPartitionQuery_Parallel(t *testing.T) {
 		t.Errorf("Row count mismatch\nGot: %d\nWant: %d", g, w)
 	}
 }
+
+func TestPartitionQuery_StalePartition(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name      string
+		err       error
+		wantStale bool
+	}{
+		{
+			name:      "stale partition token",
+			err:       status.Error(codes.FailedPrecondition, "Partition token is stale"),
+			wantStale: true,
+		},
+		{
+			name: "other failed precondition",
+			err:  status.Error(codes.FailedPrecondition, "Table not found"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server, client, teardown := setupMockedTestServer(t)
+			defer teardown()
+
+			txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer txn.Cleanup(ctx)
+			ps, err := txn.PartitionQuery(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), PartitionOptions{MaxPartitions: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{
+				Errors: []error{test.err},
+			})
+			iter := txn.Execute(ctx, ps[0])
+			err = iter.Do(func(row *Row) error { return nil })
+			if g, w := ErrCode(err), codes.FailedPrecondition; g != w {
+				t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			if g, w := errors.Is(err, ErrStalePartition), test.wantStale; g != w {
+				t.Fatalf("stale partition error mismatch\nGot: %v\nWant: %v", g, w)
+			}
+
+			// The error is returned to the caller, and the read or query is
+			// not re-partitioned.
+			var partitionRequests, executeRequests int
+			for _, req := range drainRequestsFromServer(server.TestSpanner) {
+				switch req.(type) {
+				case *sppb.PartitionQueryRequest:
+					partitionRequests++
+				case *sppb.ExecuteSqlRequest:
+					executeRequests++
+				}
+			}
+			if g, w := partitionRequests, 1; g != w {
+				t.Fatalf("PartitionQuery request count mismatch\nGot: %d\nWant: %d", g, w)
+			}
+			if g, w := executeRequests, 1; g != w {
+				t.Fatalf("ExecuteSql request count mismatch\nGot: %d\nWant: %d", g, w)
+			}
+		})
+	}
+}
+
+func TestExecutePartitions_RowCounts(t *testing.T) {
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	txn, err := client.BatchReadOnlyTransaction(ctx, StrongRead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Cleanup(ctx)
+	ps, err := txn.PartitionQuery(ctx, NewStatement("SELECT Key, Value FROM Kv"), PartitionOptions{MaxPartitions: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(ps), 2; g != w {
+		t.Fatalf("partition count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	for i, numRows := range []int{3, 1} {
+		rows := make([]*structpb.ListValue, numRows)
+		for j := range rows {
+			rows[j] = &structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewStringValue(fmt.Sprintf("k%d-%d", i, j)),
+				structpb.NewStringValue("v"),
+			}}
+		}
+		if err := server.TestSpanner.PutPartitionResult(ps[i].pt, &StatementResult{
+			Type:      StatementResultResultSet,
+			ResultSet: &sppb.ResultSet{Metadata: KvMeta(), Rows: rows},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys := make(map[int][]string)
+	counts, err := txn.ExecutePartitions(ctx, ps, func(partition int, r *Row) error {
+		var key string
+		if err := r.ColumnByName("Key", &key); err != nil {
+			return err
+		}
+		keys[partition] = append(keys[partition], key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := counts, map[int]int64{0: 3, 1: 1}; !testEqual(g, w) {
+		t.Fatalf("row counts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := keys, map[int][]string{0: {"k0-0", "k0-1", "k0-2"}, 1: {"k1-0"}}; !testEqual(g, w) {
+		t.Fatalf("rows per partition mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// The counts of the partitions that were executed are returned with the
+	// error of the row function.
+	wantErr := errors.New("stop")
+	counts, err = txn.ExecutePartitions(ctx, ps, func(partition int, r *Row) error {
+		if partition == 1 {
+			return wantErr
+		}
+		return nil
+	})
+	if err != wantErr {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, wantErr)
+	}
+	if g, w := counts, map[int]int64{0: 3, 1: 0}; !testEqual(g, w) {
+		t.Fatalf("row counts mismatch after error\nGot: %v\nWant: %v", g, w)
+	}
+}
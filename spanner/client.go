@@ -18,9 +18,12 @@ package spanner
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
 	"regexp"
 	"strconv"
@@ -39,10 +42,12 @@ import (
 	gtransport "google.golang.org/api/transport/grpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 
+	adminpb "cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
 	vkit "cloud.google.com/go/spanner/apiv1"
 	"cloud.google.com/go/spanner/internal"
 
@@ -65,6 +70,14 @@ const (
 
 	// numChannels is the default value for NumChannels of client.
 	numChannels = 4
+
+	// sessionsPerChannel is the number of sessions per channel that is used
+	// to compute the number of channels with AutoScaleChannels.
+	sessionsPerChannel = 100
+
+	// maxAutoScaledChannels is the maximum number of channels that is
+	// computed with AutoScaleChannels.
+	maxAutoScaledChannels = 40
 )
 
 const (
@@ -111,6 +124,41 @@ type Client struct {
 	disableRouteToLeader bool
 	dro                  *sppb.DirectedReadOptions
 	otConfig             *openTelemetryConfig
+	mutationAuthorizer   func(ctx context.Context, m *Mutation) error
+	disableInlineBegin   bool
+	maxAbortedRetryDelay time.Duration
+	dialect              adminpb.DatabaseDialect
+	*clientSettings
+}
+
+// clientSettings contains the settings of a Client that are shared with all
+// transactions of the client. The settings are never changed after the
+// client has been created.
+type clientSettings struct {
+	// queryStatsSink is called with the statistics of each query that has
+	// been consumed. Queries are executed in PROFILE mode when it is set.
+	queryStatsSink func(stats *sppb.ResultSetStats, stmt Statement)
+	// numericDecodeMode is the NumericDecodeMode of the rows that are
+	// returned by reads and queries.
+	numericDecodeMode NumericDecodeMode
+	// ignoreUnmappedCols makes Row.ToStruct ignore the columns of the rows
+	// that do not map to a struct field.
+	ignoreUnmappedCols bool
+	// retryClassifier determines which errors of streaming reads, queries
+	// and commits are retried.
+	retryClassifier RetryClassifier
+	// rejectZeroTime makes transactions reject statement parameters and
+	// mutations that contain a zero time.Time value.
+	rejectZeroTime bool
+	// statementFilter rejects the statements that are not allowed by the
+	// StatementAllowPrefixes and StatementDenyPrefixes of the client.
+	statementFilter *statementFilter
+	// emptyStringAsNull makes transactions write NULL for DML statement
+	// parameters and mutation values that are empty strings.
+	emptyStringAsNull bool
+	// tableKeyArity is the number of primary key columns of the tables that
+	// have been registered with ClientConfig.TableKeyArity.
+	tableKeyArity map[string]int
 }
 
 // DatabaseName returns the full name of a database, e.g.,
@@ -212,7 +260,7 @@ func createGCPMultiEndpoint(cfg *grpcgcp.GCPMultiEndpointOptions, config ClientC
 			copts = append(copts, option.WithGRPCDialOption(do))
 		}
 
-		allOpts := allClientOpts(1, config.Compression, copts...)
+		allOpts := allClientOpts(1, config.Compression, config.MaxReceiveMessageSize, config.TLSConfig, copts...)
 
 		// Overwrite endpoint and pool config.
 		allOpts = append(allOpts,
@@ -287,6 +335,13 @@ type ClientConfig struct {
 	// override the default values.
 	CallOptions *vkit.CallOptions
 
+	// SessionCreationBackoff configures the backoff between the retries of
+	// the RPCs that create sessions. It overrides the retry settings of
+	// CreateSession and BatchCreateSessions in CallOptions.
+	//
+	// Defaults to the zero value, which keeps the default backoff.
+	SessionCreationBackoff SessionCreationBackoff
+
 	// UserAgent is the prefix to the user agent header. This is used to supply information
 	// such as application name or partner tool.
 	//
@@ -322,6 +377,32 @@ type ClientConfig struct {
 	//  Default: identity
 	Compression string
 
+	// MaxReceiveMessageSize is the maximum size in bytes of a single message
+	// that the client can receive from Cloud Spanner, for example a
+	// PartialResultSet of a query that returns large rows. A stream that
+	// receives a larger message fails with a ResourceExhausted error. The
+	// value must be between 0 and math.MaxInt32.
+	//
+	// Defaults to math.MaxInt32.
+	MaxReceiveMessageSize int
+
+	// TLSConfig is the TLS configuration that is used for the connections to
+	// Cloud Spanner, for example to connect through a proxy that requires a
+	// client certificate for mutual TLS. When set, it replaces the default
+	// transport credentials of the connections. The credentials that
+	// authenticate the requests, such as the credentials from
+	// option.WithCredentialsFile, are still sent with each request.
+	//
+	// Defaults to nil, which uses the default transport credentials.
+	TLSConfig *tls.Config
+
+	// DetectDialect makes NewClient query the dialect of the database, so
+	// that it can be returned by Client.Dialect. NewClient returns an error if
+	// the dialect cannot be queried.
+	//
+	// Defaults to false.
+	DetectDialect bool
+
 	// BatchTimeout specifies the timeout for a batch of sessions managed sessionClient.
 	BatchTimeout time.Duration
 
@@ -330,6 +411,153 @@ type ClientConfig struct {
 	// should be used for non-transactional reads or queries.
 	DirectedReadOptions *sppb.DirectedReadOptions
 
+	// QueryStatsSink is called with the query plan and execution statistics
+	// of each query that is executed by the Client, after all rows of the
+	// query have been consumed. When QueryStatsSink is set, all queries that
+	// would otherwise be executed in NORMAL mode are executed in PROFILE mode,
+	// in the same way as QueryWithStats. Note that PROFILE mode adds overhead
+	// to each query, both for collecting the statistics on the server and for
+	// returning them to the client.
+	//
+	// QueryStatsSink is not called for queries that return an error, or for
+	// queries that are stopped before all rows have been consumed.
+	QueryStatsSink func(stats *sppb.ResultSetStats, stmt Statement)
+
+	// NumericDecodeMode determines the Go type that is used for NUMERIC
+	// values in rows returned by the Client that are decoded into an
+	// *interface{}. The default decodes GoogleSQL NUMERIC values into a
+	// big.Rat and PostgreSQL NUMERIC values into a PGNumeric.
+	NumericDecodeMode NumericDecodeMode
+
+	// MutationAuthorizer is called for each mutation that is buffered in a
+	// read/write transaction with BufferWrite, that is applied with Apply, or
+	// that is written with BatchWrite. If MutationAuthorizer returns an error
+	// for a mutation, none of the mutations of that call are buffered or
+	// written, and the error is returned to the caller. For a read/write
+	// transaction the error also fails the transaction: it cannot be
+	// committed, even if the transaction function ignores the error.
+	//
+	// MutationAuthorizer is called with the context of the transaction or of
+	// the Apply or BatchWrite call, and may be called concurrently from
+	// multiple goroutines.
+	MutationAuthorizer func(ctx context.Context, m *Mutation) error
+
+	// RejectZeroTime makes the Client return an InvalidArgument error for
+	// mutations and statement parameters that contain a zero time.Time value,
+	// instead of writing the timestamp 0001-01-01T00:00:00Z. This applies to
+	// time.Time values, valid NullTime values, and pointers to and slices of
+	// these types. Use a NullTime with Valid=false to write a NULL value.
+	RejectZeroTime bool
+
+	// StatementAllowPrefixes and StatementDenyPrefixes restrict the SQL
+	// statements that the Client executes, including queries, DML statements
+	// and Partitioned DML statements. A statement that starts with one of
+	// the StatementDenyPrefixes, or that does not start with any of the
+	// StatementAllowPrefixes if these are set, is not sent to Spanner, and
+	// fails with an error that wraps ErrStatementRejected. The prefixes are
+	// compared case-insensitively, after removing the leading white space and
+	// comments of the statement. StatementDenyPrefixes takes precedence over
+	// StatementAllowPrefixes. All statements are allowed if both are empty.
+	StatementAllowPrefixes []string
+	StatementDenyPrefixes  []string
+
+	// SessionUsageObserver is called each time a session is returned to the
+	// session pool after it has been used for an operation, with the ID of
+	// the session, the name of the operation, and the duration for which the
+	// session was checked out of the pool. The name of the operation is one of
+	// SingleUse, ReadOnlyTransaction, ReadWriteTransaction,
+	// ReadWriteStmtBasedTransaction, ApplyAtLeastOnce, PartitionedUpdate or
+	// BatchWrite. The observer is also called for sessions that are removed
+	// from the pool after an operation, for example because the session was
+	// not found on Spanner.
+	//
+	// SessionUsageObserver is intended for debugging. It is called
+	// synchronously by the goroutine that returns the session, may be called
+	// concurrently from multiple goroutines, and should return quickly.
+	//
+	// Defaults to nil.
+	SessionUsageObserver func(sessionID string, op string, held time.Duration)
+
+	// TableKeyArity registers the number of primary key columns of tables by
+	// table name. The client has no knowledge of the schema of the database,
+	// and a Key with the wrong number of parts is otherwise only rejected by
+	// Spanner. When a table is registered, the keys of ReadRow, TryReadRow and
+	// of Delete mutations for the table are checked by the client, and an
+	// InvalidArgument error is returned if the number of parts of a key does
+	// not match the registered number. Key ranges are not checked. Table
+	// names must match exactly, and tables that are not registered are not
+	// checked.
+	TableKeyArity map[string]int
+
+	// DisableInlineBegin makes all read/write transactions that are started
+	// with ReadWriteTransaction or ReadWriteTransactionWithOptions start with
+	// an explicit BeginTransaction RPC, instead of including the begin of the
+	// transaction in the first statement of the transaction. Inlining the
+	// begin saves a round trip, but is not supported by all backends and
+	// proxies.
+	//
+	// Defaults to false.
+	DisableInlineBegin bool
+
+	// AutoScaleChannels makes the client compute the number of gRPC channels
+	// from the maximum number of opened sessions when NumChannels is zero.
+	// The client then uses one channel per 100 sessions in MaxOpened, rounded
+	// up, with at most 40 channels. The number of channels is not changed if
+	// it is set with NumChannels or option.WithGRPCConnectionPool.
+	//
+	// Defaults to false.
+	AutoScaleChannels bool
+
+	// EmptyStringAsNull makes the Client write NULL instead of an empty
+	// string for mutation values and DML statement parameters that are an
+	// empty Go string or a pointer to an empty Go string. This is intended
+	// for applications that use empty strings where NULL is meant. Writing
+	// NULL to a column that is NOT NULL fails. NullString values, and strings
+	// that are elements of arrays, are not changed. The parameters of
+	// statements that are executed with Query, including DML statements with
+	// a THEN RETURN clause, are not changed, as a query that compares a
+	// column with NULL returns no rows.
+	//
+	// Defaults to false.
+	EmptyStringAsNull bool
+
+	// MaxAbortedRetryDelay is the maximum delay before a read/write
+	// transaction that was aborted by Cloud Spanner is retried. The delay is
+	// the retry delay that is returned by Cloud Spanner in the RetryInfo of
+	// the Aborted error, or an exponential backoff if Cloud Spanner did not
+	// return a retry delay. The delay is capped at MaxAbortedRetryDelay. This
+	// also applies to the transactions that are retried by RunStmtBased.
+	//
+	// Defaults to DefaultRetryBackoff.Max.
+	MaxAbortedRetryDelay time.Duration
+
+	// IgnoreUnmappedColumns makes Row.ToStruct, and the functions that use
+	// it such as SelectAll, ignore the columns of the rows of the Client that
+	// do not map to a field of the struct, instead of returning an error. This
+	// is useful for queries that can return more columns than expected, for
+	// example SELECT * queries on tables that have new columns. With
+	// IgnoreUnmappedColumns, ToStruct decodes rows in the same way as
+	// Row.ToStructLenient.
+	//
+	// Defaults to false.
+	IgnoreUnmappedColumns bool
+
+	// RetryClassifier determines which errors are retried by the Client when
+	// it creates sessions, resumes streaming reads and queries, and retries
+	// transactions.
+	//
+	// Defaults to DefaultRetryClassifier.
+	RetryClassifier RetryClassifier
+
+	// OpenTelemetryMeterProvider is the MeterProvider that is used to record
+	// the OpenTelemetry metrics of the client, such as the session pool and
+	// GFE latency metrics, when OpenTelemetry metrics have been enabled with
+	// EnableOpenTelemetryMetrics. The client does not export the metrics
+	// itself. To ship the metrics to an OTLP collector, use a MeterProvider
+	// of the OpenTelemetry SDK with a reader that uses an OTLP metric
+	// exporter for the endpoint of the collector.
+	//
+	// Defaults to the global MeterProvider of OpenTelemetry.
 	OpenTelemetryMeterProvider metric.MeterProvider
 }
 
@@ -344,6 +572,7 @@ type openTelemetryConfig struct {
 	getSessionTimeoutsCount metric.Int64Counter
 	acquiredSessionsCount   metric.Int64Counter
 	releasedSessionsCount   metric.Int64Counter
+	sessionPoolWaitTime     metric.Float64Histogram
 	gfeLatency              metric.Int64Histogram
 	gfeHeaderMissingCount   metric.Int64Counter
 }
@@ -372,50 +601,25 @@ func NewClientWithConfig(ctx context.Context, database string, config ClientConf
 	return newClientWithConfig(ctx, database, config, nil, opts...)
 }
 
-func newClientWithConfig(ctx context.Context, database string, config ClientConfig, gme *grpcgcp.GCPMultiEndpoint, opts ...option.ClientOption) (c *Client, err error) {
+func newClientWithConfig(ctx context.Context, database string, config ClientConfig, pool gtransport.ConnPool, opts ...option.ClientOption) (c *Client, err error) {
 	// Validate database path.
 	if err := validDatabaseName(database); err != nil {
 		return nil, err
 	}
-
-	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.NewClient")
-	defer func() { trace.EndSpan(ctx, err) }()
-
-	// Append emulator options if SPANNER_EMULATOR_HOST has been set.
-	if emulatorAddr := os.Getenv("SPANNER_EMULATOR_HOST"); emulatorAddr != "" {
-		emulatorOpts := []option.ClientOption{
-			option.WithEndpoint(emulatorAddr),
-			option.WithGRPCDialOption(grpc.WithInsecure()),
-			option.WithoutAuthentication(),
-			internaloption.SkipDialSettingsValidation(),
-		}
-		opts = append(emulatorOpts, opts...)
+	if config.MaxReceiveMessageSize < 0 || config.MaxReceiveMessageSize > math.MaxInt32 {
+		return nil, errMaxReceiveMessageSizeOutOfRange(config.MaxReceiveMessageSize)
 	}
-
-	// Prepare gRPC channels.
-	hasNumChannelsConfig := config.NumChannels > 0
-	if config.NumChannels == 0 {
-		config.NumChannels = numChannels
+	if err := config.SessionCreationBackoff.validate(); err != nil {
+		return nil, err
 	}
 
-	var pool gtransport.ConnPool
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.NewClient")
+	defer func() { trace.EndSpan(ctx, err) }()
 
-	if gme != nil {
-		// Use GCPMultiEndpoint if provided.
-		pool = &gmeWrapper{gme}
-	} else {
-		// Create gtransport ConnPool as usual if MultiEndpoint is not used.
-		// gRPC options.
-		allOpts := allClientOpts(config.NumChannels, config.Compression, opts...)
-		pool, err = gtransport.DialPool(ctx, allOpts...)
-		if err != nil {
+	if pool == nil {
+		if pool, err = dialConnPool(ctx, config, opts...); err != nil {
 			return nil, err
 		}
-
-		if hasNumChannelsConfig && pool.Num() != config.NumChannels {
-			pool.Close()
-			return nil, spannerErrorf(codes.InvalidArgument, "Connection pool mismatch: NumChannels=%v, WithGRPCConnectionPool=%v. Only set one of these options, or set both to the same value.", config.NumChannels, pool.Num())
-		}
 	}
 
 	// TODO(loite): Remove as the original map cannot be changed by the user
@@ -446,7 +650,7 @@ func newClientWithConfig(ctx context.Context, database string, config ClientConf
 	}
 
 	// Create a session client.
-	sc := newSessionClient(pool, database, config.UserAgent, sessionLabels, config.DatabaseRole, config.DisableRouteToLeader, md, config.BatchTimeout, config.Logger, config.CallOptions)
+	sc := newSessionClient(pool, database, config.UserAgent, sessionLabels, config.DatabaseRole, config.DisableRouteToLeader, md, config.BatchTimeout, config.Logger, config.SessionCreationBackoff.callOptions(config.CallOptions, config.RetryClassifier))
 
 	// Create a OpenTelemetry configuration
 	otConfig, err := createOpenTelemetryConfig(config.OpenTelemetryMeterProvider, config.Logger, sc.id, database)
@@ -457,10 +661,12 @@ func newClientWithConfig(ctx context.Context, database string, config ClientConf
 	// To prevent data race in unit tests (ex: TestClient_SessionNotFound)
 	sc.mu.Lock()
 	sc.otConfig = otConfig
+	sc.databaseRoleProvider = config.SessionPoolConfig.DatabaseRoleProvider
 	sc.mu.Unlock()
 
 	// Create a session pool.
 	config.SessionPoolConfig.sessionLabels = sessionLabels
+	config.SessionPoolConfig.sessionUsageObserver = config.SessionUsageObserver
 	sp, err := newSessionPool(sc, config.SessionPoolConfig)
 	if err != nil {
 		sc.close()
@@ -480,10 +686,97 @@ func newClientWithConfig(ctx context.Context, database string, config ClientConf
 		disableRouteToLeader: config.DisableRouteToLeader,
 		dro:                  config.DirectedReadOptions,
 		otConfig:             otConfig,
+		mutationAuthorizer:   config.MutationAuthorizer,
+		disableInlineBegin:   config.DisableInlineBegin,
+		maxAbortedRetryDelay: config.MaxAbortedRetryDelay,
+		clientSettings: &clientSettings{
+			queryStatsSink:     config.QueryStatsSink,
+			numericDecodeMode:  config.NumericDecodeMode,
+			ignoreUnmappedCols: config.IgnoreUnmappedColumns,
+			retryClassifier:    config.RetryClassifier,
+			rejectZeroTime:     config.RejectZeroTime,
+			statementFilter:    newStatementFilter(config.StatementAllowPrefixes, config.StatementDenyPrefixes),
+			emptyStringAsNull:  config.EmptyStringAsNull,
+			tableKeyArity:      copyTableKeyArity(config.TableKeyArity),
+		},
+	}
+	if c.maxAbortedRetryDelay <= 0 {
+		c.maxAbortedRetryDelay = DefaultRetryBackoff.Max
+	}
+	if config.DetectDialect {
+		if c.dialect, err = c.detectDialect(ctx); err != nil {
+			c.Close()
+			return nil, err
+		}
 	}
 	return c, nil
 }
 
+// dialConnPool dials the pool of gRPC connections for a client with the
+// given configuration.
+func dialConnPool(ctx context.Context, config ClientConfig, opts ...option.ClientOption) (gtransport.ConnPool, error) {
+	// Append emulator options if SPANNER_EMULATOR_HOST has been set.
+	if emulatorAddr := os.Getenv("SPANNER_EMULATOR_HOST"); emulatorAddr != "" {
+		emulatorOpts := []option.ClientOption{
+			option.WithEndpoint(emulatorAddr),
+			option.WithGRPCDialOption(grpc.WithInsecure()),
+			option.WithoutAuthentication(),
+			internaloption.SkipDialSettingsValidation(),
+		}
+		opts = append(emulatorOpts, opts...)
+	}
+
+	// Prepare gRPC channels.
+	hasNumChannelsConfig := config.NumChannels > 0
+	if config.NumChannels == 0 {
+		config.NumChannels = numChannels
+		if config.AutoScaleChannels && config.MaxOpened > 0 {
+			config.NumChannels = autoScaledNumChannels(config.MaxOpened)
+		}
+	}
+	// gRPC options.
+	allOpts := allClientOpts(config.NumChannels, config.Compression, config.MaxReceiveMessageSize, config.TLSConfig, opts...)
+	pool, err := gtransport.DialPool(ctx, allOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if hasNumChannelsConfig && pool.Num() != config.NumChannels {
+		pool.Close()
+		return nil, spannerErrorf(codes.InvalidArgument, "Connection pool mismatch: NumChannels=%v, WithGRPCConnectionPool=%v. Only set one of these options, or set both to the same value.", config.NumChannels, pool.Num())
+	}
+	return pool, nil
+}
+
+// dialectSQL returns the dialect of the database. The query is valid in both
+// GoogleSQL and PostgreSQL.
+const dialectSQL = "SELECT option_value FROM information_schema.database_options WHERE option_name = 'database_dialect'"
+
+// detectDialect queries the dialect of the database of the client.
+func (c *Client) detectDialect(ctx context.Context) (adminpb.DatabaseDialect, error) {
+	iter := c.Single().Query(ctx, NewStatement(dialectSQL))
+	defer iter.Stop()
+	row, err := iter.Next()
+	if err == iterator.Done {
+		// Databases that do not report a dialect use GoogleSQL.
+		return adminpb.DatabaseDialect_GOOGLE_STANDARD_SQL, nil
+	}
+	if err != nil {
+		return adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED, err
+	}
+	var dialect string
+	if err := row.Column(0, &dialect); err != nil {
+		return adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED, err
+	}
+	return adminpb.DatabaseDialect(adminpb.DatabaseDialect_value[dialect]), nil
+}
+
+// Dialect returns the dialect of the database of the client. The dialect is
+// only known if the client was created with ClientConfig.DetectDialect, and
+// is DATABASE_DIALECT_UNSPECIFIED otherwise.
+func (c *Client) Dialect() adminpb.DatabaseDialect {
+	return c.dialect
+}
+
 // NewMultiEndpointClient is the same as NewMultiEndpointClientWithConfig with
 // the default client configuration.
 //
@@ -519,17 +812,25 @@ func NewMultiEndpointClientWithConfig(ctx context.Context, database string, conf
 	}
 	// Align number of channels.
 	config.NumChannels = int(gme.GCPConfig().GetChannelPool().GetMaxSize())
-	c, err = newClientWithConfig(ctx, database, config, gme, opts...)
+	c, err = newClientWithConfig(ctx, database, config, &gmeWrapper{gme}, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
 	return
 }
 
+// errMaxReceiveMessageSizeOutOfRange returns error for
+// ClientConfig.MaxReceiveMessageSize < 0 or
+// ClientConfig.MaxReceiveMessageSize > math.MaxInt32.
+func errMaxReceiveMessageSizeOutOfRange(size int) error {
+	return spannerErrorf(codes.InvalidArgument,
+		"require ClientConfig.MaxReceiveMessageSize >= 0 && ClientConfig.MaxReceiveMessageSize <= %d, got %d", math.MaxInt32, size)
+}
+
 // Combines the default options from the generated client, the default options
 // of the hand-written client and the user options to one list of options.
 // Precedence: userOpts > clientDefaultOpts > generatedDefaultOpts
-func allClientOpts(numChannels int, compression string, userOpts ...option.ClientOption) []option.ClientOption {
+func allClientOpts(numChannels int, compression string, maxReceiveMessageSize int, tlsConfig *tls.Config, userOpts ...option.ClientOption) []option.ClientOption {
 	generatedDefaultOpts := vkit.DefaultClientOptions()
 	clientDefaultOpts := []option.ClientOption{
 		option.WithGRPCConnectionPool(numChannels),
@@ -543,10 +844,27 @@ func allClientOpts(numChannels int, compression string, userOpts ...option.Clien
 		userOpts = append(userOpts, option.WithGRPCDialOption(grpc.WithDefaultCallOptions(
 			grpc.UseCompressor(gzip.Name))))
 	}
+	if maxReceiveMessageSize > 0 {
+		userOpts = append(userOpts, option.WithGRPCDialOption(grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(maxReceiveMessageSize))))
+	}
+	if tlsConfig != nil {
+		userOpts = append(userOpts, option.WithGRPCDialOption(grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))))
+	}
 	allDefaultOpts := append(generatedDefaultOpts, clientDefaultOpts...)
 	return append(allDefaultOpts, userOpts...)
 }
 
+// autoScaledNumChannels returns the number of channels to use for the given
+// maximum number of opened sessions when AutoScaleChannels is enabled.
+func autoScaledNumChannels(maxOpened uint64) int {
+	n := (maxOpened + sessionsPerChannel - 1) / sessionsPerChannel
+	if n > maxAutoScaledChannels {
+		return maxAutoScaledChannels
+	}
+	return int(n)
+}
+
 // getQueryOptions returns the query options overwritten by the environment
 // variables if exist. The input parameter is the query options set by users
 // via application-level configuration. If the environment variables are set,
@@ -576,6 +894,78 @@ func (c *Client) Close() {
 	c.sc.close()
 }
 
+// CloseWithTimeout closes the client gracefully. The client stops handing out
+// sessions to new transactions, and waits until the sessions that are in use
+// by running transactions have been returned, or until ctx is done. The
+// sessions are then deleted and the connections of the client are closed.
+//
+// CloseWithTimeout returns an error that lists the IDs of the sessions that
+// were still in use if ctx is done before all sessions have been returned.
+// The client is closed in that case as well, and transactions that are still
+// running will fail.
+func (c *Client) CloseWithTimeout(ctx context.Context) error {
+	var err error
+	if c.idleSessions != nil {
+		deleteCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = c.idleSessions.closeWithTimeout(ctx, deleteCtx)
+	}
+	c.sc.close()
+	return err
+}
+
+// PauseMaintenance pauses the background maintenance of the session pool of
+// the client until ResumeMaintenance is called. While maintenance is paused,
+// the session pool does not send keep-alive requests for idle sessions, and
+// does not grow, shrink or remove sessions in the background. This can be
+// used to prevent the maintenance from competing with the application for
+// sessions and channels during a bulk operation. Sessions that are taken from
+// the pool are still created and checked as needed.
+//
+// Maintenance should not be paused for longer than necessary, as idle
+// sessions that are not kept alive are deleted by Cloud Spanner after one
+// hour.
+func (c *Client) PauseMaintenance() {
+	if c.idleSessions != nil {
+		c.idleSessions.hc.setPaused(true)
+	}
+}
+
+// ResumeMaintenance resumes the background maintenance of the session pool
+// of the client after it has been paused with PauseMaintenance.
+func (c *Client) ResumeMaintenance() {
+	if c.idleSessions != nil {
+		c.idleSessions.hc.setPaused(false)
+	}
+}
+
+// PoolStats returns a snapshot of the utilization of the session pool of the
+// client. The snapshot is taken while holding the lock of the session pool,
+// and is cheap enough to be called periodically, for example to export the
+// values to a custom metrics system.
+func (c *Client) PoolStats() PoolStats {
+	if c.idleSessions == nil {
+		return PoolStats{}
+	}
+	return c.idleSessions.stats()
+}
+
+// WarmUp blocks until the session pool of the client contains
+// SessionPoolConfig.MinOpened sessions, or until ctx is done. The session
+// pool creates these sessions in the background when the client is created,
+// and WarmUp can be used to wait for these sessions before the client is used
+// to serve requests. WarmUp starts the creation of the missing sessions if an
+// earlier attempt to create them failed, and returns the error of the session
+// creation if the sessions could not be created.
+//
+// WarmUp can be called multiple times and from multiple goroutines.
+func (c *Client) WarmUp(ctx context.Context) error {
+	if c.idleSessions == nil {
+		return errInvalidSessionPool
+	}
+	return c.idleSessions.warmUp(ctx)
+}
+
 // Single provides a read-only snapshot transaction optimized for the case
 // where only a single read or query is needed.  This is more efficient than
 // using ReadOnlyTransaction() for a single read or query.
@@ -590,6 +980,7 @@ func (c *Client) Single() *ReadOnlyTransaction {
 	t.txReadOnly.sp = c.idleSessions
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.clientSettings = c.clientSettings
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = true
 	t.txReadOnly.replaceSessionFunc = func(ctx context.Context) error {
@@ -598,6 +989,14 @@ func (c *Client) Single() *ReadOnlyTransaction {
 		}
 		// Remove the session that returned 'Session not found' from the pool.
 		t.sh.destroy()
+		// Pin the read timestamp if the stream has already returned one, so
+		// that a resumed stream reads from the same snapshot on the new
+		// session.
+		t.mu.Lock()
+		if !t.rts.IsZero() {
+			t.tb = ReadTimestamp(t.rts)
+		}
+		t.mu.Unlock()
 		// Reset the transaction, acquire a new session and retry.
 		t.state = txNew
 		sh, _, err := t.acquire(ctx)
@@ -631,6 +1030,7 @@ func (c *Client) ReadOnlyTransaction() *ReadOnlyTransaction {
 	t.txReadOnly.sp = c.idleSessions
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.clientSettings = c.clientSettings
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = true
 	t.txReadOnly.qo.DirectedReadOptions = c.dro
@@ -640,6 +1040,21 @@ func (c *Client) ReadOnlyTransaction() *ReadOnlyTransaction {
 	return t
 }
 
+// ReadOnlyTransactionAfter returns a ReadOnlyTransaction that reads at
+// exactly the given commit timestamp, for example the commit timestamp that
+// is returned by ReadWriteTransaction or Apply. All reads and queries in the
+// transaction see the data that was committed at or before commitTs,
+// including the data of the transaction that committed at commitTs, and the
+// same snapshot is used for all reads of the transaction. You must call
+// Close() when the ReadOnlyTransaction is no longer needed to release
+// resources on the server.
+//
+// Note that the reads fail if commitTs is older than the version retention
+// period of the database.
+func (c *Client) ReadOnlyTransactionAfter(commitTs time.Time) *ReadOnlyTransaction {
+	return c.ReadOnlyTransaction().WithTimestampBound(ReadTimestamp(commitTs))
+}
+
 // BatchReadOnlyTransaction returns a BatchReadOnlyTransaction that can be used
 // for partitioned reads or queries from a snapshot of the database. This is
 // useful in batch processing pipelines where one wants to divide the work of
@@ -702,6 +1117,7 @@ func (c *Client) BatchReadOnlyTransaction(ctx context.Context, tb TimestampBound
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.clientSettings = c.clientSettings
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = true
 	t.txReadOnly.qo.DirectedReadOptions = c.dro
@@ -736,6 +1152,7 @@ func (c *Client) BatchReadOnlyTransactionFromID(tid BatchReadOnlyTransactionID)
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.clientSettings = c.clientSettings
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = true
 	t.txReadOnly.qo.DirectedReadOptions = c.dro
@@ -797,6 +1214,9 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 	if err := checkNestedTxn(ctx); err != nil {
 		return resp, err
 	}
+	if err := c.txo.merge(options).validate(); err != nil {
+		return resp, err
+	}
 	var (
 		sh      *sessionHandle
 		t       *ReadWriteTransaction
@@ -807,13 +1227,14 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 			sh.recycle()
 		}
 	}()
-	err = runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx, func(ctx context.Context) error {
+	err = runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx, c.maxAbortedRetryDelay, c.retryClassifier, func(ctx context.Context) error {
 		var (
-			err error
+			err           error
+			explicitBegin bool
 		)
 		if sh == nil || sh.getID() == "" || sh.getClient() == nil {
 			// Session handle hasn't been allocated or has been destroyed.
-			sh, err = c.idleSessions.take(ctx)
+			sh, err = c.idleSessions.takeFor(ctx, "ReadWriteTransaction")
 			if err != nil {
 				// If session retrieval fails, just fail the transaction.
 				return err
@@ -836,17 +1257,27 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 				txReadyOrClosed: make(chan struct{}),
 			}
 			t.txReadOnly.sh = sh
+			// The transaction is begun with an explicit BeginTransaction RPC
+			// below, once the options of the transaction have been set.
+			explicitBegin = c.disableInlineBegin
 		}
 		attempt++
 		t.txReadOnly.sp = c.idleSessions
 		t.txReadOnly.txReadEnv = t
 		t.txReadOnly.qo = c.qo
+		t.txReadOnly.clientSettings = c.clientSettings
 		t.txReadOnly.ro = c.ro
 		t.txReadOnly.disableRouteToLeader = c.disableRouteToLeader
 		t.wb = []*Mutation{}
+		t.authorizeMutations = c.mutationAuthorizerFunc(ctx)
 		t.txOpts = c.txo.merge(options)
 		t.ct = c.ct
 		t.otConfig = c.otConfig
+		if explicitBegin {
+			if err = t.begin(ctx); err != nil {
+				return ToSpannerError(err)
+			}
+		}
 
 		trace.TracePrintf(ctx, map[string]interface{}{"transactionSelector": t.getTransactionSelector().String()},
 			"Starting transaction attempt")
@@ -870,6 +1301,9 @@ type applyOption struct {
 	// will not be recorded in allowed tracking change streams with DDL option
 	// allow_txn_exclusion=true.
 	excludeTxnFromChangeStreams bool
+	// maxCommitDelay is the max commit delay that is used for the commit
+	// operation.
+	maxCommitDelay *time.Duration
 }
 
 // An ApplyOption is an optional argument to Apply.
@@ -915,8 +1349,37 @@ func ExcludeTxnFromChangeStreams() ApplyOption {
 	}
 }
 
+// MaxCommitDelay returns an ApplyOption that sets the amount of latency that
+// the commit may be delayed by Cloud Spanner to batch it with other commits to
+// improve throughput. The delay must be between 0 and 500ms. Apply returns an
+// InvalidArgument error for a delay outside this range.
+func MaxCommitDelay(delay time.Duration) ApplyOption {
+	return func(ao *applyOption) {
+		ao.maxCommitDelay = &delay
+	}
+}
+
 // Apply applies a list of mutations atomically to the database.
 func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption) (commitTimestamp time.Time, err error) {
+	resp, err := c.apply(ctx, ms, false, opts...)
+	return resp.CommitTs, err
+}
+
+// ApplyWithResponse applies a list of mutations atomically to the database in
+// the same way as Apply, and returns the CommitResponse of the commit. The
+// commit statistics are always requested from Cloud Spanner, and are
+// returned in the CommitStats field of the response.
+func (c *Client) ApplyWithResponse(ctx context.Context, ms []*Mutation, opts ...ApplyOption) (*CommitResponse, error) {
+	resp, err := c.apply(ctx, ms, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// apply applies a list of mutations atomically to the database, and requests
+// the commit statistics of the commit if returnCommitStats is true.
+func (c *Client) apply(ctx context.Context, ms []*Mutation, returnCommitStats bool, opts ...ApplyOption) (resp CommitResponse, err error) {
 	ao := &applyOption{}
 
 	for _, opt := range c.ao {
@@ -931,15 +1394,50 @@ func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption)
 	defer func() { trace.EndSpan(ctx, err) }()
 
 	if !ao.atLeastOnce {
-		resp, err := c.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, t *ReadWriteTransaction) error {
+		return c.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, t *ReadWriteTransaction) error {
 			return t.BufferWrite(ms)
-		}, TransactionOptions{CommitPriority: ao.priority, TransactionTag: ao.transactionTag, ExcludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams})
-		return resp.CommitTs, err
+		}, TransactionOptions{CommitPriority: ao.priority, TransactionTag: ao.transactionTag, ExcludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams, CommitOptions: CommitOptions{ReturnCommitStats: returnCommitStats, MaxCommitDelay: ao.maxCommitDelay}})
+	}
+	if authorize := c.mutationAuthorizerFunc(ctx); authorize != nil {
+		if err := authorize(ms); err != nil {
+			return CommitResponse{}, err
+		}
 	}
-	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag, disableRouteToLeader: c.disableRouteToLeader, excludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams}
+	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag, disableRouteToLeader: c.disableRouteToLeader, excludeTxnFromChangeStreams: ao.excludeTxnFromChangeStreams, returnCommitStats: returnCommitStats, maxCommitDelay: ao.maxCommitDelay, clientSettings: c.clientSettings}
 	return t.applyAtLeastOnce(ctx, ms...)
 }
 
+// copyTableKeyArity returns a copy of the given table key arity registry, so
+// that later changes to the map of the ClientConfig do not affect the client.
+func copyTableKeyArity(arity map[string]int) map[string]int {
+	if arity == nil {
+		return nil
+	}
+	c := make(map[string]int, len(arity))
+	for table, n := range arity {
+		c[table] = n
+	}
+	return c
+}
+
+// mutationAuthorizerFunc returns a function that checks a list of mutations
+// with the MutationAuthorizer of the client using the given context, or nil
+// if the client has no MutationAuthorizer.
+func (c *Client) mutationAuthorizerFunc(ctx context.Context) func([]*Mutation) error {
+	if c.mutationAuthorizer == nil {
+		return nil
+	}
+	authorize := c.mutationAuthorizer
+	return func(ms []*Mutation) error {
+		for _, m := range ms {
+			if err := authorize(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // BatchWriteOptions provides options for a BatchWriteRequest.
 type BatchWriteOptions struct {
 	// Priority is the RPC priority to use for this request.
@@ -1100,13 +1598,34 @@ func (c *Client) BatchWriteWithOptions(ctx context.Context, mgs []*MutationGroup
 
 	opts = c.bwo.merge(opts)
 
+	if c.emptyStringAsNull {
+		mgs = emptyStringsAsNullInGroups(mgs)
+	}
+	if c.rejectZeroTime {
+		for _, mg := range mgs {
+			if err = checkZeroTime(mg.Mutations); err != nil {
+				return &BatchWriteResponseIterator{err: err}
+			}
+		}
+	}
+	authorize := c.mutationAuthorizerFunc(ctx)
+	for _, mg := range mgs {
+		if err = checkMutationKeyArity(c.tableKeyArity, mg.Mutations); err != nil {
+			return &BatchWriteResponseIterator{err: err}
+		}
+		if authorize != nil {
+			if err = authorize(mg.Mutations); err != nil {
+				return &BatchWriteResponseIterator{err: err}
+			}
+		}
+	}
 	mgsPb, err := mutationGroupsProto(mgs)
 	if err != nil {
 		return &BatchWriteResponseIterator{err: err}
 	}
 
 	var sh *sessionHandle
-	sh, err = c.idleSessions.take(ctx)
+	sh, err = c.idleSessions.takeFor(ctx, "BatchWrite")
 	if err != nil {
 		return &BatchWriteResponseIterator{err: err}
 	}
@@ -1137,7 +1656,7 @@ func (c *Client) BatchWriteWithOptions(ctx context.Context, mgs []*MutationGroup
 			sh.destroy()
 		}
 		var sessionErr error
-		sh, sessionErr = c.idleSessions.take(ct)
+		sh, sessionErr = c.idleSessions.takeFor(ct, "BatchWrite")
 		return sessionErr
 	}
 
@@ -1162,6 +1681,47 @@ func (c *Client) BatchWriteWithOptions(ctx context.Context, mgs []*MutationGroup
 	}
 }
 
+// BatchWriteCommitTimestamps applies a list of mutation groups in the same
+// way as BatchWriteWithOptions, and collects the responses of the stream. It
+// returns the commit timestamps of the mutation groups that were applied,
+// keyed by the index of the mutation group in mgs.
+//
+// If one or more mutation groups could not be applied, the returned error
+// joins one *Error per failed mutation group. Each of these errors has the
+// status code that was returned for that mutation group, so the returned
+// error can be inspected with errors.As or ErrCode. The commit timestamps
+// of the mutation groups that were applied are also returned in that case.
+//
+// BatchWriteCommitTimestamps stops reading from the stream and cancels it if
+// ctx is done before all responses have been received.
+func (c *Client) BatchWriteCommitTimestamps(ctx context.Context, mgs []*MutationGroup, opts BatchWriteOptions) (map[int]time.Time, error) {
+	timestamps := make(map[int]time.Time, len(mgs))
+	var groupErrs []error
+	iter := c.BatchWriteWithOptions(ctx, mgs, opts)
+	err := iter.Do(func(r *sppb.BatchWriteResponse) error {
+		if err := ctx.Err(); err != nil {
+			return ToSpannerError(err)
+		}
+		code := codes.Code(r.GetStatus().GetCode())
+		for _, i := range r.Indexes {
+			idx := int(i)
+			if idx < 0 || idx >= len(mgs) {
+				return spannerErrorf(codes.Internal, "BatchWrite returned a response for unknown mutation group %d", idx)
+			}
+			if code == codes.OK {
+				timestamps[idx] = r.GetCommitTimestamp().AsTime()
+				continue
+			}
+			groupErrs = append(groupErrs, spannerErrorf(code, "mutation group %d: %s", idx, r.GetStatus().GetMessage()))
+		}
+		return nil
+	})
+	if err != nil {
+		return timestamps, err
+	}
+	return timestamps, errors.Join(groupErrs...)
+}
+
 // logf logs the given message to the given logger, or the standard logger if
 // the given logger is nil.
 func logf(logger *log.Logger, format string, v ...interface{}) {
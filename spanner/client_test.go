@@ -18,11 +18,14 @@ package spanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"net"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -31,6 +34,7 @@ import (
 
 	"cloud.google.com/go/civil"
 	itestutil "cloud.google.com/go/internal/testutil"
+	adminpb "cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"github.com/GoogleCloudPlatform/grpc-gcp-go/grpcgcp"
 	"github.com/GoogleCloudPlatform/grpc-gcp-go/grpcgcp/grpc_gcp"
@@ -39,10 +43,12 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/status"
 	structpb "google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	vkit "cloud.google.com/go/spanner/apiv1"
 	. "cloud.google.com/go/spanner/internal/testutil"
@@ -444,6 +450,52 @@ func TestClient_Single_SessionNotFound(t *testing.T) {
 	}
 }
 
+func TestClient_Single_SessionNotFound_ReplacesSession(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(
+		MethodExecuteStreamingSql,
+		SimulatedExecutionTime{Errors: []error{newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s")}},
+	)
+	ctx := context.Background()
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	var rowCount int64
+	if err := iter.Do(func(r *Row) error {
+		rowCount++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rowCount, int64(SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount); g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// The query is retried once on another session.
+	var sessions []string
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			sessions = append(sessions, sqlReq.Session)
+		}
+	}
+	if g, w := len(sessions), 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if sessions[0] == sessions[1] {
+		t.Fatalf("query was retried on the same session %v", sessions[0])
+	}
+	// The session that was not found has been removed from the pool.
+	sp := client.idleSessions
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for e := sp.idleList.Front(); e != nil; e = e.Next() {
+		if s := e.Value.(*session); s.getID() == sessions[0] {
+			t.Fatalf("session %v that was not found is still in the pool", sessions[0])
+		}
+	}
+}
+
 func TestClient_Single_Read_SessionNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -527,32 +579,1178 @@ func TestClient_Single_WhenInactiveTransactionsAndSessionIsNotFoundOnBackend_Rem
 		t.Fatalf("Session pool size mismatch\nGot: %d\nWant: %d\n", g, w)
 	}
 
-	sh.mu.Lock()
-	defer sh.mu.Unlock()
-	if g, w := sh.eligibleForLongRunning, false; g != w {
-		t.Fatalf("isLongRunningTransaction mismatch\nGot: %v\nWant: %v\n", g, w)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if g, w := sh.eligibleForLongRunning, false; g != w {
+		t.Fatalf("isLongRunningTransaction mismatch\nGot: %v\nWant: %v\n", g, w)
+	}
+	if g, w := p.numOfLeakedSessionsRemoved, uint64(1); g != w {
+		t.Fatalf("Number of leaked sessions removed mismatch\nGot: %d\nWant: %d\n", g, w)
+	}
+}
+
+func TestClient_Single_ReadRow_SessionNotFound(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(
+		MethodStreamingRead,
+		SimulatedExecutionTime{Errors: []error{newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s")}},
+	)
+	ctx := context.Background()
+	row, err := client.Single().ReadRow(ctx, "Albums", Key{"foo"}, []string{"SingerId", "AlbumId", "AlbumTitle"})
+	if err != nil {
+		t.Fatalf("Unexpected error for read row: %v", err)
+	}
+	if row == nil {
+		t.Fatal("ReadRow did not return a row")
+	}
+}
+
+func TestClient_Single_TryReadRow(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Empty", &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: KvMeta()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Found.
+	row, found, err := client.Single().TryReadRow(ctx, "Albums", Key{"foo"}, []string{"SingerId", "AlbumId", "AlbumTitle"})
+	if err != nil {
+		t.Fatalf("Unexpected error for read row: %v", err)
+	}
+	if !found || row == nil {
+		t.Fatalf("TryReadRow did not return a row: found=%v, row=%v", found, row)
+	}
+
+	// Missing.
+	row, found, err = client.Single().TryReadRow(ctx, "Empty", Key{"foo"}, []string{"Key", "Value"})
+	if err != nil {
+		t.Fatalf("Unexpected error for missing row: %v", err)
+	}
+	if found || row != nil {
+		t.Fatalf("TryReadRow returned a row for a missing key: found=%v, row=%v", found, row)
+	}
+
+	// RPC error.
+	server.TestSpanner.PutExecutionTime(
+		MethodStreamingRead,
+		SimulatedExecutionTime{Errors: []error{status.Error(codes.InvalidArgument, "Invalid argument")}},
+	)
+	row, found, err = client.Single().TryReadRow(ctx, "Albums", Key{"foo"}, []string{"SingerId", "AlbumId", "AlbumTitle"})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if found || row != nil {
+		t.Fatalf("TryReadRow returned a row for a failed read: found=%v, row=%v", found, row)
+	}
+}
+
+func TestClient_MaxStalenessDuration(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	// Bounded staleness is sent to Spanner for single-use transactions.
+	iter := client.Single().WithTimestampBound(MaxStalenessDuration(15*time.Second)).Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		sqlReq, ok := req.(*sppb.ExecuteSqlRequest)
+		if !ok {
+			continue
+		}
+		found = true
+		got := sqlReq.GetTransaction().GetSingleUse().GetReadOnly().GetMaxStaleness().AsDuration()
+		if g, w := got, 15*time.Second; g != w {
+			t.Fatalf("max staleness mismatch\nGot: %v\nWant: %v", g, w)
+		}
+	}
+	if !found {
+		t.Fatal("missing ExecuteSqlRequest")
+	}
+
+	// Multi-use transactions reject bounded staleness without calling Spanner.
+	for _, tb := range []TimestampBound{MaxStalenessDuration(15 * time.Second), MinReadTimestamp(time.Now().Add(-time.Minute))} {
+		ro := client.ReadOnlyTransaction().WithTimestampBound(tb)
+		iter := ro.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+		err := iter.Do(func(r *Row) error { return nil })
+		ro.Close()
+		if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+			t.Fatalf("%v: error code mismatch\nGot: %v\nWant: %v", tb, g, w)
+		}
+		for _, req := range drainRequestsFromServer(server.TestSpanner) {
+			switch req.(type) {
+			case *sppb.BeginTransactionRequest, *sppb.ExecuteSqlRequest:
+				t.Fatalf("%v: unexpected request %T", tb, req)
+			}
+		}
+	}
+}
+
+func TestReadOptions_RowDeadline(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name   string
+		ro     ReadOptions
+		want   time.Duration
+		wantOk bool
+	}{
+		{"no deadline per row", ReadOptions{ExpectedRowCount: 10}, 0, false},
+		{"no expected row count", ReadOptions{DeadlinePerRow: time.Second}, 0, false},
+		{"derived", ReadOptions{DeadlinePerRow: time.Millisecond, ExpectedRowCount: 1000}, time.Second, true},
+		{"floor", ReadOptions{DeadlinePerRow: time.Millisecond, ExpectedRowCount: 10, MinDeadline: 5 * time.Second}, 5 * time.Second, true},
+		{"ceiling", ReadOptions{DeadlinePerRow: time.Second, ExpectedRowCount: 1000, MaxDeadline: time.Minute}, time.Minute, true},
+		{"overflow", ReadOptions{DeadlinePerRow: time.Hour, ExpectedRowCount: math.MaxInt64, MaxDeadline: time.Hour}, time.Hour, true},
+	} {
+		got, ok := test.ro.rowDeadline()
+		if got != test.want || ok != test.wantOk {
+			t.Errorf("%s: deadline mismatch\nGot: %v, %v\nWant: %v, %v", test.name, got, ok, test.want, test.wantOk)
+		}
+	}
+}
+
+func TestClient_Single_ReadWithDeadlinePerRow(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		deadlines []time.Duration
+	)
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if strings.HasSuffix(method, "/StreamingRead") {
+			mu.Lock()
+			if deadline, ok := ctx.Deadline(); ok {
+				deadlines = append(deadlines, time.Until(deadline))
+			} else {
+				deadlines = append(deadlines, 0)
+			}
+			mu.Unlock()
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	_, client, teardown := setupMockedTestServerWithConfigAndClientOptions(t, ClientConfig{}, []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithStreamInterceptor(interceptor)),
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	read := func(opts *ReadOptions) {
+		iter := client.Single().ReadWithOptions(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, opts)
+		if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read(&ReadOptions{DeadlinePerRow: time.Second, ExpectedRowCount: 100, MaxDeadline: time.Minute})
+	read(&ReadOptions{DeadlinePerRow: time.Millisecond, ExpectedRowCount: 10, MinDeadline: 30 * time.Second})
+	read(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if g, w := len(deadlines), 3; g != w {
+		t.Fatalf("number of reads mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for i, want := range []time.Duration{time.Minute, 30 * time.Second} {
+		if got := deadlines[i]; got <= 0 || got > want || got < want-10*time.Second {
+			t.Errorf("read %d: deadline mismatch\nGot: %v\nWant: %v", i, got, want)
+		}
+	}
+	if got := deadlines[2]; got != 0 {
+		t.Errorf("read without DeadlinePerRow has deadline %v", got)
+	}
+}
+
+func TestClient_Single_ReadWithAssertMaxStaleness(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	for table, age := range map[string]time.Duration{"Fresh": time.Second, "Stale": time.Hour} {
+		metadata := KvMeta()
+		metadata.Transaction = &sppb.Transaction{ReadTimestamp: timestamppb.New(time.Now().Add(-age))}
+		if err := server.TestSpanner.PutStatementResult(fmt.Sprintf("SELECT Key, Value FROM %s", table), &StatementResult{
+			Type: StatementResultResultSet,
+			ResultSet: &sppb.ResultSet{
+				Metadata: metadata,
+				Rows: []*structpb.ListValue{
+					{Values: []*structpb.Value{structpb.NewStringValue("k"), structpb.NewStringValue("v")}},
+				},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	opts := &ReadOptions{AssertMaxStaleness: time.Minute}
+
+	iter := client.Single().WithTimestampBound(MaxStaleness(time.Minute)).ReadWithOptions(ctx, "Fresh", AllKeys(), []string{"Key", "Value"}, opts)
+	rows := 0
+	if err := iter.Do(func(r *Row) error {
+		rows++
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error for fresh read: %v", err)
+	}
+	if g, w := rows, 1; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	iter = client.Single().WithTimestampBound(MaxStaleness(time.Minute)).ReadWithOptions(ctx, "Stale", AllKeys(), []string{"Key", "Value"}, opts)
+	defer iter.Stop()
+	_, err := iter.Next()
+	if !errors.Is(err, ErrStaleRead) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, ErrStaleRead)
+	}
+	if g, w := ErrCode(err), codes.FailedPrecondition; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// The staleness is not checked without AssertMaxStaleness.
+	if _, err := client.Single().ReadRow(ctx, "Stale", Key{"k"}, []string{"Key", "Value"}); err != nil {
+		t.Fatalf("Unexpected error for read without AssertMaxStaleness: %v", err)
+	}
+}
+
+func TestClient_Single_QueryWithAssertMaxStaleness(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		ReadOptions: ReadOptions{AssertMaxStaleness: time.Minute},
+	})
+	defer teardown()
+	for table, age := range map[string]time.Duration{"Fresh": time.Second, "Stale": time.Hour} {
+		metadata := KvMeta()
+		metadata.Transaction = &sppb.Transaction{ReadTimestamp: timestamppb.New(time.Now().Add(-age))}
+		if err := server.TestSpanner.PutStatementResult(fmt.Sprintf("SELECT Key, Value FROM %s", table), &StatementResult{
+			Type: StatementResultResultSet,
+			ResultSet: &sppb.ResultSet{
+				Metadata: metadata,
+				Rows: []*structpb.ListValue{
+					{Values: []*structpb.Value{structpb.NewStringValue("k"), structpb.NewStringValue("v")}},
+				},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	iter := client.Single().WithTimestampBound(MaxStaleness(time.Minute)).Query(ctx, NewStatement("SELECT Key, Value FROM Fresh"))
+	if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error for fresh query: %v", err)
+	}
+
+	iter = client.Single().WithTimestampBound(MaxStaleness(time.Minute)).Query(ctx, NewStatement("SELECT Key, Value FROM Stale"))
+	defer iter.Stop()
+	_, err := iter.Next()
+	if !errors.Is(err, ErrStaleRead) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, ErrStaleRead)
+	}
+}
+
+func TestClient_Single_ReadWithRetryWithFreshTimestamp(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("k"), structpb.NewStringValue("v")}},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server.TestSpanner.PutExecutionTime(MethodStreamingRead, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.Unavailable, "Temporary unavailable")},
+	})
+	ctx := context.Background()
+	iter := client.Single().WithTimestampBound(MaxStaleness(10*time.Second)).ReadWithOptions(ctx, "Kv", AllKeys(), []string{"Key", "Value"}, &ReadOptions{RetryWithFreshTimestamp: true})
+	rows := 0
+	if err := iter.Do(func(r *Row) error {
+		rows++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rows, 1; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	var reads []*sppb.ReadRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if read, ok := req.(*sppb.ReadRequest); ok {
+			reads = append(reads, read)
+		}
+	}
+	if g, w := len(reads), 2; g != w {
+		t.Fatalf("read request count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var bounds []time.Time
+	for i, read := range reads {
+		ts := read.GetTransaction().GetSingleUse().GetReadOnly().GetMinReadTimestamp()
+		if ts == nil {
+			t.Fatalf("read request %d does not contain a min read timestamp: %v", i, read.GetTransaction())
+		}
+		bounds = append(bounds, ts.AsTime())
+	}
+	if !bounds[1].After(bounds[0]) {
+		t.Fatalf("retry did not use a fresh timestamp bound\nFirst: %v\nRetry: %v", bounds[0], bounds[1])
+	}
+}
+
+func TestClient_Single_ReadWithRetryWithFreshTimestampResumesWithSameBound(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("k1"), structpb.NewStringValue("v1")}},
+				{Values: []*structpb.Value{structpb.NewStringValue("k2"), structpb.NewStringValue("v2")}},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server.TestSpanner.AddPartialResultSetError("SELECT Key, Value FROM Kv", PartialResultSetExecutionTime{
+		ResumeToken: EncodeResumeToken(2),
+		Err:         status.Error(codes.Unavailable, "Temporary unavailable"),
+	})
+	ctx := context.Background()
+	iter := client.Single().WithTimestampBound(MaxStaleness(10*time.Second)).ReadWithOptions(ctx, "Kv", AllKeys(), []string{"Key", "Value"}, &ReadOptions{RetryWithFreshTimestamp: true})
+	rows := 0
+	if err := iter.Do(func(r *Row) error {
+		rows++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rows, 2; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	var reads []*sppb.ReadRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if read, ok := req.(*sppb.ReadRequest); ok {
+			reads = append(reads, read)
+		}
+	}
+	if g, w := len(reads), 2; g != w {
+		t.Fatalf("read request count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if len(reads[1].ResumeToken) == 0 {
+		t.Fatal("second read request was not resumed")
+	}
+	if g, w := reads[1].GetTransaction(), reads[0].GetTransaction(); !testEqual(g, w) {
+		t.Fatalf("resumed read did not use the same timestamp bound\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_QueryStatsSink(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var gotStats []*sppb.ResultSetStats
+	var gotStmts []Statement
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		QueryStatsSink: func(stats *sppb.ResultSetStats, stmt Statement) {
+			mu.Lock()
+			defer mu.Unlock()
+			gotStats = append(gotStats, stats)
+			gotStmts = append(gotStmts, stmt)
+		},
+	})
+	defer teardown()
+	sql := "SELECT Key, Value FROM KV"
+	queryStats, _ := structpb.NewStruct(map[string]interface{}{"rows_returned": "1"})
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("k"), structpb.NewStringValue("v")}},
+			},
+			Stats: &sppb.ResultSetStats{QueryStats: queryStats},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	stmt := NewStatement(sql)
+	iter := client.Single().Query(ctx, stmt)
+	if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if g, w := len(gotStats), 1; g != w {
+		t.Fatalf("stats count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := gotStats[0].GetQueryStats().GetFields()["rows_returned"].GetStringValue(), "1"; g != w {
+		t.Fatalf("query stats mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := gotStmts[0].SQL, sql; g != w {
+		t.Fatalf("statement mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var sqlRequest *sppb.ExecuteSqlRequest
+	for _, req := range requests {
+		if r, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			sqlRequest = r
+		}
+	}
+	if sqlRequest == nil {
+		t.Fatal("missing ExecuteSqlRequest")
+	}
+	if g, w := sqlRequest.QueryMode, sppb.ExecuteSqlRequest_PROFILE; g != w {
+		t.Fatalf("query mode mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_AnalyzeQuery(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	sql := "SELECT Key, Value FROM KV"
+	plan := &sppb.QueryPlan{PlanNodes: []*sppb.PlanNode{{Index: 0, DisplayName: "Serialize Result"}}}
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Stats:    &sppb.ResultSetStats{QueryPlan: plan},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	got, err := client.Single().AnalyzeQuery(ctx, NewStatement(sql))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, plan) {
+		t.Fatalf("query plan mismatch\nGot: %v\nWant: %v", got, plan)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var sqlRequest *sppb.ExecuteSqlRequest
+	for _, req := range requests {
+		if r, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			sqlRequest = r
+		}
+	}
+	if sqlRequest == nil {
+		t.Fatal("missing ExecuteSqlRequest")
+	}
+	if g, w := sqlRequest.QueryMode, sppb.ExecuteSqlRequest_PLAN; g != w {
+		t.Fatalf("query mode mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ProfileQuery(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	sql := "SELECT Key, Value FROM KV"
+	plan := &sppb.QueryPlan{PlanNodes: []*sppb.PlanNode{{Index: 0, DisplayName: "Serialize Result"}}}
+	queryStats, _ := structpb.NewStruct(map[string]interface{}{"rows_returned": "2"})
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("k1"), structpb.NewStringValue("v1")}},
+				{Values: []*structpb.Value{structpb.NewStringValue("k2"), structpb.NewStringValue("v2")}},
+			},
+			Stats: &sppb.ResultSetStats{QueryPlan: plan, QueryStats: queryStats},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var keys []string
+	stats, err := client.Single().ProfileQuery(ctx, NewStatement(sql), func(r *Row) error {
+		var key string
+		if err := r.Column(0, &key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := keys, []string{"k1", "k2"}; !testEqual(g, w) {
+		t.Fatalf("keys mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := stats.GetQueryPlan(), plan; !testEqual(g, w) {
+		t.Fatalf("query plan mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := stats.GetQueryStats().GetFields()["rows_returned"].GetStringValue(), "2"; g != w {
+		t.Fatalf("query stats mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var sqlRequest *sppb.ExecuteSqlRequest
+	for _, req := range requests {
+		if r, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			sqlRequest = r
+		}
+	}
+	if sqlRequest == nil {
+		t.Fatal("missing ExecuteSqlRequest")
+	}
+	if g, w := sqlRequest.QueryMode, sppb.ExecuteSqlRequest_PROFILE; g != w {
+		t.Fatalf("query mode mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ProfileQuery_CallbackError(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	wantErr := errors.New("stop")
+	stats, err := client.Single().ProfileQuery(context.Background(), NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), func(r *Row) error {
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, wantErr)
+	}
+	if stats != nil {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+}
+
+func TestClient_NumericDecodeMode(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{NumericDecodeMode: NumericDecodeModeString})
+	defer teardown()
+	sql := "SELECT Value FROM Numerics"
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: &sppb.ResultSetMetadata{
+				RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: "Value", Type: &sppb.Type{Code: sppb.TypeCode_NUMERIC}}}},
+			},
+			Rows: []*structpb.ListValue{{Values: []*structpb.Value{structpb.NewStringValue("3.14")}}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	iter := client.Single().Query(context.Background(), NewStatement(sql))
+	defer iter.Stop()
+	row, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v interface{}
+	if err := row.Column(0, &v); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := v, interface{}("3.14"); g != w {
+		t.Fatalf("value mismatch\nGot: %v (%T)\nWant: %v (%T)", g, g, w, w)
+	}
+}
+
+func TestClient_QueryToColumns(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	metadata := &sppb.ResultSetMetadata{
+		RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{
+			{Name: "Id", Type: &sppb.Type{Code: sppb.TypeCode_INT64}},
+			{Name: "Name", Type: &sppb.Type{Code: sppb.TypeCode_STRING}},
+			{Name: "Score", Type: &sppb.Type{Code: sppb.TypeCode_FLOAT64}},
+			{Name: "Data", Type: &sppb.Type{Code: sppb.TypeCode_BYTES}},
+		}},
+	}
+	for sql, rows := range map[string][]*structpb.ListValue{
+		"SELECT * FROM Scores": {
+			{Values: []*structpb.Value{structpb.NewStringValue("1"), structpb.NewStringValue("foo"), structpb.NewNumberValue(1.5), structpb.NewStringValue("YWJj")}},
+			{Values: []*structpb.Value{structpb.NewStringValue("2"), structpb.NewNullValue(), structpb.NewNullValue(), structpb.NewNullValue()}},
+		},
+		"SELECT * FROM EmptyScores": nil,
+	} {
+		if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+			Type:      StatementResultResultSet,
+			ResultSet: &sppb.ResultSet{Metadata: metadata, Rows: rows},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	columns, err := client.Single().Query(ctx, NewStatement("SELECT * FROM Scores")).ToColumns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"Id":    []NullInt64{{Int64: 1, Valid: true}, {Int64: 2, Valid: true}},
+		"Name":  []NullString{{StringVal: "foo", Valid: true}, {}},
+		"Score": []NullFloat64{{Float64: 1.5, Valid: true}, {}},
+		"Data":  [][]byte{[]byte("abc"), nil},
+	}
+	if !testEqual(columns, want) {
+		t.Fatalf("columns mismatch\nGot: %v\nWant: %v", columns, want)
+	}
+
+	// An empty result returns an empty slice for each column.
+	columns, err = client.Single().Query(ctx, NewStatement("SELECT * FROM EmptyScores")).ToColumns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{
+		"Id":    []NullInt64{},
+		"Name":  []NullString{},
+		"Score": []NullFloat64{},
+		"Data":  [][]byte{},
+	}
+	if !testEqual(columns, want) {
+		t.Fatalf("columns mismatch for empty result\nGot: %v\nWant: %v", columns, want)
+	}
+}
+
+func TestClient_QueryBytesReceived(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	iter := client.Single().Query(context.Background(), NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	defer iter.Stop()
+	if g, w := iter.BytesReceived(), int64(0); g != w {
+		t.Fatalf("bytes received mismatch before Next\nGot: %v\nWant: %v", g, w)
+	}
+	var prev int64
+	rows := 0
+	for {
+		_, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows++
+		// The mock server returns each row in a separate PartialResultSet.
+		if g := iter.BytesReceived(); g <= prev {
+			t.Fatalf("bytes received did not increase after row %d\nGot: %v\nPrevious: %v", rows, g, prev)
+		}
+		prev = iter.BytesReceived()
+	}
+	if rows < 2 {
+		t.Fatalf("got %d rows, want a multi-row result", rows)
+	}
+	if g, w := iter.BytesReceived(), prev; g != w {
+		t.Fatalf("bytes received mismatch after Done\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_QueryParallelDo(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	const numRows = 40
+	rows := make([]*structpb.ListValue, numRows)
+	for i := range rows {
+		rows[i] = &structpb.ListValue{Values: []*structpb.Value{
+			structpb.NewStringValue(fmt.Sprintf("k%d", i)),
+			structpb.NewStringValue(fmt.Sprintf("v%d", i)),
+		}}
+	}
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: KvMeta(), Rows: rows},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var (
+		mu                sync.Mutex
+		seen              = make(map[string]bool)
+		active, maxActive int32
+	)
+	iter := client.Single().Query(ctx, NewStatement("SELECT Key, Value FROM Kv"))
+	if err := iter.ParallelDo(ctx, 4, func(r *Row) error {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		mu.Lock()
+		if n > maxActive {
+			maxActive = n
+		}
+		mu.Unlock()
+		var key string
+		if err := r.ColumnByName("Key", &key); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[key] {
+			return fmt.Errorf("row %q processed more than once", key)
+		}
+		seen[key] = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(seen), numRows; g != w {
+		t.Fatalf("processed row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if maxActive > 4 {
+		t.Fatalf("rows were processed by %d goroutines, want at most 4", maxActive)
+	}
+
+	// The first error of the function is returned.
+	wantErr := errors.New("process failed")
+	iter = client.Single().Query(ctx, NewStatement("SELECT Key, Value FROM Kv"))
+	if err := iter.ParallelDo(ctx, 4, func(r *Row) error {
+		return wantErr
+	}); err != wantErr {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, wantErr)
+	}
+}
+
+func TestClient_RowIteratorPeerAddr(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name string
+		iter func() *RowIterator
+	}{
+		{"query", func() *RowIterator {
+			return client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+		}},
+		{"read", func() *RowIterator {
+			return client.Single().Read(ctx, "Albums", AllKeys(), []string{"SingerId", "AlbumId", "AlbumTitle"})
+		}},
+	} {
+		iter := tt.iter()
+		if addr := iter.PeerAddr(); addr != nil {
+			t.Fatalf("%s: unexpected peer address before Next: %v", tt.name, addr)
+		}
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		addr := iter.PeerAddr()
+		if addr == nil {
+			t.Fatalf("%s: missing peer address after Next", tt.name)
+		}
+		if g, w := addr.String(), server.ServerAddress; g != w {
+			t.Fatalf("%s: peer address mismatch\nGot: %v\nWant: %v", tt.name, g, w)
+		}
+		if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if g, w := iter.PeerAddr(), addr; g != w {
+			t.Fatalf("%s: peer address after iteration mismatch\nGot: %v\nWant: %v", tt.name, g, w)
+		}
+	}
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	sorted, err := iter.Sorted(func(a, b *Row) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sorted.Stop()
+	if g, w := sorted.PeerAddr(), iter.PeerAddr(); g == nil || g != w {
+		t.Fatalf("peer address of sorted iterator mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_IgnoreUnmappedColumns(t *testing.T) {
+	t.Parallel()
+
+	// The query returns the columns SingerId, AlbumId and AlbumTitle.
+	type album struct {
+		SingerID int64 `spanner:"SingerId"`
+		AlbumID  int64 `spanner:"AlbumId"`
+	}
+	ctx := context.Background()
+	for _, ignore := range []bool{false, true} {
+		_, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{IgnoreUnmappedColumns: ignore})
+		row, err := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)).Next()
+		if err != nil {
+			teardown()
+			t.Fatal(err)
+		}
+		var a album
+		err = row.ToStruct(&a)
+		if ignore {
+			if err != nil {
+				t.Fatalf("Unexpected error with IgnoreUnmappedColumns: %v", err)
+			}
+			if g, w := a, (album{SingerID: 1, AlbumID: 0}); g != w {
+				t.Fatalf("struct mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		} else if ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error mismatch without IgnoreUnmappedColumns\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+		}
+
+		var albums []*album
+		err = SelectAll(client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)), &albums)
+		if ignore {
+			if err != nil {
+				t.Fatalf("Unexpected error for SelectAll with IgnoreUnmappedColumns: %v", err)
+			}
+			if g, w := len(albums), 3; g != w {
+				t.Fatalf("number of albums mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		} else if err == nil {
+			t.Fatal("missing error for SelectAll without IgnoreUnmappedColumns")
+		}
+		teardown()
+	}
+}
+
+func TestClient_QuerySorted(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	sorted, err := iter.Sorted(func(a, b *Row) bool {
+		var titleA, titleB string
+		if err := a.ColumnByName("AlbumTitle", &titleA); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.ColumnByName("AlbumTitle", &titleB); err != nil {
+			t.Fatal(err)
+		}
+		return titleA > titleB
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sorted.Metadata == nil {
+		t.Fatal("missing metadata")
+	}
+	var got []string
+	if err := sorted.Do(func(r *Row) error {
+		var title string
+		if err := r.ColumnByName("AlbumTitle", &title); err != nil {
+			return err
+		}
+		got = append(got, title)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Album title 2", "Album title 1", "Album title 0"}
+	if !testEqual(got, want) {
+		t.Errorf("sorted rows mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	if _, err := sorted.Next(); err != iterator.Done {
+		t.Errorf("error mismatch\nGot: %v\nWant: %v", err, iterator.Done)
+	}
+
+	// Next returns an error after Stop.
+	iter = client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	sorted, err = iter.Sorted(func(a, b *Row) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted.Stop()
+	if _, err := sorted.Next(); ErrCode(err) != codes.FailedPrecondition {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.FailedPrecondition)
+	}
+}
+
+func TestClient_QuerySorted_Error(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.InvalidArgument, "Invalid query")},
+	})
+	iter := client.Single().Query(context.Background(), NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if _, err := iter.Sorted(func(a, b *Row) bool { return false }); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+}
+
+func TestClient_QueryPullSource(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	pull := iter.PullSource(ctx)
+	var rowCount int64
+	for {
+		row, err, ok := pull()
+		if !ok {
+			if err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+		var singerID, albumID int64
+		var albumTitle string
+		if err := row.Columns(&singerID, &albumID, &albumTitle); err != nil {
+			t.Fatal(err)
+		}
+		rowCount++
+	}
+	if g, w := rowCount, int64(SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount); g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	// Calling the function after the last row keeps returning ok=false.
+	if row, err, ok := pull(); row != nil || err != nil || ok {
+		t.Fatalf("pull after completion mismatch\nGot: %v, %v, %v\nWant: <nil>, <nil>, false", row, err, ok)
+	}
+
+	// A canceled context stops the iterator.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	iter = client.Single().Query(cancelCtx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	pull = iter.PullSource(cancelCtx)
+	if _, err, ok := pull(); err != nil || !ok {
+		t.Fatalf("first pull failed: %v", err)
+	}
+	cancel()
+	if _, err, ok := pull(); ok || ErrCode(err) != codes.Canceled {
+		t.Fatalf("pull after cancel mismatch\nGot: %v, %v\nWant: %v, false", err, ok, codes.Canceled)
+	}
+}
+
+func TestClient_QueryPullSource_ErrorMidStream(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.AddPartialResultSetError(
+		SelectSingerIDAlbumIDAlbumTitleFromAlbums,
+		PartialResultSetExecutionTime{
+			ResumeToken: EncodeResumeToken(2),
+			Err:         status.Errorf(codes.PermissionDenied, "permission denied"),
+		},
+	)
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	pull := iter.PullSource(ctx)
+	var rowCount int64
+	var err error
+	for {
+		var ok bool
+		if _, err, ok = pull(); !ok {
+			break
+		}
+		rowCount++
+	}
+	if g, w := ErrCode(err), codes.PermissionDenied; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if rowCount >= SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount {
+		t.Fatalf("got %d rows, want fewer than %d", rowCount, SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount)
+	}
+	// The terminal error is returned again by later calls.
+	if row, gotErr, ok := pull(); row != nil || gotErr != err || ok {
+		t.Fatalf("pull after error mismatch\nGot: %v, %v, %v\nWant: <nil>, %v, false", row, gotErr, ok, err)
+	}
+}
+
+func TestClient_QueryWithCompression(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		compressors []string
+	)
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if strings.HasSuffix(method, "/ExecuteStreamingSql") {
+			var compressor string
+			for _, opt := range opts {
+				if c, ok := opt.(grpc.CompressorCallOption); ok {
+					compressor = c.CompressorType
+				}
+			}
+			mu.Lock()
+			compressors = append(compressors, compressor)
+			mu.Unlock()
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	server, client, teardown := setupMockedTestServerWithConfigAndClientOptions(t, ClientConfig{}, []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithStreamInterceptor(interceptor)),
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	for _, compression := range []string{gzip.Name, ""} {
+		iter := client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{Compression: compression})
+		var rowCount int64
+		if err := iter.Do(func(r *Row) error {
+			rowCount++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if g, w := rowCount, int64(SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount); g != w {
+			t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+		}
+	}
+	mu.Lock()
+	if g, w := compressors, []string{gzip.Name, ""}; !testEqual(g, w) {
+		t.Fatalf("compressor mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	mu.Unlock()
+
+	// An unknown compressor is rejected before the query is sent.
+	drainRequestsFromServer(server.TestSpanner)
+	iter := client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{Compression: "unknown"})
+	err := iter.Do(func(r *Row) error { return nil })
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !strings.Contains(err.Error(), `compressor \"unknown\" is not registered`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if _, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			t.Fatal("query with unknown compressor was sent to Spanner")
+		}
+	}
+}
+
+func TestClient_QueryWithStatsCompression(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		compressors []string
+	)
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if strings.HasSuffix(method, "/ExecuteStreamingSql") {
+			var compressor string
+			for _, opt := range opts {
+				if c, ok := opt.(grpc.CompressorCallOption); ok {
+					compressor = c.CompressorType
+				}
+			}
+			mu.Lock()
+			compressors = append(compressors, compressor)
+			mu.Unlock()
+		}
+		return streamer(ctx, desc, cc, method, opts...)
 	}
-	if g, w := p.numOfLeakedSessionsRemoved, uint64(1); g != w {
-		t.Fatalf("Number of leaked sessions removed mismatch\nGot: %d\nWant: %d\n", g, w)
+	_, client, teardown := setupMockedTestServerWithConfigAndClientOptions(t, ClientConfig{
+		QueryOptions: QueryOptions{Compression: gzip.Name},
+	}, []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithStreamInterceptor(interceptor)),
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	stmt := NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)
+	if err := client.Single().Query(ctx, stmt).Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Single().QueryWithStats(ctx, stmt).Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if g, w := compressors, []string{gzip.Name, gzip.Name}; !testEqual(g, w) {
+		t.Fatalf("compressor mismatch\nGot: %v\nWant: %v", g, w)
 	}
 }
 
-func TestClient_Single_ReadRow_SessionNotFound(t *testing.T) {
+func TestClient_QueryDuplicateColumnPolicy(t *testing.T) {
 	t.Parallel()
 
 	server, client, teardown := setupMockedTestServer(t)
 	defer teardown()
-	server.TestSpanner.PutExecutionTime(
-		MethodStreamingRead,
-		SimulatedExecutionTime{Errors: []error{newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s")}},
-	)
+	sql := "SELECT s.id, a.id, a.title FROM Singers s JOIN Albums a ON s.id = a.singer_id"
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: &sppb.ResultSetMetadata{
+				RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{
+					{Name: "id", Type: &sppb.Type{Code: sppb.TypeCode_INT64}},
+					{Name: "id", Type: &sppb.Type{Code: sppb.TypeCode_INT64}},
+					{Name: "title", Type: &sppb.Type{Code: sppb.TypeCode_STRING}},
+				}},
+			},
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("1"), structpb.NewStringValue("2"), structpb.NewStringValue("Title")}},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
 	ctx := context.Background()
-	row, err := client.Single().ReadRow(ctx, "Albums", Key{"foo"}, []string{"SingerId", "AlbumId", "AlbumTitle"})
-	if err != nil {
-		t.Fatalf("Unexpected error for read row: %v", err)
+	queryRow := func(policy DuplicateColumnPolicy) *Row {
+		iter := client.Single().QueryWithOptions(ctx, NewStatement(sql), QueryOptions{DuplicateColumnPolicy: policy})
+		defer iter.Stop()
+		row, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return row
 	}
-	if row == nil {
-		t.Fatal("ReadRow did not return a row")
+	var id int64
+
+	// The default policy returns an error for the duplicate column.
+	row := queryRow(DuplicateColumnError)
+	if err := row.ColumnByName("id", &id); ErrCode(err) != codes.FailedPrecondition {
+		t.Fatalf("error mismatch for DuplicateColumnError\nGot: %v\nWant: %v", err, codes.FailedPrecondition)
+	}
+
+	row = queryRow(DuplicateColumnFirstMatch)
+	if err := row.ColumnByName("id", &id); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := id, int64(1); g != w {
+		t.Fatalf("value mismatch for DuplicateColumnFirstMatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	row = queryRow(DuplicateColumnSuffix)
+	if g, w := row.ColumnNames(), []string{"id", "id_1", "title"}; !testEqual(g, w) {
+		t.Fatalf("column names mismatch for DuplicateColumnSuffix\nGot: %v\nWant: %v", g, w)
+	}
+	var singer struct {
+		ID      int64  `spanner:"id"`
+		AlbumID int64  `spanner:"id_1"`
+		Title   string `spanner:"title"`
+	}
+	if err := row.ToStruct(&singer); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := singer.AlbumID, int64(2); g != w {
+		t.Fatalf("value mismatch for DuplicateColumnSuffix\nGot: %v\nWant: %v", g, w)
 	}
 }
 
@@ -611,19 +1809,89 @@ func TestClient_Single_NonRetryableErrorOnPartialResultSet(t *testing.T) {
 			Err:         status.Errorf(codes.Internal, "stream terminated by RST_STREAM"),
 		},
 	)
-	// 'Session not found' is not retryable and the error will be returned to
+	// 'Permission denied' is not retryable and the error will be returned to
 	// the user.
 	server.TestSpanner.AddPartialResultSetError(
 		SelectSingerIDAlbumIDAlbumTitleFromAlbums,
 		PartialResultSetExecutionTime{
 			ResumeToken: EncodeResumeToken(3),
-			Err:         newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s"),
+			Err:         status.Errorf(codes.PermissionDenied, "permission denied"),
 		},
 	)
 	ctx := context.Background()
 	err := executeSingerQuery(ctx, client.Single())
-	if status.Code(err) != codes.NotFound {
-		t.Fatalf("Error mismatch:\ngot: %v\nwant: %v", err, codes.NotFound)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Error mismatch:\ngot: %v\nwant: %v", err, codes.PermissionDenied)
+	}
+}
+
+func TestClient_Single_SessionNotFoundOnPartialResultSet(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	// The session is deleted after the first partial result set has been
+	// returned. The read should be resumed on a new session from the last
+	// resume token and at the same read timestamp.
+	sql := "SELECT Key, Value FROM KV"
+	readTimestamp := time.Now().Add(-time.Second).Truncate(time.Microsecond)
+	metadata := KvMeta()
+	metadata.Transaction = &sppb.Transaction{ReadTimestamp: timestamppb.New(readTimestamp)}
+	var rows []*structpb.ListValue
+	for i := 0; i < 3; i++ {
+		rows = append(rows, &structpb.ListValue{Values: []*structpb.Value{
+			structpb.NewStringValue(fmt.Sprintf("k%d", i)),
+			structpb.NewStringValue(fmt.Sprintf("v%d", i)),
+		}})
+	}
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: metadata, Rows: rows},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server.TestSpanner.AddPartialResultSetError(
+		sql,
+		PartialResultSetExecutionTime{
+			ResumeToken: EncodeResumeToken(2),
+			Err:         newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s"),
+		},
+	)
+	ctx := context.Background()
+	iter := client.Single().Query(ctx, NewStatement(sql))
+	var keys []string
+	if err := iter.Do(func(r *Row) error {
+		var key string
+		if err := r.Column(0, &key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := keys, []string{"k0", "k1", "k2"}; !testEqual(g, w) {
+		t.Fatalf("keys mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	var sqlRequests []*sppb.ExecuteSqlRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			sqlRequests = append(sqlRequests, sqlReq)
+		}
+	}
+	if g, w := len(sqlRequests), 2; g != w {
+		t.Fatalf("request count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if sqlRequests[0].Session == sqlRequests[1].Session {
+		t.Fatalf("read was not resumed on a new session: %v", sqlRequests[1].Session)
+	}
+	if g, w := sqlRequests[1].ResumeToken, EncodeResumeToken(1); !testEqual(g, w) {
+		t.Fatalf("resume token mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	gotTs := sqlRequests[1].GetTransaction().GetSingleUse().GetReadOnly().GetReadTimestamp()
+	if gotTs == nil || !gotTs.AsTime().Equal(readTimestamp) {
+		t.Fatalf("read timestamp mismatch\nGot: %v\nWant: %v", gotTs, readTimestamp)
 	}
 }
 
@@ -691,6 +1959,66 @@ func TestClient_Single_DeadlineExceeded_WithErrors(t *testing.T) {
 	}
 }
 
+func TestClient_QueryWithOptions_InactivityTimeout(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	// Return each partial result set after a delay that is shorter than the
+	// inactivity timeout. The total time of the query is longer than the
+	// inactivity timeout.
+	for i := uint64(1); i <= 3; i++ {
+		server.TestSpanner.AddPartialResultSetError(
+			SelectSingerIDAlbumIDAlbumTitleFromAlbums,
+			PartialResultSetExecutionTime{
+				ResumeToken:   EncodeResumeToken(i),
+				ExecutionTime: 80 * time.Millisecond,
+			},
+		)
+	}
+	ctx := context.Background()
+	start := time.Now()
+	iter := client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{InactivityTimeout: 200 * time.Millisecond})
+	var rowCount int
+	if err := iter.Do(func(r *Row) error {
+		rowCount++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rowCount, 3; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("query finished in %v, want more than the inactivity timeout", elapsed)
+	}
+}
+
+func TestClient_QueryWithOptions_InactivityTimeoutExceeded(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.AddPartialResultSetError(
+		SelectSingerIDAlbumIDAlbumTitleFromAlbums,
+		PartialResultSetExecutionTime{
+			ResumeToken:   EncodeResumeToken(2),
+			ExecutionTime: time.Second,
+		},
+	)
+	ctx := context.Background()
+	iter := client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{InactivityTimeout: 50 * time.Millisecond})
+	var rowCount int
+	err := iter.Do(func(r *Row) error {
+		rowCount++
+		return nil
+	})
+	if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := rowCount, 1; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
 func TestClient_Single_ContextCanceled_noDeclaredServerErrors(t *testing.T) {
 	t.Parallel()
 	_, client, teardown := setupMockedTestServer(t)
@@ -972,6 +2300,45 @@ func TestClient_ReadOnlyTransaction(t *testing.T) {
 	}
 }
 
+func TestClient_ReadOnlyTransactionAfter(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	commitTs, err := client.Apply(ctx, []*Mutation{
+		InsertOrUpdate("Albums", []string{"SingerId", "AlbumId", "AlbumTitle"}, []interface{}{int64(1), int64(1), "Title"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drainRequestsFromServer(server.TestSpanner)
+
+	tx := client.ReadOnlyTransactionAfter(commitTs)
+	defer tx.Close()
+	iter := tx.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	defer iter.Stop()
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+	var begin *sppb.BeginTransactionRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if r, ok := req.(*sppb.BeginTransactionRequest); ok {
+			begin = r
+		}
+	}
+	if begin == nil {
+		t.Fatal("missing BeginTransaction request")
+	}
+	ts := begin.GetOptions().GetReadOnly().GetReadTimestamp()
+	if ts == nil {
+		t.Fatalf("BeginTransaction request does not contain a read timestamp: %v", begin.GetOptions())
+	}
+	if g, w := ts.AsTime(), commitTs; !g.Equal(w) {
+		t.Fatalf("read timestamp mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
 func TestClient_ReadOnlyTransaction_UnavailableOnSessionCreate(t *testing.T) {
 	t.Parallel()
 	if err := testReadOnlyTransaction(t, createSimulatedExecutionTimeWithTwoUnavailableErrors(MethodCreateSession)); err != nil {
@@ -1010,6 +2377,60 @@ func TestClient_ReadOnlyTransaction_SessionNotFoundOnExecuteStreamingSql(t *test
 	}
 }
 
+func TestClient_ReadOnlyTransaction_RetryReusesReadTimestamp(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	if err := executeSingerQuery(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	want, err := tx.Timestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fail the second query of the transaction with a retryable error.
+	server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.Unavailable, "Temporary unavailable")},
+	})
+	if err := executeSingerQuery(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tx.Timestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("read timestamp mismatch\nGot: %v\nWant: %v", got, want)
+	}
+
+	var beginCount int
+	var txIDs [][]byte
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch r := req.(type) {
+		case *sppb.BeginTransactionRequest:
+			beginCount++
+		case *sppb.ExecuteSqlRequest:
+			txIDs = append(txIDs, r.GetTransaction().GetId())
+		}
+	}
+	if g, w := beginCount, 1; g != w {
+		t.Fatalf("BeginTransaction count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	// The first query, the failed attempt of the second query and the retry.
+	if g, w := len(txIDs), 3; g != w {
+		t.Fatalf("ExecuteSqlRequest count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for i, id := range txIDs {
+		if len(id) == 0 || !testEqual(id, txIDs[0]) {
+			t.Fatalf("transaction ID mismatch for request %d\nGot: %v\nWant: %v", i, id, txIDs[0])
+		}
+	}
+}
+
 func TestClient_ReadOnlyTransaction_UnavailableOnCreateSessionAndBeginTransaction(t *testing.T) {
 	t.Parallel()
 	exec := map[string]SimulatedExecutionTime{
@@ -1416,23 +2837,56 @@ func TestClient_DirectedReadOptions(t *testing.T) {
 	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{DirectedReadOptions: directedReadOptionsForRW})
 	defer teardown()
 
+	// The DirectedReadOptions of the client are not used for read/write
+	// transactions, and DirectedReadOptions for a single read or query in a
+	// read/write transaction are rejected.
 	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *ReadWriteTransaction) error {
-		iter := txn.ReadWithOptions(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, &ReadOptions{DirectedReadOptions: directedReadOptions})
-		testReadOptions(t, iter, server.TestSpanner, ReadOptions{DirectedReadOptions: directedReadOptions})
+		iter := txn.Read(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"})
+		testReadOptions(t, iter, server.TestSpanner, ReadOptions{})
 		return nil
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *ReadWriteTransaction) error {
+		iter := txn.ReadWithOptions(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"}, &ReadOptions{DirectedReadOptions: directedReadOptions})
+		defer iter.Stop()
+		_, err := iter.Next()
+		return err
+	})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("read error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
 	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *ReadWriteTransaction) error {
 		iter := txn.QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{DirectedReadOptions: directedReadOptions})
-		testQueryOptions(t, iter, server.TestSpanner, QueryOptions{Options: &sppb.ExecuteSqlRequest_QueryOptions{}, DirectedReadOptions: directedReadOptions})
-		return nil
+		defer iter.Stop()
+		_, err := iter.Next()
+		return err
+	})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("query error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *ReadWriteTransaction) error {
+		_, err := txn.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{DirectedReadOptions: directedReadOptions})
+		return err
 	})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("update error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	tx, err := NewReadWriteStmtBasedTransaction(ctx, client)
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer tx.Rollback(ctx)
+	iter := tx.QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{DirectedReadOptions: directedReadOptions})
+	defer iter.Stop()
+	if _, err := iter.Next(); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("stmt-based query error mismatch\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
 }
 
 func TestClient_ReadOnlyTransaction_WhenMultipleOperations_SessionLastUseTimeShouldBeUpdated(t *testing.T) {
@@ -1727,23 +3181,128 @@ func TestClient_ReadWriteTransaction_BufferedWriteBeforeSqlStatementWithErrorTha
 	if err != nil {
 		t.Fatal(err)
 	}
-	if expectedAttempts != attempts {
-		t.Fatalf("unexpected number of attempts: %d, expected %d", attempts, expectedAttempts)
+	if expectedAttempts != attempts {
+		t.Fatalf("unexpected number of attempts: %d, expected %d", attempts, expectedAttempts)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	if err := compareRequests([]interface{}{
+		&sppb.BatchCreateSessionsRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.BeginTransactionRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.CommitRequest{},
+	}, requests); err != nil {
+		t.Fatal(err)
+	}
+	commit := requests[len(requests)-1].(*sppb.CommitRequest)
+	g, w := len(commit.Mutations), 1
+	if g != w {
+		t.Fatalf("mutations count mismatch\nGot:  %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ReadWriteTransaction_BufferedMutationCount(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.Aborted, "Transaction aborted")},
+	})
+	var counts [][]int64
+	if _, err := client.ReadWriteTransaction(context.Background(), func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempt := []int64{tx.BufferedMutationCount()}
+		if err := tx.BufferWrite([]*Mutation{
+			Insert("Singers", []string{"SingerId", "FirstName", "LastName"}, []interface{}{1, "a", "b"}),
+			Delete("Singers", KeySets(Key{2}, Key{3})),
+		}); err != nil {
+			return err
+		}
+		attempt = append(attempt, tx.BufferedMutationCount())
+		if err := tx.BufferWrite([]*Mutation{
+			Update("Singers", []string{"SingerId", "FirstName"}, []interface{}{1, "c"}),
+		}); err != nil {
+			return err
+		}
+		attempt = append(attempt, tx.BufferedMutationCount())
+		counts = append(counts, attempt)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := counts, [][]int64{{0, 4, 6}, {0, 4, 6}}; !testEqual(g, w) {
+		t.Fatalf("buffered mutation count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ReadWriteTransaction_VerifyMutationCount(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.SetCountCommitMutations(true)
+	var estimate int64
+	resp, err := client.ReadWriteTransactionWithOptions(context.Background(), func(ctx context.Context, tx *ReadWriteTransaction) error {
+		if err := tx.BufferWrite([]*Mutation{
+			Insert("Singers", []string{"SingerId", "FirstName", "LastName"}, []interface{}{1, "a", "b"}),
+			Delete("Singers", KeySets(Key{2}, Key{3})),
+			Update("Singers", []string{"SingerId", "FirstName"}, []interface{}{1, "c"}),
+		}); err != nil {
+			return err
+		}
+		estimate = tx.BufferedMutationCount()
+		return nil
+	}, TransactionOptions{VerifyMutationCount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := estimate, int64(6); g != w {
+		t.Fatalf("buffered mutation count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if resp.MutationCountMismatch {
+		t.Fatal("unexpected mutation count mismatch")
+	}
+	if resp.CommitStats != nil {
+		t.Fatalf("unexpected commit stats: %v", resp.CommitStats)
+	}
+	commits := commitRequestsFromServer(server)
+	if g, w := len(commits), 1; g != w {
+		t.Fatalf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !commits[0].ReturnCommitStats {
+		t.Fatal("commit request did not request commit stats")
+	}
+}
+
+func TestClient_ReadWriteTransaction_VerifyMutationCountMismatch(t *testing.T) {
+	t.Parallel()
+
+	// The mock server returns a mutation count of 1 for all commits.
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	var attempts int
+	resp, err := client.ReadWriteTransactionWithOptions(context.Background(), func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		return tx.BufferWrite([]*Mutation{
+			Insert("Singers", []string{"SingerId", "FirstName", "LastName"}, []interface{}{1, "a", "b"}),
+		})
+	}, TransactionOptions{VerifyMutationCount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.MutationCountMismatch {
+		t.Fatal("missing mutation count mismatch")
 	}
-	requests := drainRequestsFromServer(server.TestSpanner)
-	if err := compareRequests([]interface{}{
-		&sppb.BatchCreateSessionsRequest{},
-		&sppb.ExecuteSqlRequest{},
-		&sppb.BeginTransactionRequest{},
-		&sppb.ExecuteSqlRequest{},
-		&sppb.CommitRequest{},
-	}, requests); err != nil {
-		t.Fatal(err)
+	if resp.CommitTs.IsZero() {
+		t.Fatal("missing commit timestamp")
 	}
-	commit := requests[len(requests)-1].(*sppb.CommitRequest)
-	g, w := len(commit.Mutations), 1
-	if g != w {
-		t.Fatalf("mutations count mismatch\nGot:  %v\nWant: %v", g, w)
+	if g, w := attempts, 1; g != w {
+		t.Fatalf("attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if _, ok := req.(*sppb.RollbackRequest); ok {
+			t.Fatal("committed transaction was rolled back")
+		}
 	}
 }
 
@@ -2197,6 +3756,39 @@ func TestClient_ReadWriteTransaction_Update_QueryOptions(t *testing.T) {
 	}
 }
 
+func TestClient_ReadWriteTransaction_UpdateWithOptions_RequestOptions(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	if _, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		if _, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{
+			Priority:   sppb.RequestOptions_PRIORITY_LOW,
+			RequestTag: "bulk-delete",
+		}); err != nil {
+			return err
+		}
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}, TransactionOptions{TransactionTag: "mixed"}); err != nil {
+		t.Fatal(err)
+	}
+	var got []*sppb.RequestOptions
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			got = append(got, sqlReq.RequestOptions)
+		}
+	}
+	want := []*sppb.RequestOptions{
+		{Priority: sppb.RequestOptions_PRIORITY_LOW, RequestTag: "bulk-delete", TransactionTag: "mixed"},
+		{TransactionTag: "mixed"},
+	}
+	if diff := itestutil.Diff(got, want, cmpopts.IgnoreUnexported(sppb.RequestOptions{})); diff != "" {
+		t.Fatalf("RequestOptions mismatch. (+Got, -Want):%v", diff)
+	}
+}
+
 func TestClient_ReadWriteTransaction_TransactionOptions(t *testing.T) {
 	for _, tt := range transactionOptionsTestCases() {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2223,39 +3815,425 @@ func TestClient_ReadWriteTransaction_TransactionOptions(t *testing.T) {
 	}
 }
 
-func TestClient_ReadWriteTransactionWithOptions(t *testing.T) {
-	_, client, teardown := setupMockedTestServer(t)
+func TestClient_ReadWriteTransactionWithOptions(t *testing.T) {
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	resp, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		iter := tx.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+		defer iter.Stop()
+		rowCount := int64(0)
+		for {
+			row, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			var singerID, albumID int64
+			var albumTitle string
+			if err := row.Columns(&singerID, &albumID, &albumTitle); err != nil {
+				return err
+			}
+			rowCount++
+		}
+		if rowCount != SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount {
+			return status.Errorf(codes.FailedPrecondition, "Row count mismatch, got %v, expected %v", rowCount, SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount)
+		}
+		return nil
+	}, TransactionOptions{CommitOptions: CommitOptions{ReturnCommitStats: true}})
+	if err != nil {
+		t.Fatalf("Failed to execute the transaction: %s", err)
+	}
+	if got, want := resp.CommitStats.MutationCount, int64(1); got != want {
+		t.Fatalf("Mismatch mutation count - got: %d, want: %d", got, want)
+	}
+}
+
+func TestClient_ReadWriteTransaction_CommitPriorityDiffersFromStatementPriority(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		QueryOptions: QueryOptions{Priority: sppb.RequestOptions_PRIORITY_LOW},
+		ReadOptions:  ReadOptions{Priority: sppb.RequestOptions_PRIORITY_LOW},
+	})
+	defer teardown()
+	ctx := context.Background()
+	_, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		if _, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
+			return err
+		}
+		iter := tx.Read(ctx, "Albums", KeySets(Key{"foo"}), []string{"SingerId", "AlbumId", "AlbumTitle"})
+		return iter.Do(func(r *Row) error { return nil })
+	}, TransactionOptions{CommitPriority: sppb.RequestOptions_PRIORITY_HIGH})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numStatements, numCommits int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req := req.(type) {
+		case *sppb.ExecuteSqlRequest:
+			numStatements++
+			if g, w := req.RequestOptions.Priority, sppb.RequestOptions_PRIORITY_LOW; g != w {
+				t.Errorf("statement priority mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		case *sppb.ReadRequest:
+			numStatements++
+			if g, w := req.RequestOptions.Priority, sppb.RequestOptions_PRIORITY_LOW; g != w {
+				t.Errorf("read priority mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		case *sppb.CommitRequest:
+			numCommits++
+			if g, w := req.RequestOptions.Priority, sppb.RequestOptions_PRIORITY_HIGH; g != w {
+				t.Errorf("commit priority mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		}
+	}
+	if g, w := numStatements, 2; g != w {
+		t.Errorf("statement count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := numCommits, 1; g != w {
+		t.Errorf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ReadWriteTransactionDetectConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	// The interceptor blocks the first ExecuteSql call until the second
+	// Update has been attempted.
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var once sync.Once
+	interceptor := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if method == "/google.spanner.v1.Spanner/ExecuteSql" {
+			once.Do(func() {
+				close(started)
+				<-proceed
+			})
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	_, client, teardown := setupMockedTestServerWithConfigAndClientOptions(t, ClientConfig{}, []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(interceptor)),
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	var concurrentErr, concurrentBufferErr error
+	_, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+			errCh <- err
+		}()
+		<-started
+		_, concurrentErr = tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		concurrentBufferErr = tx.BufferWrite([]*Mutation{Insert("FOO", []string{"ID"}, []interface{}{1})})
+		close(proceed)
+		return <-errCh
+	}, TransactionOptions{DetectConcurrentUse: true})
+	if err != nil {
+		t.Fatalf("Failed to execute the transaction: %s", err)
+	}
+	if !errors.Is(concurrentErr, ErrTransactionConcurrentUse) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", concurrentErr, ErrTransactionConcurrentUse)
+	}
+	if g, w := ErrCode(concurrentErr), codes.FailedPrecondition; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !errors.Is(concurrentBufferErr, ErrTransactionConcurrentUse) {
+		t.Fatalf("BufferWrite error mismatch\nGot: %v\nWant: %v", concurrentBufferErr, ErrTransactionConcurrentUse)
+	}
+
+	// Sequential use of the transaction is allowed.
+	if _, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		for i := 0; i < 2; i++ {
+			if _, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, TransactionOptions{DetectConcurrentUse: true}); err != nil {
+		t.Fatalf("Failed to execute the transaction: %s", err)
+	}
+}
+
+func TestClient_ReadWriteTransactionWithMaxCommitBytes(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	ms := []*Mutation{
+		InsertOrUpdate("Accounts", []string{"AccountId", "Nickname"}, []interface{}{int64(1), strings.Repeat("a", 100)}),
+	}
+	bufferWrite := func(ctx context.Context, tx *ReadWriteTransaction) error {
+		return tx.BufferWrite(ms)
+	}
+
+	// Mutations below the threshold are committed.
+	if _, err := client.ReadWriteTransactionWithOptions(ctx, bufferWrite, TransactionOptions{MaxCommitBytes: 1000}); err != nil {
+		t.Fatalf("Failed to execute the transaction: %s", err)
+	}
+	if err := compareRequests([]interface{}{
+		&sppb.BatchCreateSessionsRequest{},
+		&sppb.BeginTransactionRequest{},
+		&sppb.CommitRequest{}}, drainRequestsFromServer(server.TestSpanner)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mutations above the threshold are rejected before the commit is sent.
+	_, err := client.ReadWriteTransactionWithOptions(ctx, bufferWrite, TransactionOptions{MaxCommitBytes: 100})
+	if !errors.Is(err, ErrCommitTooLarge) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, ErrCommitTooLarge)
+	}
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if err := compareRequests([]interface{}{
+		&sppb.BeginTransactionRequest{},
+		&sppb.RollbackRequest{}}, drainRequestsFromServer(server.TestSpanner)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_MutationAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	errProtected := errors.New("deletes from Protected are not allowed")
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		MutationAuthorizer: func(ctx context.Context, m *Mutation) error {
+			if m.op == opDelete && m.table == "Protected" {
+				return errProtected
+			}
+			return nil
+		},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	// Allowed mutations are committed.
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		return tx.BufferWrite([]*Mutation{Delete("Accounts", Key{int64(1)})})
+	}); err != nil {
+		t.Fatalf("Failed to execute the transaction: %s", err)
+	}
+	if g, w := len(commitRequestsFromServer(server)), 1; g != w {
+		t.Fatalf("commit count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+
+	// A rejected mutation fails the transaction and nothing is committed.
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		return tx.BufferWrite([]*Mutation{
+			InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(1)}),
+			Delete("Protected", Key{int64(1)}),
+		})
+	})
+	if !errors.Is(err, errProtected) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, errProtected)
+	}
+	if g, w := len(commitRequestsFromServer(server)), 0; g != w {
+		t.Fatalf("commit count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+
+	// A rejected mutation also fails the transaction if the transaction
+	// function ignores the error, and earlier mutations are not committed.
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		if err := tx.BufferWrite([]*Mutation{InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(1)})}); err != nil {
+			return err
+		}
+		_ = tx.BufferWrite([]*Mutation{Delete("Protected", Key{int64(1)})})
+		return nil
+	})
+	if !errors.Is(err, errProtected) {
+		t.Fatalf("error mismatch for ignored error\nGot: %v\nWant: %v", err, errProtected)
+	}
+	if g, w := len(commitRequestsFromServer(server)), 0; g != w {
+		t.Fatalf("commit count mismatch for ignored error\nGot: %d\nWant: %d", g, w)
+	}
+
+	// BatchWrite checks the mutations of all groups before sending any.
+	iter := client.BatchWrite(ctx, []*MutationGroup{
+		{Mutations: []*Mutation{InsertOrUpdate("Accounts", []string{"AccountId"}, []interface{}{int64(1)})}},
+		{Mutations: []*Mutation{Delete("Protected", AllKeys())}},
+	})
+	if err := iter.Do(func(r *sppb.BatchWriteResponse) error { return nil }); !errors.Is(err, errProtected) {
+		t.Fatalf("error mismatch for BatchWrite\nGot: %v\nWant: %v", err, errProtected)
+	}
+
+	// Apply also checks the mutations, both with and without at-least-once.
+	for _, opts := range [][]ApplyOption{nil, {ApplyAtLeastOnce()}} {
+		_, err := client.Apply(ctx, []*Mutation{Delete("Protected", AllKeys())}, opts...)
+		if !errors.Is(err, errProtected) {
+			t.Fatalf("error mismatch for Apply\nGot: %v\nWant: %v", err, errProtected)
+		}
+	}
+	if g, w := len(commitRequestsFromServer(server)), 0; g != w {
+		t.Fatalf("commit count mismatch for Apply\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+func TestClient_RejectZeroTime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	zero := []*Mutation{InsertOrUpdate("Events", []string{"Id", "CreatedAt"}, []interface{}{int64(1), time.Time{}})}
+	null := []*Mutation{InsertOrUpdate("Events", []string{"Id", "CreatedAt"}, []interface{}{int64(1), NullTime{}})}
+
+	_, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{RejectZeroTime: true})
+	defer teardown()
+	for _, opts := range [][]ApplyOption{nil, {ApplyAtLeastOnce()}} {
+		if _, err := client.Apply(ctx, zero, opts...); ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error mismatch for Apply\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+		}
+		if _, err := client.Apply(ctx, null, opts...); err != nil {
+			t.Fatalf("Unexpected error for Apply with NULL value: %v", err)
+		}
+	}
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		return tx.BufferWrite(zero)
+	}); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error mismatch for BufferWrite\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
+	stmt := Statement{
+		SQL:    SelectSingerIDAlbumIDAlbumTitleFromAlbums,
+		Params: map[string]interface{}{"ts": []NullTime{{}, {Valid: true}}},
+	}
+	iter := client.Single().Query(ctx, stmt)
+	defer iter.Stop()
+	if _, err := iter.Next(); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error mismatch for Query\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
+
+	// Zero time values are written without RejectZeroTime.
+	_, client, teardown = setupMockedTestServer(t)
+	defer teardown()
+	if _, err := client.Apply(ctx, zero); err != nil {
+		t.Fatalf("Unexpected error for Apply without RejectZeroTime: %v", err)
+	}
+}
+
+func TestClient_ReadWriteTransactionWithReadLockMode_Update(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		client sppb.TransactionOptions_ReadWrite_ReadLockMode
+		tx     sppb.TransactionOptions_ReadWrite_ReadLockMode
+		want   sppb.TransactionOptions_ReadWrite_ReadLockMode
+	}{
+		{"transaction", sppb.TransactionOptions_ReadWrite_READ_LOCK_MODE_UNSPECIFIED, sppb.TransactionOptions_ReadWrite_PESSIMISTIC, sppb.TransactionOptions_ReadWrite_PESSIMISTIC},
+		{"client", sppb.TransactionOptions_ReadWrite_OPTIMISTIC, sppb.TransactionOptions_ReadWrite_READ_LOCK_MODE_UNSPECIFIED, sppb.TransactionOptions_ReadWrite_OPTIMISTIC},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+				TransactionOptions: TransactionOptions{ReadLockMode: tt.client},
+			})
+			defer teardown()
+			ctx := context.Background()
+			if _, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+				_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+				return err
+			}, TransactionOptions{ReadLockMode: tt.tx}); err != nil {
+				t.Fatal(err)
+			}
+			var begin *sppb.TransactionOptions
+			for _, req := range drainRequestsFromServer(server.TestSpanner) {
+				if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+					begin = sqlReq.GetTransaction().GetBegin()
+				}
+			}
+			if begin == nil {
+				t.Fatal("update did not begin a transaction")
+			}
+			if g, w := begin.GetReadWrite().GetReadLockMode(), tt.want; g != w {
+				t.Fatalf("read lock mode mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		})
+	}
+}
+
+func TestClient_AutoScaleChannels(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		maxOpened uint64
+		want      int
+	}{
+		{1, 1},
+		{100, 1},
+		{101, 2},
+		{500, 5},
+		{4000, 40},
+		{100000, 40},
+	} {
+		if g, w := autoScaledNumChannels(test.maxOpened), test.want; g != w {
+			t.Errorf("MaxOpened=%d: number of channels mismatch\nGot: %v\nWant: %v", test.maxOpened, g, w)
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		config ClientConfig
+		want   int
+	}{
+		{"auto scaled", ClientConfig{AutoScaleChannels: true, SessionPoolConfig: SessionPoolConfig{MaxOpened: 500}}, 5},
+		{"disabled", ClientConfig{SessionPoolConfig: SessionPoolConfig{MaxOpened: 500}}, numChannels},
+		{"NumChannels", ClientConfig{AutoScaleChannels: true, NumChannels: 2, SessionPoolConfig: SessionPoolConfig{MaxOpened: 500}}, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, client, teardown := setupMockedTestServerWithConfig(t, test.config)
+			defer teardown()
+			if g, w := client.sc.connPool.Num(), test.want; g != w {
+				t.Fatalf("number of channels mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			// Sessions are created on and taken from the channels.
+			if _, err := client.Single().ReadRow(context.Background(), "Albums", Key{"foo"}, []string{"SingerId", "AlbumId", "AlbumTitle"}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestClient_DisableInlineBegin(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		DisableInlineBegin: true,
+	})
 	defer teardown()
 	ctx := context.Background()
-	resp, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
-		iter := tx.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
-		defer iter.Stop()
-		rowCount := int64(0)
-		for {
-			row, err := iter.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				return err
-			}
-			var singerID, albumID int64
-			var albumTitle string
-			if err := row.Columns(&singerID, &albumID, &albumTitle); err != nil {
+	for i := 0; i < 2; i++ {
+		if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			if _, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
 				return err
 			}
-			rowCount++
-		}
-		if rowCount != SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount {
-			return status.Errorf(codes.FailedPrecondition, "Row count mismatch, got %v, expected %v", rowCount, SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount)
+			_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+			return err
+		}); err != nil {
+			t.Fatal(err)
 		}
-		return nil
-	}, TransactionOptions{CommitOptions: CommitOptions{ReturnCommitStats: true}})
-	if err != nil {
-		t.Fatalf("Failed to execute the transaction: %s", err)
 	}
-	if got, want := resp.CommitStats.MutationCount, int64(1); got != want {
-		t.Fatalf("Mismatch mutation count - got: %d, want: %d", got, want)
+	requests := drainRequestsFromServer(server.TestSpanner)
+	if err := compareRequests([]interface{}{
+		&sppb.BatchCreateSessionsRequest{},
+		&sppb.BeginTransactionRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.CommitRequest{},
+		&sppb.BeginTransactionRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.ExecuteSqlRequest{},
+		&sppb.CommitRequest{},
+	}, requests); err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range requests {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok && sqlReq.GetTransaction().GetId() == nil {
+			t.Fatalf("statement did not use the explicitly begun transaction: %v", sqlReq.GetTransaction())
+		}
 	}
 }
 
@@ -2465,6 +4443,64 @@ func TestClient_SessionContainsDatabaseRole(t *testing.T) {
 	}
 }
 
+func TestClient_SessionPoolDatabaseRoleProvider(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var provided []string
+	sc := SessionPoolConfig{
+		MinOpened: 8,
+		MaxOpened: 8,
+		DatabaseRoleProvider: func() string {
+			mu.Lock()
+			defer mu.Unlock()
+			role := fmt.Sprintf("role-%d", len(provided))
+			provided = append(provided, role)
+			return role
+		},
+	}
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{SessionPoolConfig: sc, DatabaseRole: "test"})
+	defer teardown()
+	if err := client.WarmUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var requested []string
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if req, ok := req.(*sppb.BatchCreateSessionsRequest); ok {
+			requested = append(requested, req.SessionTemplate.CreatorRole)
+		}
+	}
+	mu.Lock()
+	want := append([]string(nil), provided...)
+	mu.Unlock()
+	sort.Strings(requested)
+	sort.Strings(want)
+	if !testEqual(requested, want) {
+		t.Fatalf("requested roles mismatch\nGot: %v\nWant: %v", requested, want)
+	}
+
+	sp := client.idleSessions
+	sp.mu.Lock()
+	var ids []string
+	for e := sp.idleList.Front(); e != nil; e = e.Next() {
+		ids = append(ids, e.Value.(*session).id)
+	}
+	sp.mu.Unlock()
+	if g, w := len(ids), 8; g != w {
+		t.Fatalf("session count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for _, id := range ids {
+		resp, err := server.TestSpanner.GetSession(context.Background(), &sppb.GetSessionRequest{Name: id})
+		if err != nil {
+			t.Fatalf("Failed to get session unexpectedly: %v", err)
+		}
+		if !strings.HasPrefix(resp.CreatorRole, "role-") {
+			t.Fatalf("database role mismatch for session %v\nGot: %v\nWant: a role of the provider", id, resp.CreatorRole)
+		}
+	}
+}
+
 func TestClient_SessionNotFound(t *testing.T) {
 	// Ensure we always have at least one session in the pool.
 	sc := SessionPoolConfig{
@@ -3251,9 +5287,352 @@ func TestClient_ApplyAtLeastOnce_NonRetryableInternalErrors(t *testing.T) {
 		SimulatedExecutionTime{
 			Errors: []error{status.Errorf(codes.Internal, "grpc: error while marshaling: string field contains invalid UTF-8")},
 		})
-	_, err := client.Apply(context.Background(), ms, ApplyAtLeastOnce())
-	if status.Code(err) != codes.Internal {
-		t.Fatalf("Error mismatch:\ngot: %v\nwant: %v", err, codes.Internal)
+	_, err := client.Apply(context.Background(), ms, ApplyAtLeastOnce())
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Error mismatch:\ngot: %v\nwant: %v", err, codes.Internal)
+	}
+}
+
+func TestClient_ApplyAtLeastOnce_RetryReturnsCommitTimestamp(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ms := []*Mutation{
+		Insert("Accounts", []string{"AccountId", "Nickname", "Balance"}, []interface{}{int64(1), "Foo", int64(50)}),
+	}
+	// The first commit fails with a transient error that does not tell
+	// whether the commit succeeded. The retry succeeds.
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.Internal, "stream terminated by RST_STREAM")},
+		})
+	before := time.Now()
+	ts, err := client.Apply(context.Background(), ms, ApplyAtLeastOnce())
+	if err != nil {
+		t.Fatalf("Unexpected error after a transient commit failure: %v", err)
+	}
+	if ts.IsZero() || ts.Before(before.Add(-time.Second)) {
+		t.Fatalf("commit timestamp mismatch\nGot: %v\nWant: timestamp of the successful commit after %v", ts, before)
+	}
+	if g, w := len(commitRequestsFromServer(server)), 2; g != w {
+		t.Fatalf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_PoolStats(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 10,
+			MaxOpened: 20,
+		},
+	})
+	defer teardown()
+
+	waitFor(t, func() error {
+		stats := client.PoolStats()
+		if stats.NumIdle != 10 {
+			return fmt.Errorf("num idle sessions mismatch\nGot: %v\nWant: %v", stats.NumIdle, 10)
+		}
+		return nil
+	})
+	want := PoolStats{NumOpened: 10, NumIdle: 10, MaxAllowed: 20}
+	if got := client.PoolStats(); !testEqual(got, want) {
+		t.Fatalf("pool stats mismatch\nGot: %+v\nWant: %+v", got, want)
+	}
+
+	ctx := context.Background()
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+	want = PoolStats{NumOpened: 10, NumInUse: 1, NumIdle: 9, MaxAllowed: 20, NumSessionsTaken: 1}
+	if got := client.PoolStats(); !testEqual(got, want) {
+		t.Fatalf("pool stats mismatch\nGot: %+v\nWant: %+v", got, want)
+	}
+	iter.Stop()
+	want = PoolStats{NumOpened: 10, NumIdle: 10, MaxAllowed: 20, NumSessionsTaken: 1}
+	if got := client.PoolStats(); !testEqual(got, want) {
+		t.Fatalf("pool stats mismatch\nGot: %+v\nWant: %+v", got, want)
+	}
+}
+
+func TestClient_CloseWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	tx := client.ReadOnlyTransaction()
+	if err := executeSingerQuery(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := tx.sh.getID()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		tx.Close()
+	}()
+	closeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := client.CloseWithTimeout(closeCtx); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+	var deleted bool
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if r, ok := req.(*sppb.DeleteSessionRequest); ok && r.Name == sessionID {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Fatalf("session %v that was in use was not deleted", sessionID)
+	}
+	if _, err := client.Single().ReadRow(ctx, "Albums", Key{1}, []string{"AlbumTitle"}); err != errInvalidSessionPool {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, errInvalidSessionPool)
+	}
+}
+
+func TestClient_CloseWithTimeout_SessionsInUse(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	if err := executeSingerQuery(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := tx.sh.getID()
+	closeCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err := client.CloseWithTimeout(closeCtx)
+	if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !strings.Contains(err.Error(), sessionID) {
+		t.Fatalf("error %v does not contain session %v", err, sessionID)
+	}
+}
+
+func TestClient_WarmUp(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 25,
+			MaxOpened: 100,
+		},
+	})
+	defer teardown()
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.WarmUp(ctx)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("%d: WarmUp failed: %v", i, err)
+		}
+	}
+	stats := client.PoolStats()
+	if g, w := stats.NumOpened, uint64(25); g != w {
+		t.Fatalf("num opened mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := stats.NumBeingCreated, uint64(0); g != w {
+		t.Fatalf("num being created mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	// WarmUp returns directly when the pool has been warmed up.
+	if err := client.WarmUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := client.PoolStats().NumOpened, uint64(25); g != w {
+		t.Fatalf("num opened mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_WarmUp_SessionCreationError(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 0,
+			MaxOpened: 100,
+		},
+	})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodBatchCreateSession, SimulatedExecutionTime{
+		Errors:    []error{status.Error(codes.PermissionDenied, "permission denied")},
+		KeepError: true,
+	})
+	sp := client.idleSessions
+	sp.mu.Lock()
+	sp.MinOpened = 10
+	sp.mu.Unlock()
+
+	ctx := context.Background()
+	if g, w := ErrCode(client.WarmUp(ctx)), codes.PermissionDenied; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := client.PoolStats().NumOpened, uint64(0); g != w {
+		t.Fatalf("num opened mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// WarmUp creates the missing sessions when it is called again.
+	server.TestSpanner.PutExecutionTime(MethodBatchCreateSession, SimulatedExecutionTime{})
+	if err := client.WarmUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := client.PoolStats().NumOpened, uint64(10); g != w {
+		t.Fatalf("num opened mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_WarmUp_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 0,
+			MaxOpened: 100,
+		},
+	})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodBatchCreateSession, SimulatedExecutionTime{
+		MinimumExecutionTime: time.Second,
+	})
+	sp := client.idleSessions
+	sp.mu.Lock()
+	sp.MinOpened = 10
+	sp.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if g, w := ErrCode(client.WarmUp(ctx)), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_MaxCommitDelay(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	ms := []*Mutation{Insert("Accounts", []string{"AccountId"}, []interface{}{int64(1)})}
+	delay := 100 * time.Millisecond
+
+	if _, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		return tx.BufferWrite(ms)
+	}, TransactionOptions{CommitOptions: CommitOptions{MaxCommitDelay: &delay}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Apply(ctx, ms, MaxCommitDelay(delay)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Apply(ctx, ms, MaxCommitDelay(delay), ApplyAtLeastOnce()); err != nil {
+		t.Fatal(err)
+	}
+	// The max commit delay is nil by default.
+	if _, err := client.Apply(ctx, ms); err != nil {
+		t.Fatal(err)
+	}
+	commits := commitRequestsFromServer(server)
+	if g, w := len(commits), 4; g != w {
+		t.Fatalf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for i, commit := range commits[:3] {
+		if g, w := commit.MaxCommitDelay.AsDuration(), delay; g != w {
+			t.Errorf("%d: max commit delay mismatch\nGot: %v\nWant: %v", i, g, w)
+		}
+	}
+	if commits[3].MaxCommitDelay != nil {
+		t.Errorf("max commit delay mismatch\nGot: %v\nWant: nil", commits[3].MaxCommitDelay)
+	}
+
+	for _, invalid := range []time.Duration{-time.Millisecond, 501 * time.Millisecond} {
+		invalid := invalid
+		called := false
+		_, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			called = true
+			return tx.BufferWrite(ms)
+		}, TransactionOptions{CommitOptions: CommitOptions{MaxCommitDelay: &invalid}})
+		if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+			t.Errorf("%v: error code mismatch\nGot: %v\nWant: %v", invalid, g, w)
+		}
+		if called {
+			t.Errorf("%v: transaction function was called with an invalid MaxCommitDelay", invalid)
+		}
+		if _, err := client.Apply(ctx, ms, MaxCommitDelay(invalid)); ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("%v: error code mismatch\nGot: %v\nWant: %v", invalid, ErrCode(err), codes.InvalidArgument)
+		}
+		if _, err := client.Apply(ctx, ms, MaxCommitDelay(invalid), ApplyAtLeastOnce()); ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("%v: error code mismatch\nGot: %v\nWant: %v", invalid, ErrCode(err), codes.InvalidArgument)
+		}
+	}
+	if g, w := len(commitRequestsFromServer(server)), 0; g != w {
+		t.Errorf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ApplyWithResponse(t *testing.T) {
+	t.Parallel()
+	ms := []*Mutation{
+		Insert("Accounts", []string{"AccountId", "Nickname", "Balance"}, []interface{}{int64(1), "Foo", int64(50)}),
+	}
+	for _, test := range []struct {
+		name string
+		opts []ApplyOption
+	}{
+		{"read/write", nil},
+		{"at least once", []ApplyOption{ApplyAtLeastOnce()}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server, client, teardown := setupMockedTestServer(t)
+			defer teardown()
+
+			resp, err := client.ApplyWithResponse(context.Background(), ms, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.CommitTs.IsZero() {
+				t.Fatal("missing commit timestamp")
+			}
+			if resp.CommitStats == nil {
+				t.Fatal("missing commit stats")
+			}
+			if g, w := resp.CommitStats.MutationCount, int64(1); g != w {
+				t.Fatalf("mutation count mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			commits := commitRequestsFromServer(server)
+			if g, w := len(commits), 1; g != w {
+				t.Fatalf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			if !commits[0].ReturnCommitStats {
+				t.Fatal("ReturnCommitStats was not set on the CommitRequest")
+			}
+
+			// Apply does not request commit stats.
+			if _, err := client.Apply(context.Background(), ms, test.opts...); err != nil {
+				t.Fatal(err)
+			}
+			commits = commitRequestsFromServer(server)
+			if g, w := len(commits), 1; g != w {
+				t.Fatalf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			if commits[0].ReturnCommitStats {
+				t.Fatal("ReturnCommitStats was set on the CommitRequest of Apply")
+			}
+		})
 	}
 }
 
@@ -3917,6 +6296,293 @@ func TestClient_WithGRPCConnectionPoolAndNumChannels(t *testing.T) {
 	}
 }
 
+func TestClient_EmptyStringAsNull(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name              string
+		emptyStringAsNull bool
+		wantNull          bool
+	}{
+		{"enabled", true, true},
+		{"disabled", false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+				EmptyStringAsNull: tt.emptyStringAsNull,
+			})
+			defer teardown()
+			ctx := context.Background()
+			empty := ""
+			if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+				if _, err := tx.Update(ctx, Statement{
+					SQL:    UpdateBarSetFoo,
+					Params: map[string]interface{}{"p1": "", "p2": NullString{StringVal: "", Valid: true}, "p3": "foo"},
+				}); err != nil {
+					return err
+				}
+				return tx.BufferWrite([]*Mutation{
+					Insert("Singers", []string{"A", "B", "C", "D"}, []interface{}{"", &empty, NullString{StringVal: "", Valid: true}, "foo"}),
+				})
+			}); err != nil {
+				t.Fatal(err)
+			}
+			isNull := func(v *structpb.Value) bool {
+				_, ok := v.GetKind().(*structpb.Value_NullValue)
+				return ok
+			}
+			var sqlReq *sppb.ExecuteSqlRequest
+			var commitReq *sppb.CommitRequest
+			for _, req := range drainRequestsFromServer(server.TestSpanner) {
+				switch r := req.(type) {
+				case *sppb.ExecuteSqlRequest:
+					sqlReq = r
+				case *sppb.CommitRequest:
+					commitReq = r
+				}
+			}
+			params := sqlReq.GetParams().GetFields()
+			if g, w := isNull(params["p1"]), tt.wantNull; g != w {
+				t.Fatalf("p1 NULL mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			if isNull(params["p2"]) || isNull(params["p3"]) {
+				t.Fatalf("NullString or non-empty string parameter was written as NULL: %v", params)
+			}
+			if g, w := sqlReq.GetParamTypes()["p1"].GetCode(), sppb.TypeCode_STRING; g != w {
+				t.Fatalf("p1 type mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			values := commitReq.GetMutations()[0].GetInsert().GetValues()[0].GetValues()
+			for i, want := range []bool{tt.wantNull, tt.wantNull, false, false} {
+				if g := isNull(values[i]); g != want {
+					t.Fatalf("value %d NULL mismatch\nGot: %v\nWant: %v", i, g, want)
+				}
+			}
+
+			// The parameters of queries are never changed.
+			iter := client.Single().Query(ctx, Statement{
+				SQL:    SelectSingerIDAlbumIDAlbumTitleFromAlbums,
+				Params: map[string]interface{}{"p1": ""},
+			})
+			if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+			for _, req := range drainRequestsFromServer(server.TestSpanner) {
+				if r, ok := req.(*sppb.ExecuteSqlRequest); ok && isNull(r.GetParams().GetFields()["p1"]) {
+					t.Fatalf("query parameter was written as NULL: %v", r.GetParams())
+				}
+			}
+		})
+	}
+}
+
+func TestClient_TableKeyArity(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		TableKeyArity: map[string]int{"Kv": 1},
+	})
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: KvMeta(),
+			Rows: []*structpb.ListValue{
+				{Values: []*structpb.Value{structpb.NewStringValue("k"), structpb.NewStringValue("v")}},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Keys with the registered number of parts are accepted.
+	if _, err := client.Single().ReadRow(ctx, "Kv", Key{"k"}, []string{"Key", "Value"}); err != nil {
+		t.Fatalf("Unexpected error for ReadRow with a valid key: %v", err)
+	}
+	if _, err := client.Apply(ctx, []*Mutation{Delete("Kv", KeySets(Key{"k"}, KeyRange{Start: Key{"a"}, End: Key{"b"}}))}); err != nil {
+		t.Fatalf("Unexpected error for Delete with a valid key: %v", err)
+	}
+	// Tables that are not registered are not checked.
+	if _, err := client.Apply(ctx, []*Mutation{Delete("Accounts", Key{int64(1), "extra"})}, ApplyAtLeastOnce()); err != nil {
+		t.Fatalf("Unexpected error for Delete from an unregistered table: %v", err)
+	}
+	drainRequestsFromServer(server.TestSpanner)
+
+	// Keys with a different number of parts are rejected without sending a
+	// request to Spanner.
+	invalid := Key{"k", int64(1)}
+	if _, err := client.Single().ReadRow(ctx, "Kv", invalid, []string{"Key", "Value"}); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error mismatch for ReadRow\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
+	if _, _, err := client.Single().TryReadRow(ctx, "Kv", invalid, []string{"Key", "Value"}); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error mismatch for TryReadRow\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
+	for _, opts := range [][]ApplyOption{nil, {ApplyAtLeastOnce()}} {
+		if _, err := client.Apply(ctx, []*Mutation{Delete("Kv", KeySets(Key{"k"}, invalid))}, opts...); ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error mismatch for Delete with options %v\nGot: %v\nWant: %v", opts, err, codes.InvalidArgument)
+		}
+	}
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req.(type) {
+		case *sppb.ReadRequest, *sppb.CommitRequest:
+			t.Fatalf("unexpected request for an invalid key: %v", req)
+		}
+	}
+}
+
+func TestClient_SessionUsageObserver(t *testing.T) {
+	t.Parallel()
+
+	type usage struct {
+		sessionID string
+		op        string
+		held      time.Duration
+	}
+	var (
+		mu     sync.Mutex
+		usages []usage
+	)
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionUsageObserver: func(sessionID string, op string, held time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			usages = append(usages, usage{sessionID, op, held})
+		},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	if err := executeSingerQuery(ctx, client.Single()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := server.TestSpanner.DumpSessions()
+	mu.Lock()
+	defer mu.Unlock()
+	var ops []string
+	for _, u := range usages {
+		if !sessions[u.sessionID] {
+			t.Fatalf("observer called with unknown session id %q", u.sessionID)
+		}
+		if u.held < 0 {
+			t.Fatalf("negative held duration for session %q: %v", u.sessionID, u.held)
+		}
+		ops = append(ops, u.op)
+	}
+	if g, w := ops, []string{"SingleUse", "ReadWriteTransaction"}; !testEqual(g, w) {
+		t.Fatalf("operations mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_DetectDialect(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		config ClientConfig
+		value  string
+		want   adminpb.DatabaseDialect
+	}{
+		{"PostgreSQL", ClientConfig{DetectDialect: true}, "POSTGRESQL", adminpb.DatabaseDialect_POSTGRESQL},
+		{"GoogleSQL", ClientConfig{DetectDialect: true}, "GOOGLE_STANDARD_SQL", adminpb.DatabaseDialect_GOOGLE_STANDARD_SQL},
+		{"disabled", ClientConfig{}, "POSTGRESQL", adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server, opts, serverTeardown := NewMockedSpannerInMemTestServer(t)
+			defer serverTeardown()
+			if err := server.TestSpanner.PutStatementResult(dialectSQL, &StatementResult{
+				Type: StatementResultResultSet,
+				ResultSet: &sppb.ResultSet{
+					Metadata: &sppb.ResultSetMetadata{
+						RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: "option_value", Type: &sppb.Type{Code: sppb.TypeCode_STRING}}}},
+					},
+					Rows: []*structpb.ListValue{{Values: []*structpb.Value{structpb.NewStringValue(tt.value)}}},
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClientWithConfig(context.Background(), "projects/p/instances/i/databases/d", tt.config, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			if g, w := client.Dialect(), tt.want; g != w {
+				t.Fatalf("dialect mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		})
+	}
+}
+
+func TestClient_DetectDialect_Error(t *testing.T) {
+	t.Parallel()
+
+	server, opts, serverTeardown := NewMockedSpannerInMemTestServer(t)
+	defer serverTeardown()
+	server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.PermissionDenied, "permission denied")},
+	})
+	_, err := NewClientWithConfig(context.Background(), "projects/p/instances/i/databases/d", ClientConfig{DetectDialect: true}, opts...)
+	if g, w := ErrCode(err), codes.PermissionDenied; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_MaxReceiveMessageSize(t *testing.T) {
+	t.Parallel()
+
+	sql := "SELECT Value FROM LargeValues"
+	largeResult := &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: &sppb.ResultSetMetadata{
+				RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: "Value", Type: &sppb.Type{Code: sppb.TypeCode_STRING}}}},
+			},
+			Rows: []*structpb.ListValue{{Values: []*structpb.Value{structpb.NewStringValue(strings.Repeat("a", 64*1024))}}},
+		},
+	}
+	for _, test := range []struct {
+		name     string
+		size     int
+		wantCode codes.Code
+	}{
+		{name: "default", size: 0, wantCode: codes.OK},
+		{name: "too small", size: 16 * 1024, wantCode: codes.ResourceExhausted},
+		{name: "raised", size: 1 << 20, wantCode: codes.OK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{MaxReceiveMessageSize: test.size})
+			defer teardown()
+			if err := server.TestSpanner.PutStatementResult(sql, largeResult); err != nil {
+				t.Fatal(err)
+			}
+			iter := client.Single().Query(context.Background(), NewStatement(sql))
+			defer iter.Stop()
+			_, err := iter.Next()
+			if g, w := ErrCode(err), test.wantCode; g != w {
+				t.Fatalf("error code mismatch\nGot: %v (%v)\nWant: %v", g, err, w)
+			}
+		})
+	}
+}
+
+func TestClient_MaxReceiveMessageSize_OutOfRange(t *testing.T) {
+	t.Parallel()
+
+	server, opts, serverTeardown := NewMockedSpannerInMemTestServer(t)
+	defer serverTeardown()
+	config := ClientConfig{MaxReceiveMessageSize: -1}
+	_, err := makeClientWithConfig(context.Background(), "projects/p/instances/i/databases/d", config, server.ServerAddress, opts...)
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v (%v)\nWant: %v", g, err, w)
+	}
+}
+
 func TestClient_WithGRPCConnectionPoolAndNumChannels_Misconfigured(t *testing.T) {
 	t.Parallel()
 
@@ -4510,7 +7176,13 @@ func transactionOptionsTestCases() []TransactionOptionsTestCase {
 			name:   "Read lock mode is optimistic",
 			client: &TransactionOptions{ReadLockMode: sppb.TransactionOptions_ReadWrite_OPTIMISTIC},
 			write:  &TransactionOptions{},
-			want:   &TransactionOptions{},
+			want:   &TransactionOptions{ReadLockMode: sppb.TransactionOptions_ReadWrite_OPTIMISTIC},
+		},
+		{
+			name:   "Write level has precedence than client level ReadLockMode",
+			client: &TransactionOptions{ReadLockMode: sppb.TransactionOptions_ReadWrite_OPTIMISTIC},
+			write:  &TransactionOptions{ReadLockMode: sppb.TransactionOptions_ReadWrite_PESSIMISTIC},
+			want:   &TransactionOptions{ReadLockMode: sppb.TransactionOptions_ReadWrite_PESSIMISTIC},
 		},
 	}
 }
@@ -4845,6 +7517,116 @@ func TestClient_ReadWriteTransaction_Tag(t *testing.T) {
 	}
 }
 
+func TestClient_ReadWriteTransaction_ReadCommitTimestamp(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	sql := "SELECT LastUpdated FROM Singers WHERE SingerId=1"
+	putResult := func(v *structpb.Value) {
+		if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+			Type: StatementResultResultSet,
+			ResultSet: &sppb.ResultSet{
+				Metadata: &sppb.ResultSetMetadata{
+					RowType: &sppb.StructType{
+						Fields: []*sppb.StructType_Field{{Name: "LastUpdated", Type: timeType()}},
+					},
+				},
+				Rows: []*structpb.ListValue{{Values: []*structpb.Value{v}}},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type singer struct {
+		LastUpdated NullTime
+	}
+
+	// Reading the column in the transaction that writes the commit timestamp
+	// returns the placeholder of the pending commit timestamp.
+	putResult(stringProto(commitTimestampPlaceholderString))
+	commitTs, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		if err := tx.BufferWrite([]*Mutation{
+			InsertOrUpdate("Singers", []string{"SingerId", "LastUpdated"}, []interface{}{1, CommitTimestamp}),
+		}); err != nil {
+			return err
+		}
+		row, err := tx.Query(ctx, NewStatement(sql)).Next()
+		if err != nil {
+			return err
+		}
+		var s singer
+		err = row.ToStruct(&s)
+		if g, w := ErrCode(err), codes.FailedPrecondition; g != w {
+			t.Errorf("error code mismatch for NullTime\nGot: %v\nWant: %v", err, w)
+		}
+		var ts time.Time
+		err = row.Column(0, &ts)
+		if g, w := ErrCode(err), codes.FailedPrecondition; g != w {
+			t.Errorf("error code mismatch for time.Time\nGot: %v\nWant: %v", err, w)
+		}
+		if err != nil && !strings.Contains(err.Error(), "pending commit timestamp") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the column after the commit returns the commit timestamp.
+	putResult(timeProto(commitTs))
+	row, err := client.Single().Query(ctx, NewStatement(sql)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s singer
+	if err := row.ToStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := s.LastUpdated, (NullTime{Time: commitTs, Valid: true}); !g.Time.Equal(w.Time) || !g.Valid {
+		t.Fatalf("commit timestamp mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ReadWriteTransaction_SetTag(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	for _, to := range []TransactionOptions{
+		{},
+		{TransactionTag: "tx-tag-1"},
+	} {
+		for _, qo := range []QueryOptions{
+			{},
+			{RequestTag: "request-tag-1"},
+		} {
+			_, err := client.ReadWriteTransactionWithOptions(context.Background(), func(ctx context.Context, tx *ReadWriteTransaction) error {
+				if _, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), qo); err != nil {
+					return err
+				}
+				checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, &sppb.RequestOptions{RequestTag: qo.RequestTag, TransactionTag: to.TransactionTag})
+
+				tx.SetTag("set-tag")
+				iter := tx.QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), qo)
+				iter.Next()
+				iter.Stop()
+				if _, err := tx.UpdateWithOptions(ctx, NewStatement(UpdateBarSetFoo), qo); err != nil {
+					return err
+				}
+				checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 2, &sppb.RequestOptions{RequestTag: qo.RequestTag, TransactionTag: "set-tag"})
+				return nil
+			}, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCommitForExpectedRequestOptions(t, server.TestSpanner, &sppb.RequestOptions{TransactionTag: "set-tag"})
+		}
+	}
+}
+
 func TestClient_StmtBasedReadWriteTransaction_Tag(t *testing.T) {
 	t.Parallel()
 
@@ -5249,6 +8031,94 @@ func TestClient_BatchWrite_Error(t *testing.T) {
 	}
 }
 
+func TestClient_BatchWriteCommitTimestamps(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ts1 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ts2 := time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)
+	// The responses are returned out of order, and one response covers two
+	// mutation groups.
+	server.TestSpanner.PutBatchWriteResponses([]*sppb.BatchWriteResponse{
+		{Indexes: []int32{3}, Status: status.New(codes.AlreadyExists, "row exists").Proto()},
+		{Indexes: []int32{2, 0}, Status: status.New(codes.OK, "").Proto(), CommitTimestamp: timestamppb.New(ts1)},
+		{Indexes: []int32{1}, Status: status.New(codes.NotFound, "table not found").Proto()},
+		{Indexes: []int32{4}, Status: status.New(codes.OK, "").Proto(), CommitTimestamp: timestamppb.New(ts2)},
+	})
+	var mutationGroups []*MutationGroup
+	for i := 0; i < 5; i++ {
+		mutationGroups = append(mutationGroups, &MutationGroup{[]*Mutation{
+			{opInsertOrUpdate, "t_test", nil, []string{"key", "val"}, []interface{}{fmt.Sprintf("foo%d", i), i}},
+		}})
+	}
+	timestamps, err := client.BatchWriteCommitTimestamps(context.Background(), mutationGroups, BatchWriteOptions{})
+	if err == nil {
+		t.Fatal("missing error for failed mutation groups")
+	}
+	if g, w := timestamps, map[int]time.Time{0: ts1, 2: ts1, 4: ts2}; !testEqual(g, w) {
+		t.Fatalf("commit timestamps mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("error does not wrap the errors of the mutation groups: %v", err)
+	}
+	var codesGot []codes.Code
+	for _, e := range joined.Unwrap() {
+		codesGot = append(codesGot, ErrCode(e))
+	}
+	if g, w := codesGot, []codes.Code{codes.AlreadyExists, codes.NotFound}; !testEqual(g, w) {
+		t.Fatalf("error codes mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for _, s := range []string{"mutation group 3", "mutation group 1"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("error %q does not contain %q", err.Error(), s)
+		}
+	}
+}
+
+func TestClient_BatchWriteCommitTimestamps_AllSucceed(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	mutationGroups := []*MutationGroup{
+		{[]*Mutation{{opInsertOrUpdate, "t_test", nil, []string{"key", "val"}, []interface{}{"foo1", 1}}}},
+		{[]*Mutation{{opInsertOrUpdate, "t_test", nil, []string{"key", "val"}, []interface{}{"foo2", 2}}}},
+	}
+	timestamps, err := client.BatchWriteCommitTimestamps(context.Background(), mutationGroups, BatchWriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(timestamps), len(mutationGroups); g != w {
+		t.Fatalf("commit timestamp count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	for i := range mutationGroups {
+		if timestamps[i].IsZero() {
+			t.Fatalf("missing commit timestamp for mutation group %d", i)
+		}
+	}
+}
+
+func TestClient_BatchWriteCommitTimestamps_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	mutationGroups := []*MutationGroup{
+		{[]*Mutation{{opInsertOrUpdate, "t_test", nil, []string{"key", "val"}, []interface{}{"foo1", 1}}}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	timestamps, err := client.BatchWriteCommitTimestamps(ctx, mutationGroups, BatchWriteOptions{})
+	if g, w := ErrCode(err), codes.Canceled; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if len(timestamps) != 0 {
+		t.Fatalf("unexpected commit timestamps: %v", timestamps)
+	}
+}
+
 func checkBatchWriteForExpectedRequestOptions(t *testing.T, server InMemSpannerServer, want *sppb.RequestOptions) {
 	reqs := drainRequestsFromServer(server)
 	var got *sppb.RequestOptions
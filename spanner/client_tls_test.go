@@ -0,0 +1,143 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+)
+
+// newTestTLSCertificate returns a self-signed certificate for localhost that
+// can be used both by a server and by a client, and a pool that contains the
+// certificate.
+func newTestTLSCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, pool
+}
+
+func TestClient_TLSConfig(t *testing.T) {
+	t.Parallel()
+
+	cert, pool := newTestTLSCertificate(t)
+	var (
+		mu            sync.Mutex
+		authorization []string
+		peerCerts     int
+	)
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		mu.Lock()
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			authorization = md.Get("authorization")
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				peerCerts = len(info.State.PeerCertificates)
+			}
+		}
+		mu.Unlock()
+		return handler(ctx, req)
+	}
+	server, _, teardown := NewMockedSpannerInMemTestServer(t,
+		grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pool,
+		})),
+		grpc.UnaryInterceptor(interceptor))
+	defer teardown()
+
+	var clientCertRequested bool
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		ServerName: "localhost",
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			mu.Lock()
+			clientCertRequested = true
+			mu.Unlock()
+			return &cert, nil
+		},
+	}
+	ctx := context.Background()
+	client, err := NewClientWithConfig(ctx, "projects/p/instances/i/databases/d", ClientConfig{TLSConfig: tlsConfig},
+		option.WithEndpoint(server.ServerAddress),
+		option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	iter := client.Single().Query(ctx, NewStatement(SelectFooFromBar))
+	if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !clientCertRequested {
+		t.Fatal("the client certificate of the TLS config was not used")
+	}
+	if g, w := peerCerts, 1; g != w {
+		t.Fatalf("client certificate count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := authorization, []string{"Bearer test-token"}; !testEqual(g, w) {
+		t.Fatalf("authorization header mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import "time"
+
+// clock is the source of the current time and of timers for the maintenance
+// of the session pool and for the health checks of the sessions. The default
+// is the wall clock. Tests can use a clock that is advanced manually to make
+// the maintenance of the pool deterministic.
+type clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a timer that fires after the given duration.
+	NewTimer(d time.Duration) clockTimer
+}
+
+// clockTimer is a timer that is created by a clock.
+type clockTimer interface {
+	// C returns the channel on which the time is delivered when the timer
+	// fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// wallClock is the clock that uses the system time.
+type wallClock struct{}
+
+// Now implements clock.Now.
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements clock.NewTimer.
+func (wallClock) NewTimer(d time.Duration) clockTimer {
+	return wallTimer{time.NewTimer(d)}
+}
+
+// wallTimer is the clockTimer of wallClock.
+type wallTimer struct {
+	t *time.Timer
+}
+
+// C implements clockTimer.C.
+func (t wallTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+// Stop implements clockTimer.Stop.
+func (t wallTimer) Stop() bool {
+	return t.t.Stop()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"math"
+
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ConnPool is a pool of gRPC connections to Cloud Spanner that can be shared
+// by multiple Clients, for example Clients for different databases in the same
+// instance. Create a ConnPool with NewConnPool and a Client that uses it with
+// NewClientWithConnPool.
+type ConnPool struct {
+	pool gtransport.ConnPool
+}
+
+// NewConnPool dials a pool of gRPC connections to Cloud Spanner. The
+// NumChannels, AutoScaleChannels, Compression, MaxReceiveMessageSize and
+// TLSConfig fields of config are used in the same way as by
+// NewClientWithConfig. The other fields of config are ignored.
+//
+// The ConnPool must be closed with Close after all Clients that use it have
+// been closed.
+func NewConnPool(ctx context.Context, config ClientConfig, opts ...option.ClientOption) (*ConnPool, error) {
+	if config.MaxReceiveMessageSize < 0 || config.MaxReceiveMessageSize > math.MaxInt32 {
+		return nil, errMaxReceiveMessageSizeOutOfRange(config.MaxReceiveMessageSize)
+	}
+	pool, err := dialConnPool(ctx, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConnPool{pool: pool}, nil
+}
+
+// Num returns the number of connections in the pool.
+func (p *ConnPool) Num() int {
+	return p.pool.Num()
+}
+
+// Close closes the connections of the pool.
+func (p *ConnPool) Close() error {
+	return p.pool.Close()
+}
+
+// sharedConnPool is the gtransport.ConnPool of a Client that uses a ConnPool.
+// Closing the Client does not close the connections, as these are owned by
+// the ConnPool.
+type sharedConnPool struct {
+	gtransport.ConnPool
+}
+
+// Close implements gtransport.ConnPool.Close.
+func (sharedConnPool) Close() error {
+	return nil
+}
+
+// errNilConnPool returns error for creating a Client with a nil ConnPool.
+func errNilConnPool() error {
+	return spannerErrorf(codes.InvalidArgument, "pool must not be nil")
+}
+
+// NewClientWithConnPool creates a client to a database that uses the
+// connections of the given pool. A valid database name has the form
+// projects/PROJECT_ID/instances/INSTANCE_ID/databases/DATABASE_ID.
+//
+// Multiple Clients can use the same pool. Closing a Client does not close the
+// connections of the pool. The connection settings of config, such as
+// NumChannels, MaxReceiveMessageSize and TLSConfig, are ignored, as these are
+// determined by the pool.
+func NewClientWithConnPool(ctx context.Context, database string, config ClientConfig, pool *ConnPool) (*Client, error) {
+	if pool == nil {
+		return nil, errNilConnPool()
+	}
+	return newClientWithConfig(ctx, database, config, sharedConnPool{pool.pool})
+}
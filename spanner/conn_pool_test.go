@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"testing"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestNewClientWithConnPool(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, opts, serverTeardown := NewMockedSpannerInMemTestServer(t)
+	defer serverTeardown()
+	pool, err := NewConnPool(ctx, ClientConfig{NumChannels: 2}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if g, w := pool.Num(), 2; g != w {
+		t.Fatalf("number of connections mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	var clients []*Client
+	for _, db := range []string{"projects/p/instances/i/databases/d1", "projects/p/instances/i/databases/d2"} {
+		client, err := NewClientWithConnPool(ctx, db, ClientConfig{}, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+	}
+	query := func(client *Client) {
+		t.Helper()
+		var rowCount int64
+		iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+		if err := iter.Do(func(r *Row) error {
+			rowCount++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if g, w := rowCount, int64(SelectSingerIDAlbumIDAlbumTitleFromAlbumsRowCount); g != w {
+			t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+		}
+	}
+	for _, client := range clients {
+		if g, w := client.sc.connPool.Num(), pool.Num(); g != w {
+			t.Fatalf("number of client connections mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		query(client)
+	}
+	// Closing one client does not close the shared connections.
+	clients[0].Close()
+	query(clients[1])
+	clients[1].Close()
+
+	if _, err := NewClientWithConnPool(ctx, "projects/p/instances/i/databases/d", ClientConfig{}, nil); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("nil pool: got error %v, want InvalidArgument", err)
+	}
+}
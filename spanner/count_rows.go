@@ -0,0 +1,73 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// errInvalidCountTableName returns error for a table name that cannot be
+// used in the query of CountRows.
+func errInvalidCountTableName(table string) error {
+	return spannerErrorf(codes.InvalidArgument, "invalid table name %q", table)
+}
+
+// errCountRowsKeySet returns error for counting the rows of a KeySet that is
+// not AllKeys.
+func errCountRowsKeySet() error {
+	return spannerErrorf(codes.InvalidArgument, "CountRows only supports AllKeys, count the rows of other key sets with a query that filters on the primary key")
+}
+
+// CountRows returns the number of rows of table that are in keys.
+//
+// The rows are counted by Spanner with a SELECT COUNT(*) query, without
+// returning the rows to the client. Only AllKeys is supported; CountRows
+// returns an InvalidArgument error for any other KeySet. CountRows uses a
+// GoogleSQL query, and is not supported for databases that use the PostgreSQL
+// dialect.
+func (t *ReadOnlyTransaction) CountRows(ctx context.Context, table string, keys KeySet) (int64, error) {
+	if table == "" || strings.Contains(table, "`") {
+		return 0, errInvalidCountTableName(table)
+	}
+	ks, err := keys.keySetProto()
+	if err != nil {
+		return 0, err
+	}
+	if !ks.All {
+		return 0, errCountRowsKeySet()
+	}
+	var count int64
+	iter := t.Query(ctx, NewStatement(fmt.Sprintf("SELECT COUNT(*) FROM %s", quoteTableName(table))))
+	err = iter.Do(func(r *Row) error {
+		return r.Column(0, &count)
+	})
+	return count, err
+}
+
+// quoteTableName returns the given table name as a quoted GoogleSQL
+// identifier. The schema of a table in a named schema is quoted separately.
+func quoteTableName(table string) string {
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = "`" + p + "`"
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+)
+
+func putSingleColumnResult(t *testing.T, server *MockedSpannerInMemTestServer, sql, col string, code sppb.TypeCode, values ...string) {
+	t.Helper()
+	rows := make([]*structpb.ListValue, len(values))
+	for i, v := range values {
+		rows[i] = &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue(v)}}
+	}
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: &sppb.ResultSetMetadata{
+				RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: col, Type: &sppb.Type{Code: code}}}},
+			},
+			Rows: rows,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCountRows_AllKeys(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	putSingleColumnResult(t, server, "SELECT COUNT(*) FROM `Singers`", "", sppb.TypeCode_INT64, "3")
+
+	count, err := client.Single().CountRows(context.Background(), "Singers", AllKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := count, int64(3); g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestCountRows_Errors(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	txn := client.ReadOnlyTransaction()
+	defer txn.Close()
+	for _, keys := range []KeySet{Key{1}, KeySets(Key{1}, Key{2}), KeyRange{Start: Key{1}, End: Key{5}, Kind: ClosedOpen}} {
+		if _, err := client.Single().CountRows(ctx, "Singers", keys); ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error code mismatch for key set %v in single-use transaction\nGot: %v\nWant: %v", keys, ErrCode(err), codes.InvalidArgument)
+		}
+		if _, err := txn.CountRows(ctx, "Singers", keys); ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error code mismatch for key set %v\nGot: %v\nWant: %v", keys, ErrCode(err), codes.InvalidArgument)
+		}
+	}
+	if _, err := client.Single().CountRows(ctx, "Sing`ers", AllKeys()); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error code mismatch for invalid table name\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+	// Unsupported key sets are rejected without reading the rows.
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req.(type) {
+		case *sppb.ReadRequest, *sppb.ExecuteSqlRequest:
+			t.Fatalf("unexpected request for an unsupported key set: %T", req)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/civil"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	proto3 "google.golang.org/protobuf/types/known/structpb"
+)
+
+// DynamicRow is a row with columns that are looked up by name, for tools
+// that do not know the schema of a result at compile time. A DynamicRow can
+// be created from any Row with NewDynamicRow, and columns can be added or
+// replaced with Set.
+//
+// The typed getters of DynamicRow, such as GetInt64 and GetString, decode the
+// value of a column in the same way as Row.ColumnByName with a destination of
+// the corresponding type. They return an error if the row does not contain the
+// column, if the column has a different type, or if the value of the column is
+// NULL. Use IsNull to check for NULL values, or Get with a destination of a
+// NullXXX type.
+//
+// A DynamicRow is not safe for concurrent use by multiple goroutines.
+type DynamicRow struct {
+	row   Row
+	index map[string]int
+}
+
+// NewDynamicRow returns a DynamicRow that contains the columns of the given
+// row. It returns an error if the row contains multiple columns with the same
+// name.
+func NewDynamicRow(r *Row) (*DynamicRow, error) {
+	d := &DynamicRow{
+		row: Row{
+			fields: append([]*sppb.StructType_Field(nil), r.fields...),
+			vals:   append([]*proto3.Value(nil), r.vals...),
+			cfg:    r.cfg,
+		},
+		index: make(map[string]int, len(r.fields)),
+	}
+	if len(d.row.vals) != len(d.row.fields) {
+		return nil, errFieldsMismatchVals(r)
+	}
+	for i, f := range d.row.fields {
+		if f == nil {
+			return nil, errNilColType(i)
+		}
+		if _, ok := d.index[f.Name]; ok {
+			return nil, errDupColName(f.Name)
+		}
+		d.index[f.Name] = i
+	}
+	return d, nil
+}
+
+// ColumnNames returns the names of the columns of the row in the order of
+// the row. Columns that are added with Set are returned after the columns of
+// the original row.
+func (d *DynamicRow) ColumnNames() []string {
+	return d.row.ColumnNames()
+}
+
+// Has reports whether the row contains a column with the given name.
+func (d *DynamicRow) Has(col string) bool {
+	_, ok := d.index[col]
+	return ok
+}
+
+// Set sets the value of the named column, adding the column to the row if
+// the row does not contain it. The value is encoded in the same way as the
+// values of a Mutation or the parameters of a Statement.
+func (d *DynamicRow) Set(col string, value interface{}) error {
+	val, typ, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	field := &sppb.StructType_Field{Name: col, Type: typ}
+	if i, ok := d.index[col]; ok {
+		d.row.fields[i] = field
+		d.row.vals[i] = val
+		return nil
+	}
+	d.index[col] = len(d.row.fields)
+	d.row.fields = append(d.row.fields, field)
+	d.row.vals = append(d.row.vals, val)
+	return nil
+}
+
+// IsNull reports whether the value of the named column is NULL. It returns
+// false if the row does not contain the column.
+func (d *DynamicRow) IsNull(col string) bool {
+	i, ok := d.index[col]
+	if !ok {
+		return false
+	}
+	_, isNull := d.row.vals[i].GetKind().(*proto3.Value_NullValue)
+	return isNull
+}
+
+// Get decodes the value of the named column into ptr. See the Row
+// documentation for the list of acceptable argument types.
+func (d *DynamicRow) Get(col string, ptr interface{}) error {
+	i, ok := d.index[col]
+	if !ok {
+		return errColNotFound(col)
+	}
+	return d.row.Column(i, ptr)
+}
+
+// GetBool returns the value of a BOOL column.
+func (d *DynamicRow) GetBool(col string) (bool, error) {
+	var v bool
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetInt64 returns the value of an INT64 column.
+func (d *DynamicRow) GetInt64(col string) (int64, error) {
+	var v int64
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetFloat64 returns the value of a FLOAT64 column.
+func (d *DynamicRow) GetFloat64(col string) (float64, error) {
+	var v float64
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetString returns the value of a STRING column.
+func (d *DynamicRow) GetString(col string) (string, error) {
+	var v string
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetBytes returns the value of a BYTES column. A NULL value is returned as
+// nil without an error.
+func (d *DynamicRow) GetBytes(col string) ([]byte, error) {
+	var v []byte
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetTime returns the value of a TIMESTAMP column.
+func (d *DynamicRow) GetTime(col string) (time.Time, error) {
+	var v time.Time
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetDate returns the value of a DATE column.
+func (d *DynamicRow) GetDate(col string) (civil.Date, error) {
+	var v civil.Date
+	err := d.Get(col, &v)
+	return v, err
+}
+
+// GetNumeric returns the value of a NUMERIC column.
+func (d *DynamicRow) GetNumeric(col string) (*big.Rat, error) {
+	v := new(big.Rat)
+	if err := d.Get(col, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDynamicRow(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	date := civil.Date{Year: 2026, Month: 1, Day: 2}
+	r, err := NewRow(
+		[]string{"Bool", "Int64", "Float64", "String", "Bytes", "Time", "Date", "Numeric", "NullString", "NullBytes"},
+		[]interface{}{true, int64(42), 1.5, "foo", []byte("bar"), ts, date, *big.NewRat(3, 4), NullString{}, []byte(nil)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDynamicRow(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := d.GetBool("Bool"); err != nil || v != true {
+		t.Errorf("GetBool = (%v, %v), want (true, nil)", v, err)
+	}
+	if v, err := d.GetInt64("Int64"); err != nil || v != 42 {
+		t.Errorf("GetInt64 = (%v, %v), want (42, nil)", v, err)
+	}
+	if v, err := d.GetFloat64("Float64"); err != nil || v != 1.5 {
+		t.Errorf("GetFloat64 = (%v, %v), want (1.5, nil)", v, err)
+	}
+	if v, err := d.GetString("String"); err != nil || v != "foo" {
+		t.Errorf("GetString = (%v, %v), want (foo, nil)", v, err)
+	}
+	if v, err := d.GetBytes("Bytes"); err != nil || string(v) != "bar" {
+		t.Errorf("GetBytes = (%v, %v), want (bar, nil)", v, err)
+	}
+	if v, err := d.GetTime("Time"); err != nil || !v.Equal(ts) {
+		t.Errorf("GetTime = (%v, %v), want (%v, nil)", v, err, ts)
+	}
+	if v, err := d.GetDate("Date"); err != nil || v != date {
+		t.Errorf("GetDate = (%v, %v), want (%v, nil)", v, err, date)
+	}
+	if v, err := d.GetNumeric("Numeric"); err != nil || v.Cmp(big.NewRat(3, 4)) != 0 {
+		t.Errorf("GetNumeric = (%v, %v), want (3/4, nil)", v, err)
+	}
+	if v, err := d.GetBytes("NullBytes"); err != nil || v != nil {
+		t.Errorf("GetBytes for NULL = (%v, %v), want (nil, nil)", v, err)
+	}
+	var ns NullString
+	if err := d.Get("NullString", &ns); err != nil || ns.Valid {
+		t.Errorf("Get for NULL = (%v, %v), want (%v, nil)", ns, err, NullString{})
+	}
+
+	// Errors for NULL values, type mismatches and missing columns.
+	if _, err := d.GetString("NullString"); err == nil {
+		t.Error("GetString for NULL value returned no error")
+	}
+	if _, err := d.GetInt64("String"); err == nil {
+		t.Error("GetInt64 for STRING column returned no error")
+	}
+	if _, err := d.GetInt64("Missing"); ErrCode(err) != codes.NotFound {
+		t.Errorf("GetInt64 for missing column error mismatch\nGot: %v\nWant: %v", err, codes.NotFound)
+	}
+}
+
+func TestDynamicRow_IsNull(t *testing.T) {
+	r, err := NewRow([]string{"A", "B"}, []interface{}{NullInt64{}, NullInt64{Int64: 1, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDynamicRow(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, want := range map[string]bool{"A": true, "B": false, "Missing": false} {
+		if got := d.IsNull(col); got != want {
+			t.Errorf("IsNull(%q) = %v, want %v", col, got, want)
+		}
+	}
+}
+
+func TestDynamicRow_Set(t *testing.T) {
+	r, err := NewRow([]string{"A"}, []interface{}{int64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDynamicRow(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("A", "replaced"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("B", NullInt64{}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := d.ColumnNames(), []string{"A", "B"}; !testEqual(g, w) {
+		t.Fatalf("column names mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if v, err := d.GetString("A"); err != nil || v != "replaced" {
+		t.Errorf("GetString(A) = (%v, %v), want (replaced, nil)", v, err)
+	}
+	if !d.Has("B") || !d.IsNull("B") {
+		t.Errorf("column B is missing or not NULL")
+	}
+	// The original row is not changed.
+	var a int64
+	if err := r.ColumnByName("A", &a); err != nil || a != 1 {
+		t.Errorf("original row changed: (%v, %v), want (1, nil)", a, err)
+	}
+
+	dup, err := NewRow([]string{"A", "A"}, []interface{}{int64(1), int64(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDynamicRow(dup); err == nil {
+		t.Error("NewDynamicRow for duplicate column names returned no error")
+	}
+
+	// The settings of the query that returned the row are kept when columns
+	// are added.
+	num, err := NewRow([]string{"N"}, []interface{}{*big.NewRat(1, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	num.cfg = &rowConfig{numericDecodeMode: NumericDecodeModeString}
+	d, err = NewDynamicRow(num)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("C", int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	var n interface{}
+	if err := d.Get("N", &n); err != nil || n != "0.500000000" {
+		t.Errorf("Get(N) = (%v, %v), want (0.500000000, nil)", n, err)
+	}
+}
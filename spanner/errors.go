@@ -108,6 +108,13 @@ func (e *Error) GRPCStatus() *status.Status {
 	}
 }
 
+// Is reports whether target is ErrAbortedRetryable and the error has code
+// Aborted. This allows errors.Is(err, ErrAbortedRetryable) to be used to
+// check whether the transaction that returned err can be retried.
+func (e *Error) Is(target error) bool {
+	return target == ErrAbortedRetryable && ErrCode(e) == codes.Aborted
+}
+
 // decorate decorates an existing spanner.Error with more information.
 func (e *Error) decorate(info string) {
 	e.Desc = fmt.Sprintf("%v, %v", info, e.Desc)
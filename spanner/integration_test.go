@@ -3888,7 +3888,7 @@ func TestIntegration_BatchQuery(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer txn.Cleanup(ctx)
-	if partitions, err = txn.PartitionQueryWithOptions(ctx, stmt, PartitionOptions{0, 3}, QueryOptions{DataBoostEnabled: true}); err != nil {
+	if partitions, err = txn.PartitionQueryWithOptions(ctx, stmt, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3}, QueryOptions{DataBoostEnabled: true}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -3971,7 +3971,7 @@ func TestIntegration_BatchRead(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer txn.Cleanup(ctx)
-	if partitions, err = txn.PartitionReadWithOptions(ctx, "test", AllKeys(), simpleDBTableColumns, PartitionOptions{0, 3}, ReadOptions{DataBoostEnabled: true}); err != nil {
+	if partitions, err = txn.PartitionReadWithOptions(ctx, "test", AllKeys(), simpleDBTableColumns, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3}, ReadOptions{DataBoostEnabled: true}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -4043,7 +4043,7 @@ func TestIntegration_BROTNormal(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer txn.Cleanup(ctx)
-	if _, err := txn.PartitionRead(ctx, "test", AllKeys(), simpleDBTableColumns, PartitionOptions{0, 3}); err != nil {
+	if _, err := txn.PartitionRead(ctx, "test", AllKeys(), simpleDBTableColumns, PartitionOptions{PartitionBytes: 0, MaxPartitions: 3}); err != nil {
 		t.Fatal(err)
 	}
 	// Normal query should work with BatchReadOnlyTransaction.
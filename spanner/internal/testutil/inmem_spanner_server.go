@@ -100,6 +100,10 @@ type StatementResult struct {
 	ResultSet    *spannerpb.ResultSet
 	UpdateCount  int64
 	ResumeTokens [][]byte
+	// ProgressUpdateCounts are the intermediate update counts that are
+	// returned as lower bound statistics before the final UpdateCount when a
+	// partitioned DML statement is executed with ExecuteStreamingSql.
+	ProgressUpdateCounts []int64
 }
 
 // PartialResultSetExecutionTime represents execution times and errors that
@@ -158,6 +162,11 @@ func (s *StatementResult) ToPartialResultSets(resumeToken []byte) (result []*spa
 			Metadata: s.ResultSet.Metadata,
 		})
 	}
+	// Return the statistics of the result set with the last partial result
+	// set.
+	if s.ResultSet.Stats != nil {
+		result[len(result)-1].Stats = s.ResultSet.Stats
+	}
 	return result, nil
 }
 
@@ -203,10 +212,11 @@ func (s *StatementResult) convertUpdateCountToResultSet(exact bool) *spannerpb.R
 
 func (s StatementResult) getResultSetWithTransactionSet(selector *spannerpb.TransactionSelector, tx []byte) *StatementResult {
 	res := &StatementResult{
-		Type:         s.Type,
-		Err:          s.Err,
-		UpdateCount:  s.UpdateCount,
-		ResumeTokens: s.ResumeTokens,
+		Type:                 s.Type,
+		Err:                  s.Err,
+		UpdateCount:          s.UpdateCount,
+		ResumeTokens:         s.ResumeTokens,
+		ProgressUpdateCounts: s.ProgressUpdateCounts,
 	}
 	if s.ResultSet != nil {
 		p, err := deepCopy(s.ResultSet)
@@ -281,6 +291,17 @@ type InMemSpannerServer interface {
 	// token.
 	PutPartitionResult(partitionToken []byte, result *StatementResult) error
 
+	// Puts mocked responses on the server for BatchWrite requests. The
+	// responses are returned instead of one successful response per mutation
+	// group. Responses without a commit timestamp and with an OK status get the
+	// current time as commit timestamp.
+	PutBatchWriteResponses(responses []*spannerpb.BatchWriteResponse)
+
+	// Sets whether the mutation count in the commit statistics is computed
+	// from the mutations in the commit request. The server returns a
+	// mutation count of 1 if this is not set.
+	SetCountCommitMutations(count bool)
+
 	// Adds a PartialResultSetExecutionTime to the server that should be returned
 	// for the specified SQL string.
 	AddPartialResultSetError(sql string, err PartialResultSetExecutionTime)
@@ -340,6 +361,11 @@ type inMemSpannerServer struct {
 	// The mocked results for this server.
 	statementResults map[string]*StatementResult
 	partitionResults map[string]*StatementResult
+	// The mocked responses for BatchWrite requests.
+	batchWriteResponses []*spannerpb.BatchWriteResponse
+	// Whether the mutation count of commit statistics is computed from the
+	// mutations in the commit request.
+	countCommitMutations bool
 	// The simulated execution times per method.
 	executionTimes map[string]*SimulatedExecutionTime
 	// The simulated errors for partial result sets
@@ -420,6 +446,19 @@ func (s *inMemSpannerServer) PutPartitionResult(partitionToken []byte, result *S
 	return nil
 }
 
+// Registers mocked responses for BatchWrite requests on the server.
+func (s *inMemSpannerServer) PutBatchWriteResponses(responses []*spannerpb.BatchWriteResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchWriteResponses = responses
+}
+
+func (s *inMemSpannerServer) SetCountCommitMutations(count bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.countCommitMutations = count
+}
+
 func (s *inMemSpannerServer) AbortTransaction(id []byte) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -925,6 +964,16 @@ func (s *inMemSpannerServer) executeStreamingSQL(req *spannerpb.ExecuteSqlReques
 				if nextPartialResultSetError.Err != nil {
 					return nextPartialResultSetError.Err
 				}
+				// A delay without an error does not end the stream. Continue
+				// with the next execution time for the statement.
+				nextPartialResultSetError = nil
+				s.mu.Lock()
+				pErrors := s.partialResultSetErrors[req.Sql]
+				if len(pErrors) > 0 {
+					nextPartialResultSetError = pErrors[0]
+					s.partialResultSetErrors[req.Sql] = pErrors[1:]
+				}
+				s.mu.Unlock()
 			}
 			if err := stream.Send(part); err != nil {
 				return err
@@ -932,6 +981,14 @@ func (s *inMemSpannerServer) executeStreamingSQL(req *spannerpb.ExecuteSqlReques
 		}
 		return nil
 	case StatementResultUpdateCount:
+		if isPartitionedDml {
+			for _, c := range statementResult.ProgressUpdateCounts {
+				progress := &StatementResult{UpdateCount: c}
+				if err := stream.Send(progress.updateCountToPartialResultSet(false)); err != nil {
+					return err
+				}
+			}
+		}
 		part := statementResult.updateCountToPartialResultSet(!isPartitionedDml)
 		if err := stream.Send(part); err != nil {
 			return err
@@ -1067,10 +1124,41 @@ func (s *inMemSpannerServer) Commit(ctx context.Context, req *spannerpb.CommitRe
 		resp.CommitStats = &spannerpb.CommitResponse_CommitStats{
 			MutationCount: int64(1),
 		}
+		s.mu.Lock()
+		count := s.countCommitMutations
+		s.mu.Unlock()
+		if count {
+			resp.CommitStats.MutationCount = countMutations(req.Mutations)
+		}
 	}
 	return resp, nil
 }
 
+// countMutations returns the number of mutations that the given mutations
+// count towards the mutation limit of a commit. A write counts as one
+// mutation per column per row, and a delete counts as one mutation.
+func countMutations(ms []*spannerpb.Mutation) int64 {
+	var n int64
+	for _, m := range ms {
+		var w *spannerpb.Mutation_Write
+		switch op := m.Operation.(type) {
+		case *spannerpb.Mutation_Insert:
+			w = op.Insert
+		case *spannerpb.Mutation_Update:
+			w = op.Update
+		case *spannerpb.Mutation_InsertOrUpdate:
+			w = op.InsertOrUpdate
+		case *spannerpb.Mutation_Replace:
+			w = op.Replace
+		case *spannerpb.Mutation_Delete_:
+			n++
+			continue
+		}
+		n += int64(len(w.GetColumns()) * len(w.GetValues()))
+	}
+	return n
+}
+
 func (s *inMemSpannerServer) Rollback(ctx context.Context, req *spannerpb.RollbackRequest) (*emptypb.Empty, error) {
 	s.mu.Lock()
 	if s.stopped {
@@ -1183,6 +1271,25 @@ func (s *inMemSpannerServer) batchWrite(req *spannerpb.BatchWriteRequest, stream
 	if len(req.GetMutationGroups()) == 0 {
 		return gstatus.Error(codes.InvalidArgument, "No mutations in Batch Write")
 	}
+	s.mu.Lock()
+	mocked := s.batchWriteResponses
+	s.mu.Unlock()
+	if mocked != nil {
+		for _, r := range mocked {
+			res := &spannerpb.BatchWriteResponse{
+				Indexes:         r.Indexes,
+				Status:          r.Status,
+				CommitTimestamp: r.CommitTimestamp,
+			}
+			if res.CommitTimestamp == nil && res.GetStatus().GetCode() == int32(codes.OK) {
+				res.CommitTimestamp = getCurrentTimestamp()
+			}
+			if err = stream.Send(res); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	// For each MutationGroup, write a BatchWriteResponse to the response stream
 	for idx := range req.GetMutationGroups() {
 		res := &spannerpb.BatchWriteResponse{
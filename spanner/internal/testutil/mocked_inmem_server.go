@@ -182,6 +182,24 @@ func (s *MockedSpannerInMemTestServer) CreateSingleRowSingersResult(rowNum int64
 	return &StatementResult{Type: StatementResultResultSet, ResultSet: resultSet}
 }
 
+// CreateKvResult creates a result set with the KvMeta columns Key and Value
+// and one row for each pair of the given keys and values, for example
+// CreateKvResult("k1", "v1", "k2", "v2").
+func (s *MockedSpannerInMemTestServer) CreateKvResult(keyValues ...string) *StatementResult {
+	rows := make([]*structpb.ListValue, 0, len(keyValues)/2)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		rows = append(rows, &structpb.ListValue{Values: []*structpb.Value{
+			structpb.NewStringValue(keyValues[i]),
+			structpb.NewStringValue(keyValues[i+1]),
+		}})
+	}
+	resultSet := &spannerpb.ResultSet{
+		Metadata: KvMeta(),
+		Rows:     rows,
+	}
+	return &StatementResult{Type: StatementResultResultSet, ResultSet: resultSet}
+}
+
 func createSingersMetadata() *spannerpb.ResultSetMetadata {
 	fields := make([]*spannerpb.StructType_Field, SelectSingerIDAlbumIDAlbumTitleFromAlbumsColCount)
 	fields[0] = &spannerpb.StructType_Field{
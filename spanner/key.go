@@ -19,7 +19,9 @@ package spanner
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"time"
 
 	"cloud.google.com/go/civil"
@@ -433,3 +435,430 @@ func (u union) keySetProto() (*sppb.KeySet, error) {
 	}
 	return upb, nil
 }
+
+// errKeyArityMismatch returns error for a key that does not have the number of
+// parts that has been registered for the primary key of a table.
+func errKeyArityMismatch(table string, key Key, want int) error {
+	return spannerErrorf(codes.InvalidArgument, "key %v has %d parts, but the primary key of table %q has %d columns", key, len(key), table, want)
+}
+
+// checkKeyArity returns an error if the number of parts of the key does not
+// match the number of primary key columns that are registered for the table
+// in arity. Keys of tables that are not registered are not checked.
+func checkKeyArity(arity map[string]int, table string, key Key) error {
+	want, ok := arity[table]
+	if !ok || len(key) == want {
+		return nil
+	}
+	return errKeyArityMismatch(table, key, want)
+}
+
+// checkKeySetArity calls checkKeyArity for each Key in the KeySet. Key ranges
+// are not checked, as the start and end of a range may be a prefix of the
+// primary key.
+func checkKeySetArity(arity map[string]int, table string, ks KeySet) error {
+	switch ks := ks.(type) {
+	case Key:
+		return checkKeyArity(arity, table, ks)
+	case union:
+		for _, k := range ks {
+			if err := checkKeySetArity(arity, table, k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// errIncomparableKeyParts returns error for two key parts that cannot be
+// compared with each other.
+func errIncomparableKeyParts(a, b interface{}) error {
+	return spannerErrorf(codes.InvalidArgument, "key parts %v (type %T) and %v (type %T) cannot be compared", a, a, b, b)
+}
+
+// comparableKeyPart converts a part of a Key into a value of one of the types
+// int64, float64, bool, string, []byte, time.Time, civil.Date or *big.Rat that
+// can be compared with compareKeyParts. It returns nil for a NULL part.
+func comparableKeyPart(part interface{}) (interface{}, error) {
+	switch v := part.(type) {
+	case nil:
+		return nil, nil
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case int64, bool, string, time.Time, civil.Date:
+		return v, nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case []byte:
+		if v == nil {
+			return nil, nil
+		}
+		return v, nil
+	case big.Rat:
+		return &v, nil
+	case protoreflect.Enum:
+		return int64(v.Number()), nil
+	case NullInt64:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Int64, nil
+	case NullFloat64:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Float64, nil
+	case NullFloat32:
+		if !v.Valid {
+			return nil, nil
+		}
+		return float64(v.Float32), nil
+	case NullBool:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Bool, nil
+	case NullString:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.StringVal, nil
+	case NullTime:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Time, nil
+	case NullDate:
+		if !v.Valid {
+			return nil, nil
+		}
+		return v.Date, nil
+	case NullNumeric:
+		if !v.Valid {
+			return nil, nil
+		}
+		return &v.Numeric, nil
+	case NullProtoEnum:
+		if !v.Valid {
+			return nil, nil
+		}
+		return int64(v.ProtoEnumVal.Number()), nil
+	case Encoder:
+		encoded, err := v.EncodeSpanner()
+		if err != nil {
+			return nil, err
+		}
+		return comparableKeyPart(encoded)
+	}
+	return nil, errInvdKeyPartType(part)
+}
+
+// compareKeyParts compares two parts of a Key in the ascending sort order of
+// Cloud Spanner, in which NULL is smaller than any other value. It returns an
+// error if the parts have different types.
+func compareKeyParts(a, b interface{}) (int, error) {
+	ca, err := comparableKeyPart(a)
+	if err != nil {
+		return 0, err
+	}
+	cb, err := comparableKeyPart(b)
+	if err != nil {
+		return 0, err
+	}
+	if ca == nil || cb == nil {
+		switch {
+		case ca == nil && cb == nil:
+			return 0, nil
+		case ca == nil:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+	switch va := ca.(type) {
+	case int64:
+		if vb, ok := cb.(int64); ok {
+			return compareOrdered(va < vb, va > vb), nil
+		}
+	case float64:
+		if vb, ok := cb.(float64); ok {
+			// NaN is smaller than any other FLOAT64 value.
+			if math.IsNaN(va) || math.IsNaN(vb) {
+				return compareOrdered(!math.IsNaN(vb), !math.IsNaN(va)), nil
+			}
+			return compareOrdered(va < vb, va > vb), nil
+		}
+	case bool:
+		if vb, ok := cb.(bool); ok {
+			return compareOrdered(!va && vb, va && !vb), nil
+		}
+	case string:
+		if vb, ok := cb.(string); ok {
+			return compareOrdered(va < vb, va > vb), nil
+		}
+	case []byte:
+		if vb, ok := cb.([]byte); ok {
+			return bytes.Compare(va, vb), nil
+		}
+	case time.Time:
+		if vb, ok := cb.(time.Time); ok {
+			return compareOrdered(va.Before(vb), va.After(vb)), nil
+		}
+	case civil.Date:
+		if vb, ok := cb.(civil.Date); ok {
+			return compareOrdered(va.Before(vb), va.After(vb)), nil
+		}
+	case *big.Rat:
+		if vb, ok := cb.(*big.Rat); ok {
+			return va.Cmp(vb), nil
+		}
+	}
+	return 0, errIncomparableKeyParts(a, b)
+}
+
+// compareOrdered returns -1 if less is true, 1 if greater is true, and 0
+// otherwise.
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// keyPosition is a position in the ascending sort order of the keys of a table
+// or index. A position is either a Key, or the position just before or just
+// after all keys that have prefix as their prefix. The latter are used for
+// the boundaries of a KeyRange.
+type keyPosition struct {
+	prefix Key
+	// side is -1 for the position before, 0 for the key itself, and 1 for
+	// the position after all keys with the prefix.
+	side int
+}
+
+// compareKeyPositions compares two positions in the sort order of keys.
+func compareKeyPositions(a, b keyPosition) (int, error) {
+	n := len(a.prefix)
+	if len(b.prefix) < n {
+		n = len(b.prefix)
+	}
+	for i := 0; i < n; i++ {
+		c, err := compareKeyParts(a.prefix[i], b.prefix[i])
+		if err != nil || c != 0 {
+			return c, err
+		}
+	}
+	switch {
+	case len(a.prefix) == len(b.prefix):
+		return compareOrdered(a.side < b.side, a.side > b.side), nil
+	case len(a.prefix) < len(b.prefix):
+		// b has a longer prefix, and is therefore between the positions before
+		// and after all keys with prefix a. A key that is a prefix of a longer
+		// key sorts before it.
+		if a.side <= 0 {
+			return -1, nil
+		}
+		return 1, nil
+	default:
+		if b.side <= 0 {
+			return 1, nil
+		}
+		return -1, nil
+	}
+}
+
+// bounds returns the positions of the start and end of the key range. The
+// keys in the range are the keys between these positions.
+func (r KeyRange) bounds() (start, end keyPosition) {
+	start = keyPosition{prefix: r.Start, side: -1}
+	if r.Kind == OpenClosed || r.Kind == OpenOpen {
+		start.side = 1
+	}
+	end = keyPosition{prefix: r.End, side: 1}
+	if r.Kind == ClosedOpen || r.Kind == OpenOpen {
+		end.side = -1
+	}
+	return start, end
+}
+
+// keyRangeFromBounds returns the KeyRange with the given start and end
+// positions.
+func keyRangeFromBounds(start, end keyPosition) KeyRange {
+	var kind KeyRangeKind
+	switch {
+	case start.side < 0 && end.side > 0:
+		kind = ClosedClosed
+	case start.side < 0:
+		kind = ClosedOpen
+	case end.side > 0:
+		kind = OpenClosed
+	default:
+		kind = OpenOpen
+	}
+	return KeyRange{Start: start.prefix, End: end.prefix, Kind: kind}
+}
+
+// contains returns true if the key range contains the key.
+func (r KeyRange) contains(k Key) (bool, error) {
+	start, end := r.bounds()
+	pos := keyPosition{prefix: k}
+	c, err := compareKeyPositions(start, pos)
+	if err != nil || c >= 0 {
+		return false, err
+	}
+	c, err = compareKeyPositions(pos, end)
+	if err != nil {
+		return false, err
+	}
+	return c < 0, nil
+}
+
+// Contains returns true if the key range contains the given key, following
+// the semantics of the Kind of the range. The Start and End keys of the range
+// may be a prefix of the key, in which case a closed boundary includes all
+// keys with that prefix, and an open boundary excludes them.
+//
+// Contains assumes that all key columns are sorted in ascending order, and
+// that NULL values sort before all other values. It returns false if a part
+// of the key cannot be compared with the corresponding part of the Start or
+// End key, for example because the parts have different types.
+func (r KeyRange) Contains(k Key) bool {
+	ok, err := r.contains(k)
+	return ok && err == nil
+}
+
+// NormalizeKeySet returns a KeySet that contains the same keys as ks, without
+// redundant keys and key ranges. Overlapping and adjacent key ranges are
+// merged, empty key ranges are removed, and keys that are contained in a key
+// range or that are specified more than once are removed. The keys and ranges
+// of the returned KeySet are sorted. If ks contains AllKeys, NormalizeKeySet
+// returns AllKeys.
+//
+// NormalizeKeySet assumes that all key columns are sorted in ascending order,
+// and should not be used for tables or indexes with descending key columns. It
+// returns an error if two keys in ks cannot be compared, for example because
+// the parts of the keys have different types.
+func NormalizeKeySet(ks KeySet) (KeySet, error) {
+	var keys []Key
+	var ranges []KeyRange
+	var isAll bool
+	var flatten func(ks KeySet)
+	flatten = func(ks KeySet) {
+		switch ks := ks.(type) {
+		case Key:
+			keys = append(keys, ks)
+		case KeyRange:
+			ranges = append(ranges, ks)
+		case all:
+			isAll = true
+		case union:
+			for _, k := range ks {
+				flatten(k)
+			}
+		}
+	}
+	flatten(ks)
+	if isAll {
+		return AllKeys(), nil
+	}
+
+	type bounds struct{ start, end keyPosition }
+	var bs []bounds
+	for _, r := range ranges {
+		start, end := r.bounds()
+		c, err := compareKeyPositions(start, end)
+		if err != nil {
+			return nil, err
+		}
+		if c < 0 {
+			bs = append(bs, bounds{start, end})
+		}
+	}
+	var err error
+	sort.SliceStable(bs, func(i, j int) bool {
+		c, cerr := compareKeyPositions(bs[i].start, bs[j].start)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	var merged []bounds
+	for _, b := range bs {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			c, err := compareKeyPositions(b.start, last.end)
+			if err != nil {
+				return nil, err
+			}
+			if c <= 0 {
+				if c, err = compareKeyPositions(b.end, last.end); err != nil {
+					return nil, err
+				}
+				if c > 0 {
+					last.end = b.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, b)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		c, cerr := compareKeyPositions(keyPosition{prefix: keys[i]}, keyPosition{prefix: keys[j]})
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := union{}
+	for i, k := range keys {
+		if i > 0 {
+			c, err := compareKeyPositions(keyPosition{prefix: keys[i-1]}, keyPosition{prefix: k})
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				continue
+			}
+		}
+		var contained bool
+		for _, b := range merged {
+			if contained, err = keyRangeFromBounds(b.start, b.end).contains(k); err != nil {
+				return nil, err
+			}
+			if contained {
+				break
+			}
+		}
+		if !contained {
+			u = append(u, k)
+		}
+	}
+	for _, b := range merged {
+		u = append(u, keyRangeFromBounds(b.start, b.end))
+	}
+	return u, nil
+}
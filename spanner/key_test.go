@@ -18,6 +18,7 @@ package spanner
 
 import (
 	"errors"
+	"math"
 	"math/big"
 	"testing"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"cloud.google.com/go/civil"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	pb "cloud.google.com/go/spanner/testdata/protos"
+	"google.golang.org/grpc/codes"
 	proto3 "google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -507,3 +509,98 @@ func TestKeySets(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyRangeContains(t *testing.T) {
+	for _, test := range []struct {
+		kr   KeyRange
+		key  Key
+		want bool
+	}{
+		{KeyRange{Key{1}, Key{3}, ClosedClosed}, Key{1}, true},
+		{KeyRange{Key{1}, Key{3}, ClosedClosed}, Key{3}, true},
+		{KeyRange{Key{1}, Key{3}, ClosedClosed}, Key{4}, false},
+		{KeyRange{Key{1}, Key{3}, ClosedOpen}, Key{1}, true},
+		{KeyRange{Key{1}, Key{3}, ClosedOpen}, Key{3}, false},
+		{KeyRange{Key{1}, Key{3}, OpenClosed}, Key{1}, false},
+		{KeyRange{Key{1}, Key{3}, OpenClosed}, Key{3}, true},
+		{KeyRange{Key{1}, Key{3}, OpenOpen}, Key{2}, true},
+		{KeyRange{Key{1}, Key{3}, OpenOpen}, Key{3}, false},
+		// A prefix boundary includes or excludes all keys with that prefix.
+		{KeyRange{Key{1}, Key{3}, ClosedClosed}, Key{3, "z"}, true},
+		{KeyRange{Key{1}, Key{3}, ClosedOpen}, Key{3, "a"}, false},
+		{KeyRange{Key{1}, Key{3}, OpenClosed}, Key{1, "z"}, false},
+		{Key{2}.AsPrefix(), Key{2, "a", 1}, true},
+		{Key{2}.AsPrefix(), Key{3}, false},
+		{KeyRange{Key{}, Key{}, ClosedClosed}, Key{"any"}, true},
+		// NULL sorts before all other values.
+		{KeyRange{Key{nil}, Key{0}, ClosedClosed}, Key{NullInt64{}}, true},
+		{KeyRange{Key{0}, Key{10}, ClosedClosed}, Key{nil}, false},
+		// Different integer types, Null types and custom types are compared
+		// by value.
+		{KeyRange{Key{int32(1)}, Key{NullInt64{Int64: 3, Valid: true}}, ClosedClosed}, Key{customKeyToInt(2)}, true},
+		{KeyRange{Key{"a"}, Key{"c"}, ClosedOpen}, Key{"b"}, true},
+		{KeyRange{Key{[]byte("a")}, Key{[]byte("c")}, ClosedOpen}, Key{[]byte("c")}, false},
+		{KeyRange{Key{civil.Date{Year: 2024, Month: 1, Day: 1}}, Key{civil.Date{Year: 2024, Month: 12, Day: 31}}, ClosedClosed}, Key{civil.Date{Year: 2024, Month: 6, Day: 1}}, true},
+		{KeyRange{Key{math.NaN()}, Key{0.0}, ClosedClosed}, Key{-1.5}, true},
+		// Keys that cannot be compared are not contained.
+		{KeyRange{Key{1}, Key{3}, ClosedClosed}, Key{"2"}, false},
+	} {
+		if got := test.kr.Contains(test.key); got != test.want {
+			t.Errorf("%v.Contains(%v) mismatch\nGot: %v\nWant: %v", test.kr, test.key, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeKeySet(t *testing.T) {
+	for i, test := range []struct {
+		ks   KeySet
+		want KeySet
+	}{
+		{KeySets(), union{}},
+		{KeySets(Key{1}, AllKeys()), AllKeys()},
+		{
+			KeySets(Key{3}, Key{1}, Key{3}),
+			union{Key{1}, Key{3}},
+		},
+		{
+			KeySets(
+				KeyRange{Key{5}, Key{8}, ClosedOpen},
+				KeyRange{Key{1}, Key{4}, ClosedClosed},
+				KeyRange{Key{3}, Key{6}, OpenOpen},
+			),
+			union{KeyRange{Key{1}, Key{8}, ClosedOpen}},
+		},
+		{
+			// Adjacent ranges are merged, ranges with a gap are not.
+			KeySets(
+				KeyRange{Key{1}, Key{2}, ClosedOpen},
+				KeyRange{Key{2}, Key{3}, ClosedClosed},
+				KeyRange{Key{3}, Key{4}, OpenClosed},
+				KeyRange{Key{5}, Key{6}, OpenOpen},
+			),
+			union{KeyRange{Key{1}, Key{4}, ClosedClosed}, KeyRange{Key{5}, Key{6}, OpenOpen}},
+		},
+		{
+			// A prefix range contains all keys with that prefix.
+			KeySets(Key{2, "a"}, Key{2}.AsPrefix(), KeyRange{Key{2, "b"}, Key{3}, ClosedOpen}, Key{3, "a"}),
+			union{Key{3, "a"}, KeyRange{Key{2}, Key{3}, ClosedOpen}},
+		},
+		{
+			// Empty ranges are removed.
+			KeySets(KeyRange{Key{2}, Key{1}, ClosedClosed}, KeyRange{Key{1}, Key{1}, ClosedOpen}, Key{1}),
+			union{Key{1}},
+		},
+	} {
+		got, err := NormalizeKeySet(test.ks)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("%d: NormalizeKeySet mismatch\nGot: %v\nWant: %v", i, got, test.want)
+		}
+	}
+
+	if _, err := NormalizeKeySet(KeySets(Key{1}, Key{"1"})); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+}
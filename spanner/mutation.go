@@ -17,7 +17,9 @@ limitations under the License.
 package spanner
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"google.golang.org/grpc/codes"
@@ -111,6 +113,8 @@ const (
 //	[][]byte - BYTES ARRAY
 //	int, int64, *int64, NullInt64 - INT64
 //	[]int, []int64, []*int64, []NullInt64 - INT64 ARRAY
+//	time.Duration, NullDuration - INT64, as nanoseconds
+//	[]time.Duration, []NullDuration - INT64 ARRAY, as nanoseconds
 //	bool, *bool, NullBool - BOOL
 //	[]bool, []*bool, []NullBool - BOOL ARRAY
 //	float64, *float64, NullFloat64 - FLOAT64
@@ -119,7 +123,7 @@ const (
 //	[]time.Time, []*time.Time, []NullTime - TIMESTAMP ARRAY
 //	Date, *Date, NullDate - DATE
 //	[]Date, []*Date, []NullDate - DATE ARRAY
-//	big.Rat, *big.Rat, NullNumeric - NUMERIC
+//	big.Rat, *big.Rat, NullNumeric, *big.Int, Numeric - NUMERIC
 //	[]big.Rat, []*big.Rat, []NullNumeric - NUMERIC ARRAY
 //
 // To compare two Mutations for testing purposes, use reflect.DeepEqual.
@@ -191,7 +195,11 @@ func structToMutationParams(in interface{}) ([]string, []interface{}, error) {
 	}
 	var cols []string
 	var vals []interface{}
-	for _, f := range fields {
+	for i := range fields {
+		f := &fields[i]
+		if isRemainderField(f) {
+			continue
+		}
 		cols = append(cols, f.Name)
 		vals = append(vals, v.FieldByIndex(f.Index).Interface())
 	}
@@ -354,6 +362,105 @@ func Delete(table string, ks KeySet) *Mutation {
 	}
 }
 
+// MutationBuilder builds an Insert, Update, InsertOrUpdate or Replace
+// Mutation one column at a time, which guarantees that each column is paired
+// with its value. Create a MutationBuilder with NewInsert, NewUpdate,
+// NewInsertOrUpdate or NewReplace:
+//
+//	m, err := spanner.NewInsert("User").
+//		Set("user_id", UserID).
+//		Set("profile", profile).
+//		Build()
+//
+// The Mutation that is returned by Build is the same as the Mutation that is
+// returned by the corresponding function, for example Insert, with the columns
+// in the order in which they were set.
+type MutationBuilder struct {
+	op      op
+	table   string
+	columns []string
+	values  []interface{}
+	// seen contains the lower case names of the columns that have been set.
+	seen map[string]bool
+	// err is the first error that occurred while setting the columns.
+	err error
+}
+
+// NewInsert returns a MutationBuilder for a Mutation to insert a row into a
+// table. See Insert.
+func NewInsert(table string) *MutationBuilder {
+	return &MutationBuilder{op: opInsert, table: table}
+}
+
+// NewUpdate returns a MutationBuilder for a Mutation to update a row in a
+// table. See Update.
+func NewUpdate(table string) *MutationBuilder {
+	return &MutationBuilder{op: opUpdate, table: table}
+}
+
+// NewInsertOrUpdate returns a MutationBuilder for a Mutation to insert a row
+// into a table, or to update it if it already exists. See InsertOrUpdate.
+func NewInsertOrUpdate(table string) *MutationBuilder {
+	return &MutationBuilder{op: opInsertOrUpdate, table: table}
+}
+
+// NewReplace returns a MutationBuilder for a Mutation to insert a row into a
+// table, deleting any existing row. See Replace.
+func NewReplace(table string) *MutationBuilder {
+	return &MutationBuilder{op: opReplace, table: table}
+}
+
+// errDupMutationColumn returns error for setting a column of a
+// MutationBuilder more than once.
+func errDupMutationColumn(table, col string) error {
+	return spannerErrorf(codes.InvalidArgument, "column %q of table %q is set more than once", col, table)
+}
+
+// errEmptyMutationColumn returns error for setting a column with an empty
+// name in a MutationBuilder.
+func errEmptyMutationColumn(table string) error {
+	return spannerErrorf(codes.InvalidArgument, "column name for table %q must not be empty", table)
+}
+
+// Set sets the value of a column. Column names are case-insensitive, and
+// setting the same column more than once makes Build return an error. The
+// valid Go types of val are the same as for Insert.
+func (b *MutationBuilder) Set(column string, val interface{}) *MutationBuilder {
+	if b.err != nil {
+		return b
+	}
+	if column == "" {
+		b.err = errEmptyMutationColumn(b.table)
+		return b
+	}
+	key := strings.ToLower(column)
+	if b.seen[key] {
+		b.err = errDupMutationColumn(b.table, column)
+		return b
+	}
+	if b.seen == nil {
+		b.seen = make(map[string]bool)
+	}
+	b.seen[key] = true
+	b.columns = append(b.columns, column)
+	b.values = append(b.values, val)
+	return b
+}
+
+// Build returns the Mutation, or the first error that occurred while setting
+// the columns of the Mutation.
+func (b *MutationBuilder) Build() (*Mutation, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &Mutation{
+		op:      b.op,
+		table:   b.table,
+		columns: append([]string(nil), b.columns...),
+		values:  append([]interface{}(nil), b.values...),
+	}, nil
+}
+
 // prepareWrite generates sppb.Mutation_Write from table name, column names
 // and new column values.
 func prepareWrite(table string, columns []string, vals []interface{}) (*sppb.Mutation_Write, error) {
@@ -425,6 +532,25 @@ func (m Mutation) proto() (*sppb.Mutation, error) {
 	return pb, nil
 }
 
+// mutationCount returns the number of mutations that m counts towards the
+// mutation limit of a commit, without the mutations of secondary indexes.
+func (m *Mutation) mutationCount() int64 {
+	if m.op == opDelete {
+		return 1
+	}
+	return int64(len(m.columns))
+}
+
+// mutationsCount returns the number of mutations that ms count towards the
+// mutation limit of a commit, without the mutations of secondary indexes.
+func mutationsCount(ms []*Mutation) int64 {
+	var n int64
+	for _, m := range ms {
+		n += m.mutationCount()
+	}
+	return n
+}
+
 // mutationsProto turns a spanner.Mutation array into a sppb.Mutation array,
 // it is convenient for sending batch mutations to Cloud Spanner.
 func mutationsProto(ms []*Mutation) ([]*sppb.Mutation, error) {
@@ -439,6 +565,135 @@ func mutationsProto(ms []*Mutation) ([]*sppb.Mutation, error) {
 	return l, nil
 }
 
+// checkZeroTime returns an error if any of the values that are written by
+// the given mutations contains a zero time.Time value.
+func checkZeroTime(ms []*Mutation) error {
+	for _, m := range ms {
+		for i, v := range m.values {
+			if containsZeroTime(v) {
+				var col string
+				if i < len(m.columns) {
+					col = m.columns[i]
+				}
+				return errZeroTime(fmt.Sprintf("value of column %q of table %q", col, m.table))
+			}
+		}
+	}
+	return nil
+}
+
+// emptyStringsAsNull returns the given mutations with the values that are
+// empty strings replaced by NULL values. Mutations that contain empty strings
+// are copied, and the other mutations are returned as is.
+func emptyStringsAsNull(ms []*Mutation) []*Mutation {
+	var res []*Mutation
+	for i, m := range ms {
+		var values []interface{}
+		for j, v := range m.values {
+			nv, ok := emptyStringAsNull(v)
+			if !ok {
+				continue
+			}
+			if values == nil {
+				values = append([]interface{}(nil), m.values...)
+			}
+			values[j] = nv
+		}
+		if values == nil {
+			continue
+		}
+		if res == nil {
+			res = append([]*Mutation(nil), ms...)
+		}
+		cp := *m
+		cp.values = values
+		res[i] = &cp
+	}
+	if res == nil {
+		return ms
+	}
+	return res
+}
+
+// emptyStringsAsNullInGroups returns the given mutation groups with the
+// values that are empty strings replaced by NULL values.
+func emptyStringsAsNullInGroups(mgs []*MutationGroup) []*MutationGroup {
+	res := make([]*MutationGroup, len(mgs))
+	for i, mg := range mgs {
+		res[i] = &MutationGroup{Mutations: emptyStringsAsNull(mg.Mutations)}
+	}
+	return res
+}
+
+// checkMutationKeyArity returns an error if a Delete mutation in ms contains
+// a key that does not match the number of primary key columns that are
+// registered for the table of the mutation in arity.
+func checkMutationKeyArity(arity map[string]int, ms []*Mutation) error {
+	for _, m := range ms {
+		if m.op != opDelete {
+			continue
+		}
+		if err := checkKeySetArity(arity, m.table, m.keySet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errMutationColumnType returns error for a mutation that writes a value of
+// the wrong type to a column.
+func errMutationColumnType(table, col string, got, want sppb.TypeCode) error {
+	return spannerErrorf(codes.InvalidArgument, "value of column %q of table %q has type %v, want %v", col, table, got, want)
+}
+
+// ValidateMutations checks the values that are written by ms against the
+// column types in schema, and returns an error for the first value that has
+// a different type than its column. schema maps table names to the types of
+// the columns of the table. Table and column names must match exactly.
+//
+// Tables and columns that are not in schema are not checked, and neither are
+// Delete mutations. Untyped nil values are accepted for all columns. The
+// check only compares the type codes of the values with those of the columns,
+// so for example the element type of an ARRAY value is not checked.
+//
+// ValidateMutations can be used to catch values of the wrong type before the
+// mutations are sent to Cloud Spanner, which also checks the types.
+func ValidateMutations(ms []*Mutation, schema map[string]map[string]sppb.TypeCode) error {
+	for _, m := range ms {
+		if m == nil || m.op == opDelete {
+			continue
+		}
+		cols, ok := schema[m.table]
+		if !ok {
+			continue
+		}
+		for i, v := range m.values {
+			if i >= len(m.columns) {
+				break
+			}
+			want, ok := cols[m.columns[i]]
+			if !ok {
+				continue
+			}
+			if !isSupportedMutationType(v) {
+				return errEncoderUnsupportedType(v)
+			}
+			_, t, err := encodeValue(v)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				// Untyped NULL value.
+				continue
+			}
+			if t.Code != want {
+				return errMutationColumnType(m.table, m.columns[i], t.Code, want)
+			}
+		}
+	}
+	return nil
+}
+
 // mutationGroupsProto turns a spanner.MutationGroup array into a
 // sppb.BatchWriteRequest_MutationGroup array, in preparation to send RPCs.
 func mutationGroupsProto(mgs []*MutationGroup) ([]*sppb.BatchWriteRequest_MutationGroup, error) {
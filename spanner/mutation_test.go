@@ -25,6 +25,7 @@ import (
 
 	"cloud.google.com/go/civil"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
 	proto3 "google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -378,6 +379,69 @@ func TestBadStructs(t *testing.T) {
 	}
 }
 
+// Test building mutations with MutationBuilder.
+func TestMutationBuilder(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		b    *MutationBuilder
+		want *Mutation
+	}{
+		{
+			"NewInsert",
+			NewInsert("t_foo").Set("col1", int64(1)).Set("col2", "a"),
+			Insert("t_foo", []string{"col1", "col2"}, []interface{}{int64(1), "a"}),
+		},
+		{
+			"NewUpdate",
+			NewUpdate("t_foo").Set("col1", int64(1)).Set("col2", nil),
+			Update("t_foo", []string{"col1", "col2"}, []interface{}{int64(1), nil}),
+		},
+		{
+			"NewInsertOrUpdate",
+			NewInsertOrUpdate("t_foo").Set("col1", int64(1)).Set("col2", 2.5),
+			InsertOrUpdate("t_foo", []string{"col1", "col2"}, []interface{}{int64(1), 2.5}),
+		},
+		{
+			"NewReplace",
+			NewReplace("t_foo").Set("col1", int64(1)),
+			Replace("t_foo", []string{"col1"}, []interface{}{int64(1)}),
+		},
+	} {
+		got, err := test.b.Build()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("%s: mutation mismatch\nGot: %v\nWant: %v", test.name, got, test.want)
+		}
+		gotProto, err := got.proto()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		wantProto, err := test.want.proto()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !testEqual(gotProto, wantProto) {
+			t.Errorf("%s: proto mismatch\nGot: %v\nWant: %v", test.name, gotProto, wantProto)
+		}
+	}
+
+	for _, test := range []struct {
+		name string
+		b    *MutationBuilder
+	}{
+		{"duplicate column", NewInsert("t_foo").Set("col1", int64(1)).Set("col2", int64(2)).Set("col1", int64(3))},
+		{"duplicate column with different case", NewUpdate("t_foo").Set("Col1", int64(1)).Set("COL1", int64(2))},
+		{"empty column name", NewInsertOrUpdate("t_foo").Set("", int64(1))},
+	} {
+		_, err := test.b.Build()
+		if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+			t.Errorf("%s: error code mismatch\nGot: %v\nWant: %v", test.name, g, w)
+		}
+	}
+}
+
 func TestStructToMutationParams(t *testing.T) {
 	// Tests cases not covered elsewhere.
 	type S struct{ F interface{} }
@@ -725,3 +789,59 @@ func TestEncodeMutationGroupArray(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMutations(t *testing.T) {
+	schema := map[string]map[string]sppb.TypeCode{
+		"Singers": {
+			"SingerId":  sppb.TypeCode_INT64,
+			"FirstName": sppb.TypeCode_STRING,
+			"Birthday":  sppb.TypeCode_DATE,
+			"Tags":      sppb.TypeCode_ARRAY,
+		},
+	}
+	for _, test := range []struct {
+		name    string
+		ms      []*Mutation
+		wantErr error
+	}{
+		{
+			"Valid Mutations",
+			[]*Mutation{
+				Insert("Singers", []string{"SingerId", "FirstName", "Birthday", "Tags"}, []interface{}{1, "Alice", civil.Date{Year: 1980, Month: 1, Day: 1}, []string{"a"}}),
+				Update("Singers", []string{"SingerId", "FirstName"}, []interface{}{NullInt64{Int64: 2, Valid: true}, NullString{}}),
+				InsertOrUpdate("Singers", []string{"SingerId", "Birthday"}, []interface{}{3, nil}),
+				Delete("Singers", Key{"not-an-int"}),
+			},
+			nil,
+		},
+		{
+			"Unknown Tables And Columns",
+			[]*Mutation{
+				Insert("Albums", []string{"AlbumId"}, []interface{}{"foo"}),
+				Insert("Singers", []string{"SingerId", "LastName"}, []interface{}{1, 2}),
+			},
+			nil,
+		},
+		{
+			"Type Mismatch",
+			[]*Mutation{
+				Insert("Singers", []string{"SingerId", "FirstName"}, []interface{}{1, "Alice"}),
+				Insert("Singers", []string{"SingerId", "FirstName"}, []interface{}{"2", "Bob"}),
+				Insert("Singers", []string{"SingerId", "FirstName"}, []interface{}{3, 4}),
+			},
+			errMutationColumnType("Singers", "SingerId", sppb.TypeCode_STRING, sppb.TypeCode_INT64),
+		},
+		{
+			"Unsupported Type",
+			[]*Mutation{
+				Insert("Singers", []string{"SingerId"}, []interface{}{struct{}{}}),
+			},
+			errEncoderUnsupportedType(struct{}{}),
+		},
+	} {
+		gotErr := ValidateMutations(test.ms, schema)
+		if !testEqual(gotErr, test.wantErr) {
+			t.Errorf("%v: ValidateMutations returns error %v, want %v", test.name, gotErr, test.wantErr)
+		}
+	}
+}
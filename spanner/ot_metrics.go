@@ -158,6 +158,18 @@ func initializeMetricInstruments(config *openTelemetryConfig, logger *log.Logger
 	}
 	config.releasedSessionsCount = releasedSessionsCountInstrument
 
+	sessionPoolWaitTimeInstrument, err := meter.Float64Histogram(
+		metricsPrefix+"session_pool_wait_time",
+		metric.WithDescription("The time that requests for a session waited for a session to become available in the session pool."),
+		metric.WithUnit("ms"),
+		metric.WithExplicitBucketBoundaries(0.0, 0.5, 1.0, 2.0, 5.0, 10.0, 20.0, 50.0, 100.0, 200.0, 500.0, 1000.0,
+			2000.0, 5000.0, 10000.0, 20000.0, 50000.0, 100000.0),
+	)
+	if err != nil {
+		logf(logger, "Error during registering instrument for metric spanner/session_pool_wait_time, error: %v", err)
+	}
+	config.sessionPoolWaitTime = sessionPoolWaitTimeInstrument
+
 	gfeLatencyInstrument, err := meter.Int64Histogram(
 		metricsPrefix+"gfe_latency",
 		metric.WithDescription("Latency between Google's network receiving an RPC and reading back the first byte of the response"),
@@ -16,6 +16,7 @@ package spanner
 
 import (
 	"context"
+	"io"
 
 	"cloud.google.com/go/internal/trace"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
@@ -35,24 +36,40 @@ import (
 // PartitionedUpdate returns an estimated count of the number of rows affected.
 // The actual number of affected rows may be greater than the estimate.
 func (c *Client) PartitionedUpdate(ctx context.Context, statement Statement) (count int64, err error) {
-	return c.partitionedUpdate(ctx, statement, c.qo)
+	return c.partitionedUpdate(ctx, statement, c.qo, nil)
 }
 
 // PartitionedUpdateWithOptions executes a DML statement in parallel across the database,
 // using separate, internal transactions that commit independently. The sql
 // query execution will be optimized based on the given query options.
 func (c *Client) PartitionedUpdateWithOptions(ctx context.Context, statement Statement, opts QueryOptions) (count int64, err error) {
-	return c.partitionedUpdate(ctx, statement, c.qo.merge(opts))
+	return c.partitionedUpdate(ctx, statement, c.qo.merge(opts), nil)
 }
 
-func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, options QueryOptions) (count int64, err error) {
+// PartitionedUpdateWithProgress is like PartitionedUpdateWithOptions, but
+// executes the statement as a streaming call and calls onProgress with the
+// number of rows that have been modified so far each time Cloud Spanner
+// returns statistics for the statement. If Cloud Spanner only returns the
+// final statistics, onProgress is called once with the final count. The
+// counts start again from zero if the statement is retried after the
+// transaction was aborted.
+func (c *Client) PartitionedUpdateWithProgress(ctx context.Context, statement Statement, opts QueryOptions, onProgress func(rowsSoFar int64)) (count int64, err error) {
+	return c.partitionedUpdate(ctx, statement, c.qo.merge(opts), onProgress)
+}
+
+// partitionedUpdate executes a partitioned DML statement. onProgress may be
+// nil.
+func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, options QueryOptions, onProgress func(rowsSoFar int64)) (count int64, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.PartitionedUpdate")
 	defer func() { trace.EndSpan(ctx, err) }()
 	if err := checkNestedTxn(ctx); err != nil {
 		return 0, err
 	}
+	if err := c.statementFilter.check(statement.SQL); err != nil {
+		return 0, err
+	}
 
-	sh, err := c.idleSessions.take(ctx)
+	sh, err := c.idleSessions.takeFor(ctx, "PartitionedUpdate")
 	if err != nil {
 		return 0, ToSpannerError(err)
 	}
@@ -66,6 +83,14 @@ func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, opt
 
 	// Create the parameters and the SQL request, but without a transaction.
 	// The transaction reference will be added by the executePdml method.
+	if c.emptyStringAsNull {
+		statement = statement.emptyStringsAsNull()
+	}
+	if c.rejectZeroTime {
+		if err := statement.checkZeroTime(); err != nil {
+			return 0, err
+		}
+	}
 	params, paramTypes, err := statement.convertParams()
 	if err != nil {
 		return 0, ToSpannerError(err)
@@ -80,11 +105,11 @@ func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, opt
 	}
 
 	// Make a retryer for Aborted and certain Internal errors.
-	retryer := onCodes(DefaultRetryBackoff, codes.Aborted, codes.Internal)
+	retryer := newRetryer(c.retryClassifier, RetryOperationTransaction, DefaultRetryBackoff)
 	// Execute the PDML and retry if the transaction is aborted.
 	executePdmlWithRetry := func(ctx context.Context) (int64, error) {
 		for {
-			count, err := executePdml(contextWithOutgoingMetadata(ctx, sh.getMetadata(), c.disableRouteToLeader), sh, req, options)
+			count, err := executePdml(contextWithOutgoingMetadata(ctx, sh.getMetadata(), c.disableRouteToLeader), sh, req, options, onProgress)
 			if err == nil {
 				return count, nil
 			}
@@ -106,7 +131,7 @@ func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, opt
 // 3. Execute the update statement on the PDML transaction
 //
 // Note that PDML transactions cannot be committed or rolled back.
-func executePdml(ctx context.Context, sh *sessionHandle, req *sppb.ExecuteSqlRequest, options QueryOptions) (count int64, err error) {
+func executePdml(ctx context.Context, sh *sessionHandle, req *sppb.ExecuteSqlRequest, options QueryOptions, onProgress func(rowsSoFar int64)) (count int64, err error) {
 	var md metadata.MD
 	sh.updateLastUseTime()
 	// Begin transaction.
@@ -126,6 +151,9 @@ func executePdml(ctx context.Context, sh *sessionHandle, req *sppb.ExecuteSqlReq
 	}
 
 	sh.updateLastUseTime()
+	if onProgress != nil {
+		return executeStreamingPdml(ctx, sh, req, onProgress)
+	}
 	resultSet, err := sh.getClient().ExecuteSql(ctx, req, gax.WithGRPCOptions(grpc.Header(&md)))
 	if getGFELatencyMetricsFlag() && md != nil && sh.session.pool != nil {
 		err := captureGFELatencyStats(tag.NewContext(ctx, sh.session.pool.tagMap), md, "executePdml_ExecuteSql")
@@ -145,3 +173,50 @@ func executePdml(ctx context.Context, sh *sessionHandle, req *sppb.ExecuteSqlReq
 	}
 	return extractRowCount(resultSet.Stats)
 }
+
+// executeStreamingPdml executes a partitioned DML statement in the given
+// transaction with ExecuteStreamingSql and calls onProgress for each
+// PartialResultSet that contains statistics. It returns the row count of the
+// last statistics that were returned.
+func executeStreamingPdml(ctx context.Context, sh *sessionHandle, req *sppb.ExecuteSqlRequest, onProgress func(rowsSoFar int64)) (count int64, err error) {
+	stream, err := sh.getClient().ExecuteStreamingSql(ctx, req)
+	if err != nil {
+		return 0, ToSpannerError(err)
+	}
+	md, headerErr := stream.Header()
+	if headerErr == nil && sh.session.pool != nil {
+		if getGFELatencyMetricsFlag() && md != nil {
+			err := captureGFELatencyStats(tag.NewContext(ctx, sh.session.pool.tagMap), md, "executePdml_ExecuteStreamingSql")
+			if err != nil {
+				trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
+			}
+		}
+		if metricErr := recordGFELatencyMetricsOT(ctx, md, "executePdml_ExecuteStreamingSql", sh.session.pool.otConfig); metricErr != nil {
+			trace.TracePrintf(ctx, nil, "Error in recording GFE Latency through OpenTelemetry. Error: %v", metricErr)
+		}
+	}
+	var hasStats bool
+	for {
+		prs, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, ToSpannerError(err)
+		}
+		if prs.Stats == nil {
+			continue
+		}
+		rowCount, err := extractRowCount(prs.Stats)
+		if err != nil {
+			return 0, err
+		}
+		hasStats = true
+		count = rowCount
+		onProgress(count)
+	}
+	if !hasStats {
+		return 0, spannerErrorf(codes.InvalidArgument, "query passed to Update: %q", req.Sql)
+	}
+	return count, nil
+}
@@ -201,3 +201,70 @@ func TestPartitionedUpdate_ExcludeTxnFromChangeStreams(t *testing.T) {
 		t.Fatal("Transaction is not set to be excluded from change streams")
 	}
 }
+
+func TestPartitionedUpdate_OnProgress(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	sql := "UPDATE Singers SET Active=TRUE WHERE TRUE"
+	server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type:                 StatementResultUpdateCount,
+		UpdateCount:          100,
+		ProgressUpdateCounts: []int64{10, 40, 75},
+	})
+	var progress []int64
+	count, err := client.PartitionedUpdateWithProgress(ctx, NewStatement(sql), QueryOptions{}, func(rowsSoFar int64) {
+		progress = append(progress, rowsSoFar)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := count, int64(100); g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := progress, []int64{10, 40, 75, 100}; !testEqual(g, w) {
+		t.Fatalf("progress mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	if err := compareRequests([]interface{}{
+		&sppb.BatchCreateSessionsRequest{},
+		&sppb.BeginTransactionRequest{},
+		&sppb.ExecuteSqlRequest{}}, requests); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPartitionedUpdate_OnProgressWithoutProgressStats(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	var progress []int64
+	count, err := client.PartitionedUpdateWithProgress(ctx, NewStatement(UpdateBarSetFoo), QueryOptions{}, func(rowsSoFar int64) {
+		progress = append(progress, rowsSoFar)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := count, int64(UpdateBarSetFooRowCount); g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := progress, []int64{UpdateBarSetFooRowCount}; !testEqual(g, w) {
+		t.Fatalf("progress mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestPartitionedUpdate_OnProgressWithQuery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	_, err := client.PartitionedUpdateWithProgress(ctx, NewStatement(SelectFooFromBar), QueryOptions{}, func(rowsSoFar int64) {})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
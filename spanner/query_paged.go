@@ -0,0 +1,156 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+)
+
+// pageKeyParam is the name of the query parameter that contains the key of
+// the last row of the previous page for keyset pagination.
+const pageKeyParam = "_page_key"
+
+// PageIterator fetches the results of a query one page at a time. A
+// PageIterator is returned by ReadOnlyTransaction.QueryPaged.
+//
+// Each page is fetched with a separate query. By default, the pages are
+// fetched by appending a LIMIT and OFFSET clause to the query. The base query
+// must therefore not contain a LIMIT or OFFSET clause itself, and should
+// contain an ORDER BY clause that gives the rows a stable order, as Spanner
+// does not otherwise guarantee that the rows are returned in the same order by
+// each query.
+//
+// Note that OFFSET does not skip rows efficiently: Spanner still computes and
+// discards all rows before the offset, so the cost of fetching a page
+// increases with the page number. Use WithKeyColumn to page through large
+// results with keyset pagination instead.
+//
+// A PageIterator is not safe for concurrent use by multiple goroutines.
+type PageIterator struct {
+	ctx       context.Context
+	txn       *ReadOnlyTransaction
+	stmt      Statement
+	pageSize  int64
+	keyColumn string
+
+	offset  int64
+	lastKey *GenericColumnValue
+	done    bool
+}
+
+// errInvalidPageSize returns error for a paged query with a page size that is
+// not positive.
+func errInvalidPageSize(pageSize int64) error {
+	return spannerErrorf(codes.InvalidArgument, "page size must be positive, got %d", pageSize)
+}
+
+// QueryPaged returns a PageIterator that executes the given query in pages of
+// at most pageSize rows. The pages are fetched on demand by calling NextPage.
+//
+// All pages are read with this transaction. Use a multi-use transaction that
+// is created with Client.ReadOnlyTransaction, so that all pages are read at
+// the same timestamp. A single-use transaction only allows one query to be
+// executed, and can therefore only return the first page.
+func (t *ReadOnlyTransaction) QueryPaged(ctx context.Context, statement Statement, pageSize int64) *PageIterator {
+	return &PageIterator{
+		ctx:      ctx,
+		txn:      t,
+		stmt:     statement,
+		pageSize: pageSize,
+	}
+}
+
+// WithKeyColumn sets a column that is used for keyset pagination instead of
+// LIMIT and OFFSET. The values of the column must be unique in the results of
+// the query. Each page is then fetched by selecting the rows of the base query
+// with a key that is larger than the key of the last row of the previous
+// page, ordered by the key column:
+//
+//	SELECT * FROM (<query>) WHERE <key> > @_page_key ORDER BY <key> LIMIT <n>
+//
+// The key column must be included in the columns that are returned by the
+// query, and the query must not use a parameter with the name _page_key.
+// WithKeyColumn must be called before the first call to NextPage.
+func (p *PageIterator) WithKeyColumn(column string) *PageIterator {
+	p.keyColumn = column
+	return p
+}
+
+// NextPage returns the rows of the next page. It returns iterator.Done if all
+// rows of the query have been returned. Only the last page can contain fewer
+// than pageSize rows.
+func (p *PageIterator) NextPage() ([]*Row, error) {
+	if p.done {
+		return nil, iterator.Done
+	}
+	if p.pageSize <= 0 {
+		return nil, errInvalidPageSize(p.pageSize)
+	}
+	var rows []*Row
+	err := p.txn.Query(p.ctx, p.pageStatement()).Do(func(r *Row) error {
+		rows = append(rows, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(rows)) < p.pageSize {
+		p.done = true
+	}
+	if len(rows) == 0 {
+		return nil, iterator.Done
+	}
+	if p.keyColumn != "" {
+		var key GenericColumnValue
+		if err := rows[len(rows)-1].ColumnByName(p.keyColumn, &key); err != nil {
+			return nil, err
+		}
+		p.lastKey = &key
+	} else {
+		p.offset += int64(len(rows))
+	}
+	return rows, nil
+}
+
+// pageStatement returns the statement that fetches the next page.
+func (p *PageIterator) pageStatement() Statement {
+	if p.keyColumn == "" {
+		return Statement{
+			SQL:    fmt.Sprintf("%s LIMIT %d OFFSET %d", p.stmt.SQL, p.pageSize, p.offset),
+			Params: p.stmt.Params,
+		}
+	}
+	if p.lastKey == nil {
+		return Statement{
+			SQL:    fmt.Sprintf("SELECT * FROM (%s) ORDER BY %s LIMIT %d", p.stmt.SQL, p.keyColumn, p.pageSize),
+			Params: p.stmt.Params,
+		}
+	}
+	params := make(map[string]interface{}, len(p.stmt.Params)+1)
+	for k, v := range p.stmt.Params {
+		params[k] = v
+	}
+	params[pageKeyParam] = *p.lastKey
+	return Statement{
+		SQL:    fmt.Sprintf("SELECT * FROM (%s) WHERE %s > @%s ORDER BY %s LIMIT %d", p.stmt.SQL, p.keyColumn, pageKeyParam, p.keyColumn, p.pageSize),
+		Params: params,
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+)
+
+func pageKeys(t *testing.T, rows []*Row) []string {
+	t.Helper()
+	keys := make([]string, len(rows))
+	for i, r := range rows {
+		if err := r.ColumnByName("Key", &keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func TestQueryPaged_LimitOffset(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	sql := "SELECT Key, Value FROM Kv ORDER BY Key"
+	if err := server.TestSpanner.PutStatementResult(sql+" LIMIT 2 OFFSET 0", server.CreateKvResult("k1", "vk1", "k2", "vk2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.TestSpanner.PutStatementResult(sql+" LIMIT 2 OFFSET 2", server.CreateKvResult("k3", "vk3", "k4", "vk4")); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.TestSpanner.PutStatementResult(sql+" LIMIT 2 OFFSET 4", server.CreateKvResult("k5", "vk5")); err != nil {
+		t.Fatal(err)
+	}
+
+	txn := client.ReadOnlyTransaction()
+	defer txn.Close()
+	pages := txn.QueryPaged(context.Background(), NewStatement(sql), 2)
+	var got [][]string
+	for {
+		rows, err := pages.NextPage()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, pageKeys(t, rows))
+	}
+	want := [][]string{{"k1", "k2"}, {"k3", "k4"}, {"k5"}}
+	if !testEqual(got, want) {
+		t.Fatalf("pages mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	if _, err := pages.NextPage(); err != iterator.Done {
+		t.Fatalf("error mismatch after last page\nGot: %v\nWant: %v", err, iterator.Done)
+	}
+}
+
+func TestQueryPaged_KeyColumn(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	sql := "SELECT Key, Value FROM Kv"
+	if err := server.TestSpanner.PutStatementResult(fmt.Sprintf("SELECT * FROM (%s) ORDER BY Key LIMIT 2", sql), server.CreateKvResult("k1", "vk1", "k2", "vk2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.TestSpanner.PutStatementResult(fmt.Sprintf("SELECT * FROM (%s) WHERE Key > @_page_key ORDER BY Key LIMIT 2", sql), server.CreateKvResult("k3", "vk3")); err != nil {
+		t.Fatal(err)
+	}
+
+	txn := client.ReadOnlyTransaction()
+	defer txn.Close()
+	pages := txn.QueryPaged(context.Background(), NewStatement(sql), 2).WithKeyColumn("Key")
+	var got [][]string
+	for {
+		rows, err := pages.NextPage()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, pageKeys(t, rows))
+	}
+	want := [][]string{{"k1", "k2"}, {"k3"}}
+	if !testEqual(got, want) {
+		t.Fatalf("pages mismatch\nGot: %v\nWant: %v", got, want)
+	}
+
+	// The second page must start after the last key of the first page.
+	var pageKeyParams []string
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			if v, ok := sqlReq.Params.GetFields()[pageKeyParam]; ok {
+				pageKeyParams = append(pageKeyParams, v.GetStringValue())
+			}
+		}
+	}
+	if g, w := pageKeyParams, []string{"k2"}; !testEqual(g, w) {
+		t.Fatalf("page key parameters mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestQueryPaged_InvalidPageSize(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	_, err := client.Single().QueryPaged(context.Background(), NewStatement("SELECT Key, Value FROM Kv"), 0).NextPage()
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
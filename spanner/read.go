@@ -21,6 +21,10 @@ import (
 	"context"
 	"io"
 	"log"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -30,6 +34,7 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 	proto3 "google.golang.org/protobuf/types/known/structpb"
 )
@@ -126,6 +131,20 @@ type RowIterator struct {
 	err              error
 	rows             []*Row
 	sawStats         bool
+
+	// checkReadTimestamp is called with the read timestamp of the first
+	// response that contains a read timestamp. Next returns the error of
+	// checkReadTimestamp if it is not nil.
+	checkReadTimestamp func(time.Time) error
+
+	// statsSink is called with the statistics of the query when Next returns
+	// iterator.Done.
+	statsSink func(*sppb.ResultSetStats)
+	stats     *sppb.ResultSetStats
+
+	// peerAddr is the address that is returned by PeerAddr for an iterator
+	// without a stream, see Sorted.
+	peerAddr net.Addr
 }
 
 // this is for safety from future changes to RowIterator making sure that it implements rowIterator interface.
@@ -135,10 +154,21 @@ var _ rowIterator = (*RowIterator)(nil)
 // there are no more results. Once Next returns Done, all subsequent calls
 // will return Done.
 func (r *RowIterator) Next() (*Row, error) {
+	if r.rowd != nil && r.rowd.reuseRows {
+		// The rows that are returned by Next must remain valid.
+		r.rowd.reuseRows = false
+		r.rowd.reusable = nil
+		r.rowd.rowsBuf = nil
+	}
+	return r.next()
+}
+
+// next returns the next row of the iterator.
+func (r *RowIterator) next() (*Row, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
-	for len(r.rows) == 0 && r.streamd.next() {
+	for len(r.rows) == 0 && r.streamd != nil && r.streamd.next() {
 		prs := r.streamd.get()
 		if r.setTransactionID != nil {
 			// this is when Read/Query is executed using ReadWriteTransaction
@@ -157,6 +187,7 @@ func (r *RowIterator) Next() (*Row, error) {
 		}
 		if prs.Stats != nil {
 			r.sawStats = true
+			r.stats = prs.Stats
 			r.QueryPlan = prs.Stats.QueryPlan
 			r.QueryStats = protostruct.DecodeToMap(prs.Stats.QueryStats)
 			if prs.Stats.RowCount != nil {
@@ -179,22 +210,120 @@ func (r *RowIterator) Next() (*Row, error) {
 			r.setTimestamp(r.rowd.ts)
 			r.setTimestamp = nil
 		}
+		if !r.rowd.ts.IsZero() && r.checkReadTimestamp != nil {
+			r.err = r.checkReadTimestamp(r.rowd.ts)
+			r.checkReadTimestamp = nil
+			if r.err != nil {
+				return nil, r.err
+			}
+		}
 	}
 	if len(r.rows) > 0 {
 		row := r.rows[0]
 		r.rows = r.rows[1:]
 		return row, nil
 	}
+	if r.streamd == nil {
+		// The iterator only contains rows that have already been received,
+		// see Sorted.
+		r.err = iterator.Done
+		return nil, r.err
+	}
 	if err := r.streamd.lastErr(); err != nil {
 		r.err = ToSpannerError(err)
 	} else if !r.rowd.done() {
 		r.err = errEarlyReadEnd()
 	} else {
 		r.err = iterator.Done
+		if r.statsSink != nil && r.stats != nil {
+			r.statsSink(r.stats)
+			r.statsSink = nil
+		}
 	}
 	return nil, r.err
 }
 
+// NextInto decodes the next row of the iterator into dst, which must not be
+// nil. It returns iterator.Done if there are no more rows, and any other
+// error in the same way as Next.
+//
+// NextInto reuses the internal buffers of dst and of the iterator, and
+// allocates less memory per row than Next. Use it to scan a large number of
+// rows in a tight loop:
+//
+//	var row spanner.Row
+//	for {
+//		err := iter.NextInto(&row)
+//		if err == iterator.Done {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		// Use row. The values of row are overwritten by the next call to
+//		// NextInto.
+//	}
+//
+// A row that is filled by NextInto must not be used after the next call to
+// NextInto with the same row. Decode the values that must be retained, for
+// example with Columns or ToStruct.
+func (r *RowIterator) NextInto(dst *Row) error {
+	if r.streamd != nil {
+		// The rows that are yielded by the decoder are copied into dst, and
+		// are never returned to the caller.
+		r.rowd.reuseRows = true
+	}
+	row, err := r.next()
+	if err != nil {
+		return err
+	}
+	dst.fields = row.fields
+	dst.vals = append(dst.vals[:0], row.vals...)
+	dst.cfg = row.cfg
+	return nil
+}
+
+// PeerAddr returns the address of the server that returned the results of
+// the iterator, for example to find out which backend served a streaming
+// query. The address is available after the first call to Next, and remains
+// available after the iteration has finished. If the stream was resumed on
+// another server, PeerAddr returns the address of the last server. PeerAddr
+// returns nil if the address is not known.
+//
+// PeerAddr must not be called concurrently with Next.
+func (r *RowIterator) PeerAddr() net.Addr {
+	if r.streamd != nil {
+		return r.streamd.peerAddr
+	}
+	return r.peerAddr
+}
+
+// errMetadataNotAvailable returns error for requesting metadata from a
+// RowIterator before the metadata has been received.
+func errMetadataNotAvailable() error {
+	return spannerErrorf(codes.FailedPrecondition, "result set metadata is not available, call Next first")
+}
+
+// UndeclaredParameters returns the types of the parameters that were used in
+// the SQL statement but were not declared in the statement's parameter types.
+// Cloud Spanner only infers and returns undeclared parameters for some
+// statements, for example when a PostgreSQL-dialect statement is analyzed with
+// AnalyzeQuery. The result is nil if the metadata does not contain any
+// undeclared parameters.
+//
+// The undeclared parameters are part of the result set metadata, and are
+// available after the first call to RowIterator.Next(). An error is returned
+// if the metadata has not been received.
+func (r *RowIterator) UndeclaredParameters() (*sppb.StructType, error) {
+	if r.Metadata == nil {
+		if r.err != nil && r.err != iterator.Done {
+			return nil, r.err
+		}
+		return nil, errMetadataNotAvailable()
+	}
+	return r.Metadata.UndeclaredParameters, nil
+}
+
 func extractRowCount(stats *sppb.ResultSetStats) (int64, error) {
 	if stats.RowCount == nil {
 		return 0, spannerErrorf(codes.Internal, "missing RowCount")
@@ -209,6 +338,56 @@ func extractRowCount(stats *sppb.ResultSetStats) (int64, error) {
 	}
 }
 
+// BytesReceived returns the total serialized size in bytes of the results
+// that the iterator has received from Cloud Spanner and processed so far. The
+// value increases while the rows of the iterator are consumed, and contains
+// the size of all results once Next has returned iterator.Done. Results that
+// were received by an attempt of the stream that was retried are not counted.
+func (r *RowIterator) BytesReceived() int64 {
+	if r.rowd == nil {
+		return 0
+	}
+	return r.rowd.bytesReceived
+}
+
+// Sorted reads all remaining rows of the iterator, sorts them with the given
+// less function, and returns a new iterator that returns the sorted rows. The
+// sort is stable, so rows that are equal according to less keep the order in
+// which they were returned by Cloud Spanner. This can be used to get a
+// deterministic order for small results of queries without an ORDER BY clause.
+//
+// All rows are held in memory until the returned iterator is stopped or has
+// been fully consumed, so Sorted should only be used for results that fit in
+// memory. Prefer an ORDER BY clause for large results.
+//
+// Sorted always calls Stop on the iterator, and returns the error of the
+// iterator if it fails before all rows have been read. The QueryPlan,
+// QueryStats, RowCount and Metadata of the returned iterator are copied from
+// the iterator.
+func (r *RowIterator) Sorted(less func(a, b *Row) bool) (*RowIterator, error) {
+	var rows []*Row
+	if err := r.Do(func(row *Row) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return less(rows[i], rows[j])
+	})
+	return &RowIterator{
+		QueryPlan:  r.QueryPlan,
+		QueryStats: r.QueryStats,
+		RowCount:   r.RowCount,
+		Metadata:   r.Metadata,
+		rowd:       r.rowd,
+		rows:       rows,
+		peerAddr:   r.PeerAddr(),
+		// Make Next return an error after Stop, as for other iterators.
+		release: func(error) {},
+	}, nil
+}
+
 // Do calls the provided function once in sequence for each row in the
 // iteration. If the function returns a non-nil error, Do immediately returns
 // that error.
@@ -234,6 +413,232 @@ func (r *RowIterator) Do(f func(r *Row) error) error {
 	}
 }
 
+// ParallelDo calls the provided function once for each row of the iterator,
+// using parallelism goroutines to process the rows concurrently. The rows are
+// received from the underlying stream one at a time in the order of the
+// result, but may be processed and completed in any order. ParallelDo is
+// therefore only suitable for processing where the order of the rows does
+// not matter. A parallelism of less than 1 is treated as 1.
+//
+// The provided function must be safe for concurrent use. If it returns an
+// error, or if the iterator returns an error, no new rows are dispatched and
+// ParallelDo returns the first error after all running calls have finished.
+// ParallelDo also stops dispatching rows and returns the error of the context
+// if ctx is done before all rows have been processed.
+//
+// ParallelDo always calls Stop on the iterator.
+func (r *RowIterator) ParallelDo(ctx context.Context, parallelism int, f func(r *Row) error) error {
+	defer r.Stop()
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	dispatchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	rows := make(chan *Row)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				if err := f(row); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+dispatch:
+	for {
+		row, err := r.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			setErr(err)
+			break
+		}
+		select {
+		case rows <- row:
+		case <-dispatchCtx.Done():
+			break dispatch
+		}
+	}
+	close(rows)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if firstErr != nil {
+		return firstErr
+	}
+	if ctx.Err() != nil {
+		return ToSpannerError(ctx.Err())
+	}
+	return nil
+}
+
+// PullSource returns a function that returns the next row of the iterator
+// each time that it is called. The rows are only fetched when the function is
+// called, so the caller controls the pace at which rows are read from the
+// stream, and no goroutines are started.
+//
+// The returned function returns the next row and ok=true as long as there are
+// more rows. When there are no more rows, it returns ok=false and a nil error.
+// If the iterator or ctx returns an error, the function returns ok=false and
+// that error. The iterator is stopped when it is exhausted or returns an
+// error, and all later calls return the same ok=false result.
+func (r *RowIterator) PullSource(ctx context.Context) (pull func() (*Row, error, bool)) {
+	var (
+		done    bool
+		lastErr error
+	)
+	return func() (*Row, error, bool) {
+		if done {
+			return nil, lastErr, false
+		}
+		if err := ctx.Err(); err != nil {
+			done, lastErr = true, ToSpannerError(err)
+			r.Stop()
+			return nil, lastErr, false
+		}
+		row, err := r.Next()
+		if err == nil {
+			return row, nil, true
+		}
+		done = true
+		if err != iterator.Done {
+			lastErr = err
+		}
+		r.Stop()
+		return nil, lastErr, false
+	}
+}
+
+// ToColumns reads all remaining rows of the iterator and returns the values
+// of each column as a slice, keyed by the column name. The type of each slice
+// is determined by the type of the column:
+//
+//   - BOOL: []NullBool
+//   - INT64: []NullInt64
+//   - FLOAT32: []NullFloat32
+//   - FLOAT64: []NullFloat64
+//   - STRING: []NullString
+//   - BYTES: [][]byte
+//   - TIMESTAMP: []NullTime
+//   - DATE: []NullDate
+//   - NUMERIC: []NullNumeric, or []PGNumeric for PostgreSQL NUMERIC
+//   - JSON: []NullJSON, or []PGJsonB for PostgreSQL JSONB
+//
+// All other column types are returned as []GenericColumnValue. The i-th
+// element of each slice contains the value of the i-th row. An error is
+// returned if the result contains columns with duplicate or empty names.
+//
+// ToColumns always calls Stop on the iterator.
+func (r *RowIterator) ToColumns() (columns map[string]interface{}, err error) {
+	var slices []reflect.Value
+	if err := r.Do(func(row *Row) error {
+		if slices == nil {
+			var err error
+			if slices, err = columnSlices(r.Metadata.GetRowType().GetFields()); err != nil {
+				return err
+			}
+		}
+		for i := range slices {
+			v := reflect.New(slices[i].Type().Elem())
+			if err := row.Column(i, v.Interface()); err != nil {
+				return err
+			}
+			slices[i] = reflect.Append(slices[i], v.Elem())
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	fields := r.Metadata.GetRowType().GetFields()
+	if slices == nil {
+		if slices, err = columnSlices(fields); err != nil {
+			return nil, err
+		}
+	}
+	columns = make(map[string]interface{}, len(fields))
+	for i, f := range fields {
+		columns[f.Name] = slices[i].Interface()
+	}
+	return columns, nil
+}
+
+// errUnnamedColumn returns error for a column without a name in a result that
+// is read with ToColumns.
+func errUnnamedColumn(i int) error {
+	return spannerErrorf(codes.FailedPrecondition, "column %d has no name", i)
+}
+
+// columnSlices returns an empty slice for each of the given columns with the
+// element type that is used by ToColumns for the type of the column.
+func columnSlices(fields []*sppb.StructType_Field) ([]reflect.Value, error) {
+	seen := make(map[string]bool, len(fields))
+	slices := make([]reflect.Value, len(fields))
+	for i, f := range fields {
+		if f.Name == "" {
+			return nil, errUnnamedColumn(i)
+		}
+		if seen[f.Name] {
+			return nil, errDupColName(f.Name)
+		}
+		seen[f.Name] = true
+		slices[i] = reflect.MakeSlice(reflect.SliceOf(columnElemType(f.Type)), 0, 0)
+	}
+	return slices, nil
+}
+
+// columnElemType returns the Go type that ToColumns uses for the values of a
+// column with the given type.
+func columnElemType(t *sppb.Type) reflect.Type {
+	switch t.GetCode() {
+	case sppb.TypeCode_BOOL:
+		return reflect.TypeOf(NullBool{})
+	case sppb.TypeCode_INT64:
+		return reflect.TypeOf(NullInt64{})
+	case sppb.TypeCode_FLOAT32:
+		return reflect.TypeOf(NullFloat32{})
+	case sppb.TypeCode_FLOAT64:
+		return reflect.TypeOf(NullFloat64{})
+	case sppb.TypeCode_STRING:
+		return reflect.TypeOf(NullString{})
+	case sppb.TypeCode_BYTES:
+		return reflect.TypeOf([]byte(nil))
+	case sppb.TypeCode_TIMESTAMP:
+		return reflect.TypeOf(NullTime{})
+	case sppb.TypeCode_DATE:
+		return reflect.TypeOf(NullDate{})
+	case sppb.TypeCode_NUMERIC:
+		if t.GetTypeAnnotation() == sppb.TypeAnnotationCode_PG_NUMERIC {
+			return reflect.TypeOf(PGNumeric{})
+		}
+		return reflect.TypeOf(NullNumeric{})
+	case sppb.TypeCode_JSON:
+		if t.GetTypeAnnotation() == sppb.TypeAnnotationCode_PG_JSONB {
+			return reflect.TypeOf(PGJsonB{})
+		}
+		return reflect.TypeOf(NullJSON{})
+	default:
+		return reflect.TypeOf(GenericColumnValue{})
+	}
+}
+
 // Stop terminates the iteration. It should be called after you finish using the
 // iterator.
 func (r *RowIterator) Stop() {
@@ -244,6 +649,9 @@ func (r *RowIterator) Stop() {
 			defer trace.EndSpan(r.streamd.ctx, nil)
 		}
 	}
+	if r.streamd != nil {
+		r.streamd.stopStream()
+	}
 	if r.cancel != nil {
 		r.cancel()
 	}
@@ -376,6 +784,10 @@ type resumableStreamDecoder struct {
 	// stream is the current RPC streaming receiver.
 	stream streamingReceiver
 
+	// peerAddr is the address of the server of the last stream that was
+	// started, or nil if the address is not known.
+	peerAddr net.Addr
+
 	// q buffers received yet undecoded partial results.
 	q partialResultQueue
 
@@ -403,6 +815,25 @@ type resumableStreamDecoder struct {
 
 	// backoff is used for the retry settings
 	backoff gax.Backoff
+	// retryClassifier determines which errors of the stream are retried.
+	// DefaultRetryClassifier is used if it is nil.
+	retryClassifier RetryClassifier
+
+	// inactivityTimeout is the maximum time that the decoder waits for the
+	// next PartialResultSet of a stream. Zero means no limit.
+	inactivityTimeout time.Duration
+	// cancelStream cancels the context of the current stream. It is only set
+	// if inactivityTimeout is set, and is called when the stream is replaced,
+	// when the decoder finishes or aborts, and when the iterator is stopped.
+	cancelStream context.CancelFunc
+}
+
+// stopStream cancels the context of the current stream, if the decoder
+// created one.
+func (d *resumableStreamDecoder) stopStream() {
+	if d.cancelStream != nil {
+		d.cancelStream()
+	}
 }
 
 // newResumableStreamDecoder creates a new resumeableStreamDecoder instance.
@@ -497,14 +928,32 @@ var (
 	maxBytesBetweenResumeTokens = int32(128 * 1024 * 1024)
 )
 
+// recordPeerAddr records the address of the server of the current stream, if
+// the stream is a gRPC stream.
+func (d *resumableStreamDecoder) recordPeerAddr() {
+	s, ok := d.stream.(interface{ Context() context.Context })
+	if !ok {
+		return
+	}
+	if p, ok := peer.FromContext(s.Context()); ok {
+		d.peerAddr = p.Addr
+	}
+}
+
 func (d *resumableStreamDecoder) next() bool {
-	retryer := onCodes(d.backoff, codes.Unavailable, codes.ResourceExhausted, codes.Internal)
+	retryer := newRetryer(d.retryClassifier, RetryOperationStream, d.backoff)
 	for {
 		switch d.state {
 		case unConnected:
 			// If no gRPC stream is available, try to initiate one.
-			d.stream, d.err = d.rpc(d.ctx, d.resumeToken)
+			ctx := d.ctx
+			if d.inactivityTimeout > 0 {
+				d.stopStream()
+				ctx, d.cancelStream = context.WithCancel(d.ctx)
+			}
+			d.stream, d.err = d.rpc(ctx, d.resumeToken)
 			if d.err == nil {
+				d.recordPeerAddr()
 				d.changeState(queueingRetryable)
 				continue
 			}
@@ -570,8 +1019,12 @@ func (d *resumableStreamDecoder) next() bool {
 			// Discard all pending items because none of them should be yield
 			// to caller.
 			d.q.clear()
+			d.stopStream()
 			return false
 		case finished:
+			// The stream has returned all its data, so its context is no
+			// longer needed.
+			d.stopStream()
 			// If query has finished, check if there are still buffered messages.
 			if d.q.empty() {
 				// No buffered PartialResultSet.
@@ -592,7 +1045,18 @@ func (d *resumableStreamDecoder) next() bool {
 // tryRecv attempts to receive a PartialResultSet from gRPC stream.
 func (d *resumableStreamDecoder) tryRecv(retryer gax.Retryer) {
 	var res *sppb.PartialResultSet
-	res, d.err = d.stream.Recv()
+	var inactive bool
+	if d.cancelStream != nil {
+		res, inactive, d.err = d.recvWithInactivityTimeout()
+	} else {
+		res, d.err = d.stream.Recv()
+	}
+	if inactive {
+		// The stream was cancelled because it was inactive for too long.
+		d.err = errInactivityTimeout(d.inactivityTimeout)
+		d.changeState(aborted)
+		return
+	}
 	if d.err == nil {
 		d.q.push(res)
 		if d.state == queueingRetryable && !d.isNewResumeToken(res.ResumeToken) {
@@ -606,10 +1070,12 @@ func (d *resumableStreamDecoder) tryRecv(retryer gax.Retryer) {
 		d.changeState(finished)
 		return
 	}
-	if d.replaceSessionFunc != nil && isSessionNotFoundError(d.err) && d.resumeToken == nil {
-		// A 'Session not found' error occurred before we received a resume
-		// token and a replaceSessionFunc function is defined. Try to restart
-		// the stream on a new session.
+	if d.replaceSessionFunc != nil && isSessionNotFoundError(d.err) && (d.resumeToken == nil || d.state == queueingRetryable) {
+		// A 'Session not found' error occurred and a replaceSessionFunc
+		// function is defined. Try to restart the stream on a new session.
+		// The stream is resumed from the last resume token, unless no resume
+		// token has been received yet, in which case it is restarted from the
+		// beginning.
 		if err := d.replaceSessionFunc(d.ctx); err != nil {
 			d.err = err
 			d.changeState(aborted)
@@ -635,6 +1101,34 @@ func (d *resumableStreamDecoder) tryRecv(retryer gax.Retryer) {
 	d.changeState(unConnected)
 }
 
+// recvWithInactivityTimeout receives a PartialResultSet from the gRPC stream,
+// and cancels the stream if nothing is received within inactivityTimeout. It
+// returns true if the stream was cancelled because of the inactivity timeout,
+// in which case the result of the receive must be discarded.
+func (d *resumableStreamDecoder) recvWithInactivityTimeout() (*sppb.PartialResultSet, bool, error) {
+	cancel := d.cancelStream
+	// done is set by whichever of the receive and the timer finishes first,
+	// so that the timer never cancels the stream after the receive returned.
+	var done int32
+	timer := time.AfterFunc(d.inactivityTimeout, func() {
+		if atomic.CompareAndSwapInt32(&done, 0, 1) {
+			cancel()
+		}
+	})
+	res, err := d.stream.Recv()
+	timer.Stop()
+	if !atomic.CompareAndSwapInt32(&done, 0, 1) {
+		return nil, true, err
+	}
+	return res, false, err
+}
+
+// errInactivityTimeout returns error for a stream that did not return any
+// data within the inactivity timeout.
+func errInactivityTimeout(timeout time.Duration) error {
+	return spannerErrorf(codes.DeadlineExceeded, "no data received from the stream within the inactivity timeout of %v", timeout)
+}
+
 // get returns the most recent PartialResultSet generated by a call to next.
 func (d *resumableStreamDecoder) get() *sppb.PartialResultSet {
 	return d.np
@@ -653,6 +1147,23 @@ type partialResultSetDecoder struct {
 	chunked bool // if true, next value should be merged with last values
 	// entry.
 	ts time.Time // read timestamp
+	// rowConfig is the rowConfig of the yielded rows.
+	rowConfig *rowConfig
+	// bytesReceived is the total serialized size of all PartialResultSets
+	// that have been added to the decoder.
+	bytesReceived int64
+	// reuseRows is set by RowIterator.NextInto. The rows that are yielded
+	// while reuseRows is set are not returned to clients, and are reused for
+	// the rows of the next PartialResultSet.
+	reuseRows bool
+	// reusable contains the rows that have been yielded while reuseRows was
+	// set, and nextReusable is the index of the next of these rows that can
+	// be yielded.
+	reusable     []*Row
+	nextReusable int
+	// rowsBuf is the buffer for the rows that are returned by add while
+	// reuseRows is set.
+	rowsBuf []*Row
 }
 
 // yield checks we have a complete row, and if so returns it.  A row is not
@@ -670,10 +1181,27 @@ func (p *partialResultSetDecoder) yield(chunked, last bool) *Row {
 		//
 		// Use a fresh Row to simplify clients that want to use yielded results
 		// after the next row is retrieved. Note that fields is never changed
-		// so it doesn't need to be copied.
+		// so it doesn't need to be copied. Rows that are only consumed by
+		// RowIterator.NextInto are not visible to clients, and are reused.
+		if p.reuseRows {
+			var row *Row
+			if p.nextReusable < len(p.reusable) {
+				row = p.reusable[p.nextReusable]
+			} else {
+				row = &Row{}
+				p.reusable = append(p.reusable, row)
+			}
+			p.nextReusable++
+			row.fields = p.row.fields
+			row.vals = append(row.vals[:0], p.row.vals...)
+			row.cfg = p.rowConfig
+			p.row.vals = p.row.vals[:0]
+			return row
+		}
 		fresh := Row{
 			fields: p.row.fields,
 			vals:   make([]*proto3.Value, len(p.row.vals)),
+			cfg:    p.rowConfig,
 		}
 		copy(fresh.vals, p.row.vals)
 		p.row.vals = p.row.vals[:0] // empty and reuse slice
@@ -691,10 +1219,19 @@ func errChunkedEmptyRow() error {
 // rows that have been completed as a result.
 func (p *partialResultSetDecoder) add(r *sppb.PartialResultSet) ([]*Row, *sppb.ResultSetMetadata, error) {
 	var rows []*Row
+	if p.reuseRows {
+		// All rows of the previous PartialResultSet have been consumed.
+		p.nextReusable = 0
+		rows = p.rowsBuf[:0]
+	}
+	p.bytesReceived += int64(proto.Size(r))
 	if r.Metadata != nil {
 		// Metadata should only be returned in the first result.
 		if p.row.fields == nil {
 			p.row.fields = r.Metadata.RowType.Fields
+			if p.rowConfig != nil && p.rowConfig.duplicateColumnPolicy == DuplicateColumnSuffix {
+				p.row.fields = suffixDuplicateColumns(p.row.fields)
+			}
 		}
 		if p.tx == nil && r.Metadata.Transaction != nil {
 			p.tx = r.Metadata.Transaction
@@ -739,6 +1276,9 @@ func (p *partialResultSetDecoder) add(r *sppb.PartialResultSet) ([]*Row, *sppb.R
 		// also chunked.
 		p.chunked = true
 	}
+	if p.reuseRows {
+		p.rowsBuf = rows
+	}
 	return rows, r.Metadata, nil
 }
 
@@ -0,0 +1,57 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+)
+
+// ReadModifyWrite reads the rows with the given keys from table in a
+// read/write transaction, calls fn with the rows, and commits the mutations
+// that are returned by fn in the same transaction. The rows that are passed
+// to fn contain the given columns, in the order that they were returned by
+// Cloud Spanner. Keys that do not exist are not included in the rows.
+//
+// The transaction is retried if it is aborted by Cloud Spanner, in the same
+// way as for ReadWriteTransaction. As fn is called again for each attempt, it
+// should only compute the mutations from the given rows, and should not have
+// other side effects. If fn returns an error, the transaction is rolled back
+// and the error is returned.
+//
+// ReadModifyWrite returns the commit timestamp of the transaction.
+func (c *Client) ReadModifyWrite(ctx context.Context, table string, keys KeySet, columns []string, fn func(rows []*Row) ([]*Mutation, error), options TransactionOptions) (commitTimestamp time.Time, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.ReadModifyWrite")
+	defer func() { trace.EndSpan(ctx, err) }()
+	resp, err := c.rwTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		var rows []*Row
+		if err := tx.Read(ctx, table, keys, columns).Do(func(r *Row) error {
+			rows = append(rows, r)
+			return nil
+		}); err != nil {
+			return err
+		}
+		ms, err := fn(rows)
+		if err != nil {
+			return err
+		}
+		return tx.BufferWrite(ms)
+	}, options)
+	return resp.CommitTs, err
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	. "cloud.google.com/go/spanner/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// expireKvRows returns Update mutations that set the Value of the given rows
+// to "expired" if the current Value is "stale".
+func expireKvRows(rows []*Row) ([]*Mutation, error) {
+	var ms []*Mutation
+	for _, row := range rows {
+		var key, value string
+		if err := row.Columns(&key, &value); err != nil {
+			return nil, err
+		}
+		if value == "stale" {
+			ms = append(ms, Update("Kv", []string{"Key", "Value"}, []interface{}{key, "expired"}))
+		}
+	}
+	return ms, nil
+}
+
+func TestClient_ReadModifyWrite(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", server.CreateKvResult("k1", "stale", "k2", "fresh")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var numRows int
+	ts, err := client.ReadModifyWrite(ctx, "Kv", KeySets(Key{"k1"}, Key{"k2"}), []string{"Key", "Value"}, func(rows []*Row) ([]*Mutation, error) {
+		numRows = len(rows)
+		return expireKvRows(rows)
+	}, TransactionOptions{TransactionTag: "expire"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.IsZero() {
+		t.Fatal("missing commit timestamp")
+	}
+	if g, w := numRows, 2; g != w {
+		t.Fatalf("number of rows mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	var read *sppb.ReadRequest
+	var commit *sppb.CommitRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req := req.(type) {
+		case *sppb.ReadRequest:
+			read = req
+		case *sppb.CommitRequest:
+			commit = req
+		}
+	}
+	if read == nil || commit == nil {
+		t.Fatalf("missing read or commit request\nRead: %v\nCommit: %v", read, commit)
+	}
+	if read.GetTransaction().GetBegin().GetReadWrite() == nil {
+		t.Fatalf("read did not begin a read/write transaction: %v", read.GetTransaction())
+	}
+	if g, w := read.GetRequestOptions().GetTransactionTag(), "expire"; g != w {
+		t.Fatalf("transaction tag mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	want, err := mutationsProto([]*Mutation{Update("Kv", []string{"Key", "Value"}, []interface{}{"k1", "expired"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(commit.Mutations, want) {
+		t.Fatalf("mutations mismatch\nGot: %v\nWant: %v", commit.Mutations, want)
+	}
+}
+
+func TestClient_ReadModifyWrite_RetriesAborted(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", server.CreateKvResult("k1", "stale", "k2", "stale")); err != nil {
+		t.Fatal(err)
+	}
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.Aborted, "Transaction aborted")},
+		})
+	ctx := context.Background()
+
+	var attempts int
+	if _, err := client.ReadModifyWrite(ctx, "Kv", KeySets(Key{"k1"}, Key{"k2"}), []string{"Key", "Value"}, func(rows []*Row) ([]*Mutation, error) {
+		attempts++
+		return expireKvRows(rows)
+	}, TransactionOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	commits := commitRequestsFromServer(server)
+	if g, w := len(commits), 2; g != w {
+		t.Fatalf("number of commits mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := len(commits[1].Mutations), 2; g != w {
+		t.Fatalf("number of mutations mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestClient_ReadModifyWrite_FuncError(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT Key, Value FROM Kv", server.CreateKvResult("k1", "stale")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	wantErr := errors.New("no changes allowed")
+	_, err := client.ReadModifyWrite(ctx, "Kv", KeySets(Key{"k1"}), []string{"Key", "Value"}, func(rows []*Row) ([]*Mutation, error) {
+		return nil, wantErr
+	}, TransactionOptions{})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, wantErr)
+	}
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if _, ok := req.(*sppb.CommitRequest); ok {
+			t.Fatal("transaction was committed after fn returned an error")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+)
+
+// errNoKeyColumns returns error for calling ReadRangeWithKey without key
+// columns.
+func errNoKeyColumns() error {
+	return spannerErrorf(codes.InvalidArgument, "at least one key column must be specified")
+}
+
+// errUnsupportedKeyColumnType returns error for a key column with a type that
+// cannot be used as a part of a Key.
+func errUnsupportedKeyColumnType(col string, t *sppb.Type) error {
+	return spannerErrorf(codes.InvalidArgument, "key column %q has unsupported type %v", col, t)
+}
+
+// ReadRangeWithKey reads the rows of table in the key range kr, and calls fn
+// for each row with the primary key of the row and the row itself.
+//
+// keyColumns must be the primary key columns of table, in the order of the
+// primary key, or a prefix of these. The row that is passed to fn contains
+// keyColumns followed by valueColumns. The parts of the Key that is passed to
+// fn have the Go type that corresponds to the type of the key column, for
+// example int64 for INT64 and string for STRING columns. A NULL key part is
+// returned as the NullXXX type of the column, for example NullInt64{}.
+//
+// ReadRangeWithKey stops and returns the error if fn returns an error.
+func (t *ReadOnlyTransaction) ReadRangeWithKey(ctx context.Context, table string, kr KeyRange, keyColumns, valueColumns []string, fn func(key Key, row *Row) error) error {
+	if len(keyColumns) == 0 {
+		return errNoKeyColumns()
+	}
+	columns := make([]string, 0, len(keyColumns)+len(valueColumns))
+	columns = append(columns, keyColumns...)
+	columns = append(columns, valueColumns...)
+	return t.Read(ctx, table, kr, columns).Do(func(r *Row) error {
+		key := make(Key, len(keyColumns))
+		for i := range keyColumns {
+			part, err := keyPartFromColumn(r, i)
+			if err != nil {
+				return err
+			}
+			key[i] = part
+		}
+		return fn(key, r)
+	})
+}
+
+// keyPartFromColumn decodes column i of r into a value that can be used as a
+// part of a Key.
+func keyPartFromColumn(r *Row, i int) (interface{}, error) {
+	t := r.ColumnType(i)
+	if t == nil {
+		return nil, errColIdxOutOfRange(i, r)
+	}
+	switch t.Code {
+	case sppb.TypeCode_INT64:
+		var v NullInt64
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Int64, nil
+	case sppb.TypeCode_STRING:
+		var v NullString
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.StringVal, nil
+	case sppb.TypeCode_BYTES:
+		var v []byte
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case sppb.TypeCode_BOOL:
+		var v NullBool
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Bool, nil
+	case sppb.TypeCode_FLOAT64:
+		var v NullFloat64
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Float64, nil
+	case sppb.TypeCode_FLOAT32:
+		var v NullFloat32
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Float32, nil
+	case sppb.TypeCode_TIMESTAMP:
+		var v NullTime
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Time, nil
+	case sppb.TypeCode_DATE:
+		var v NullDate
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Date, nil
+	case sppb.TypeCode_NUMERIC:
+		var v NullNumeric
+		if err := r.Column(i, &v); err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return v, nil
+		}
+		return v.Numeric, nil
+	}
+	return nil, errUnsupportedKeyColumnType(r.ColumnName(i), t)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/grpc/codes"
+	proto3 "google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestReadRangeWithKey(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	var gotKeys []Key
+	var gotTitles []string
+	err := client.Single().ReadRangeWithKey(ctx, "Albums", KeyRange{Start: Key{1}, End: Key{3}, Kind: ClosedClosed},
+		[]string{"SingerId", "AlbumId"}, []string{"AlbumTitle"},
+		func(key Key, row *Row) error {
+			var title string
+			if err := row.ColumnByName("AlbumTitle", &title); err != nil {
+				return err
+			}
+			gotKeys = append(gotKeys, key)
+			gotTitles = append(gotTitles, title)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKeys := []Key{{int64(1), int64(0)}, {int64(2), int64(11)}, {int64(3), int64(22)}}
+	if !testEqual(gotKeys, wantKeys) {
+		t.Errorf("keys mismatch\nGot: %v\nWant: %v", gotKeys, wantKeys)
+	}
+	wantTitles := []string{"Album title 0", "Album title 1", "Album title 2"}
+	if !testEqual(gotTitles, wantTitles) {
+		t.Errorf("titles mismatch\nGot: %v\nWant: %v", gotTitles, wantTitles)
+	}
+
+	var readReq *sppb.ReadRequest
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if r, ok := req.(*sppb.ReadRequest); ok {
+			readReq = r
+		}
+	}
+	if readReq == nil {
+		t.Fatal("missing read request")
+	}
+	if g, w := readReq.Columns, []string{"SingerId", "AlbumId", "AlbumTitle"}; !testEqual(g, w) {
+		t.Errorf("columns mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := len(readReq.KeySet.Ranges), 1; g != w {
+		t.Errorf("key range count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestReadRangeWithKey_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+	kr := KeyRange{Start: Key{1}, End: Key{3}, Kind: ClosedClosed}
+
+	err := client.Single().ReadRangeWithKey(ctx, "Albums", kr, nil, []string{"SingerId", "AlbumId", "AlbumTitle"},
+		func(key Key, row *Row) error { return nil })
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	stop := errors.New("stop")
+	var calls int
+	err = client.Single().ReadRangeWithKey(ctx, "Albums", kr, []string{"SingerId"}, []string{"AlbumId", "AlbumTitle"},
+		func(key Key, row *Row) error {
+			calls++
+			return stop
+		})
+	if !errors.Is(err, stop) {
+		t.Errorf("error mismatch\nGot: %v\nWant: %v", err, stop)
+	}
+	if g, w := calls, 1; g != w {
+		t.Errorf("call count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestKeyPartFromColumn(t *testing.T) {
+	row := &Row{
+		fields: []*sppb.StructType_Field{
+			{Name: "Id", Type: intType()},
+			{Name: "Name", Type: stringType()},
+			{Name: "Missing", Type: intType()},
+			{Name: "Tags", Type: &sppb.Type{Code: sppb.TypeCode_ARRAY, ArrayElementType: stringType()}},
+		},
+		vals: []*proto3.Value{
+			intProto(1),
+			stringProto("foo"),
+			nullProto(),
+			listProto(stringProto("bar")),
+		},
+	}
+	for i, want := range []interface{}{int64(1), "foo", NullInt64{}} {
+		got, err := keyPartFromColumn(row, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !testEqual(got, want) {
+			t.Errorf("key part %d mismatch\nGot: %v\nWant: %v", i, got, want)
+		}
+	}
+	if _, err := keyPartFromColumn(row, 3); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -1782,3 +1783,277 @@ func createSession(client *vkit.Client) (*sppb.Session, error) {
 	}
 	return client.CreateSession(context.Background(), request)
 }
+
+func TestRowIteratorUndeclaredParameters(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	sql := "SELECT Key, Value FROM KV WHERE Key=$1"
+	metadata := KvMeta()
+	metadata.UndeclaredParameters = &sppb.StructType{
+		Fields: []*sppb.StructType_Field{
+			{Name: "p1", Type: &sppb.Type{Code: sppb.TypeCode_STRING}},
+		},
+	}
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: metadata},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	iter := client.Single().Query(context.Background(), NewStatement(sql))
+	defer iter.Stop()
+	if _, err := iter.UndeclaredParameters(); ErrCode(err) != codes.FailedPrecondition {
+		t.Fatalf("error mismatch before first call to Next\nGot: %v\nWant: %v", err, codes.FailedPrecondition)
+	}
+	if _, err := iter.Next(); err != iterator.Done {
+		t.Fatalf("unexpected result from Next: %v", err)
+	}
+	got, err := iter.UndeclaredParameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, metadata.UndeclaredParameters) {
+		t.Fatalf("undeclared parameters mismatch\nGot: %v\nWant: %v", got, metadata.UndeclaredParameters)
+	}
+	if !proto.Equal(iter.Metadata.RowType, metadata.RowType) {
+		t.Fatalf("row type mismatch\nGot: %v\nWant: %v", iter.Metadata.RowType, metadata.RowType)
+	}
+}
+
+func TestRowIteratorNextInto(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	const stmt = "SELECT Key, Value FROM KV"
+	if err := setupStatementResult(t, server, stmt, 25, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var want [][]string
+	iter := client.Single().Query(ctx, NewStatement(stmt))
+	for {
+		row, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var k, v string
+		if err := row.Columns(&k, &v); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, []string{k, v})
+	}
+	iter.Stop()
+
+	var got [][]string
+	var row Row
+	iter = client.Single().Query(ctx, NewStatement(stmt))
+	defer iter.Stop()
+	for {
+		err := iter.NextInto(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, w := row.ColumnNames(), []string{"Key", "Value"}; !testEqual(g, w) {
+			t.Fatalf("column names mismatch\nGot: %v\nWant: %v", g, w)
+		}
+		var k, v string
+		if err := row.Columns(&k, &v); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, []string{k, v})
+	}
+	if !testEqual(got, want) {
+		t.Fatalf("rows mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	if err := iter.NextInto(&row); err != iterator.Done {
+		t.Fatalf("error mismatch after the last row\nGot: %v\nWant: %v", err, iterator.Done)
+	}
+}
+
+// prsReceiver is a streamingReceiver that returns a fixed list of
+// PartialResultSets, followed by err, or io.EOF if err is nil.
+type prsReceiver struct {
+	prs []*sppb.PartialResultSet
+	err error
+}
+
+// Recv implements streamingReceiver.Recv for prsReceiver.
+func (r *prsReceiver) Recv() (*sppb.PartialResultSet, error) {
+	if len(r.prs) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	prs := r.prs[0]
+	r.prs = r.prs[1:]
+	return prs, nil
+}
+
+func TestRowIteratorNextIntoMixedWithNext(t *testing.T) {
+	t.Parallel()
+
+	var prs []*sppb.PartialResultSet
+	for i := 0; i < 3; i++ {
+		p := &sppb.PartialResultSet{}
+		if i == 0 {
+			p.Metadata = kvMeta
+		}
+		for j := 0; j < 2; j++ {
+			k := i*2 + j
+			p.Values = append(p.Values, stringProto(keyStr(k)), stringProto(valStr(k)))
+		}
+		prs = append(prs, p)
+	}
+	iter := stream(context.Background(), nil,
+		func(ct context.Context, resumeToken []byte) (streamingReceiver, error) {
+			return &prsReceiver{prs: prs}, nil
+		},
+		nil,
+		func(error) {})
+	defer iter.Stop()
+
+	var row Row
+	if err := iter.NextInto(&row); err != nil {
+		t.Fatal(err)
+	}
+	// A row that is returned by Next must not be changed by the following
+	// calls to NextInto.
+	kept, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for {
+		err := iter.NextInto(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var k string
+		if err := row.Column(0, &k); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+	}
+	if g, w := keys, []string{keyStr(2), keyStr(3), keyStr(4), keyStr(5)}; !testEqual(g, w) {
+		t.Fatalf("keys mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var k string
+	if err := kept.Column(0, &k); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := k, keyStr(1); g != w {
+		t.Fatalf("key of row returned by Next mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestRowIteratorInactivityTimeoutCancelsStream(t *testing.T) {
+	t.Parallel()
+
+	prs := []*sppb.PartialResultSet{{
+		Metadata: kvMeta,
+		Values:   []*proto3.Value{stringProto(keyStr(0)), stringProto(valStr(0))},
+	}}
+	for _, test := range []struct {
+		name string
+		recv streamingReceiver
+		// stopEarly stops the iterator before it has returned all rows.
+		stopEarly bool
+	}{
+		{name: "EOF", recv: &prsReceiver{prs: prs}},
+		{name: "error", recv: &prsReceiver{prs: prs, err: status.Error(codes.InvalidArgument, "invalid")}},
+		{name: "Stop", recv: &prsReceiver{prs: prs}, stopEarly: true},
+	} {
+		var streamCtx context.Context
+		iter := stream(context.Background(), nil,
+			func(ct context.Context, resumeToken []byte) (streamingReceiver, error) {
+				streamCtx = ct
+				return test.recv, nil
+			},
+			nil,
+			func(error) {})
+		iter.streamd.inactivityTimeout = time.Minute
+		if test.stopEarly {
+			if _, err := iter.Next(); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			iter.Stop()
+		} else {
+			// The stream must be cancelled when the decoder finishes or
+			// aborts, without waiting for Stop.
+			for {
+				if _, err := iter.Next(); err != nil {
+					break
+				}
+			}
+			defer iter.Stop()
+		}
+		if streamCtx == nil || streamCtx.Err() != context.Canceled {
+			t.Errorf("%s: stream context was not cancelled", test.name)
+		}
+	}
+}
+
+func benchmarkRowIterator(b *testing.B, next func(iter *RowIterator) error) {
+	const numResultSets = 100
+	const numRowsPerResultSet = 10
+	prs := make([]*sppb.PartialResultSet, numResultSets)
+	for i := range prs {
+		prs[i] = &sppb.PartialResultSet{}
+		if i == 0 {
+			prs[i].Metadata = kvMeta
+		}
+		for j := 0; j < numRowsPerResultSet; j++ {
+			k := i*numRowsPerResultSet + j
+			prs[i].Values = append(prs[i].Values, stringProto(keyStr(k)), stringProto(valStr(k)))
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		iter := stream(context.Background(), nil,
+			func(ct context.Context, resumeToken []byte) (streamingReceiver, error) {
+				return &prsReceiver{prs: prs}, nil
+			},
+			nil,
+			func(error) {})
+		for {
+			err := next(iter)
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		iter.Stop()
+	}
+}
+
+func BenchmarkRowIteratorNext(b *testing.B) {
+	benchmarkRowIterator(b, func(iter *RowIterator) error {
+		_, err := iter.Next()
+		return err
+	})
+}
+
+func BenchmarkRowIteratorNextInto(b *testing.B) {
+	var row Row
+	benchmarkRowIterator(b, func(iter *RowIterator) error {
+		return iter.NextInto(&row)
+	})
+}
@@ -40,38 +40,106 @@ var DefaultRetryBackoff = gax.Backoff{
 	Multiplier: 1.3,
 }
 
-// spannerRetryer extends the generic gax Retryer, but also checks for any
-// retry info returned by Cloud Spanner and uses that if present.
+// RetryOperation is the kind of operation that is retried by the client.
+type RetryOperation int
+
+const (
+	// RetryOperationCreateSession is the creation of sessions for the session
+	// pool.
+	RetryOperationCreateSession RetryOperation = iota
+	// RetryOperationStream is a streaming read or query. A stream that fails
+	// with a retryable error is resumed from the last resume token.
+	RetryOperationStream
+	// RetryOperationTransaction is a transaction that is retried as a whole,
+	// such as a read/write transaction, a partitioned DML statement, or the
+	// commit of Apply with ApplyAtLeastOnce.
+	RetryOperationTransaction
+)
+
+// RetryClassifier determines which errors are retried by the client. Set
+// ClientConfig.RetryClassifier to use a custom RetryClassifier.
+//
+// 'Session not found' errors are always retried, regardless of the
+// RetryClassifier. The delay between retries is the retry delay that is
+// returned by Cloud Spanner, or if none is returned, the delay that is
+// calculated with exponential backoff.
+type RetryClassifier interface {
+	// IsRetryable reports whether an operation that failed with err should be
+	// retried.
+	IsRetryable(op RetryOperation, err error) bool
+}
+
+// DefaultRetryClassifier is the RetryClassifier that is used when
+// ClientConfig.RetryClassifier is not set. A custom RetryClassifier can use
+// DefaultRetryClassifier for the errors that it does not classify itself.
+//
+// DefaultRetryClassifier retries Unavailable and ResourceExhausted errors for
+// session creation, Unavailable, ResourceExhausted and Internal errors for
+// streams, and Aborted and Internal errors for transactions. Internal errors
+// are only retried if they are caused by a known transient network error.
+type DefaultRetryClassifier struct{}
+
+// IsRetryable implements RetryClassifier.IsRetryable.
+func (DefaultRetryClassifier) IsRetryable(op RetryOperation, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return op == RetryOperationCreateSession || op == RetryOperationStream
+	case codes.ResourceExhausted:
+		// A message that exceeds the maximum receive message size of the
+		// client will also exceed it when the request is retried. gRPC
+		// returns the error "grpc: received message larger than max (%d vs.
+		// %d)" for these messages, see recv in grpc-go's rpc_util.go.
+		// TestDefaultRetryClassifier_ReceivedMessageTooLarge fails if the
+		// text of this error changes.
+		if strings.Contains(err.Error(), "received message larger than max") {
+			return false
+		}
+		return op == RetryOperationCreateSession || op == RetryOperationStream
+	case codes.Aborted:
+		return op == RetryOperationTransaction
+	case codes.Internal:
+		if op == RetryOperationCreateSession {
+			return false
+		}
+		return strings.Contains(err.Error(), "stream terminated by RST_STREAM") ||
+			// See b/25451313.
+			strings.Contains(err.Error(), "HTTP/2 error code: INTERNAL_ERROR") ||
+			// See b/27794742.
+			strings.Contains(err.Error(), "Connection closed with unknown cause") ||
+			strings.Contains(err.Error(), "Received unexpected EOS on DATA frame from server")
+	}
+	return false
+}
+
+// spannerRetryer is a gax Retryer that retries the errors that are retryable
+// according to a RetryClassifier. It also checks for any retry info returned
+// by Cloud Spanner and uses that if present.
 type spannerRetryer struct {
-	gax.Retryer
+	classifier RetryClassifier
+	op         RetryOperation
+	backoff    gax.Backoff
 }
 
-// onCodes returns a spannerRetryer that will retry on the specified error
-// codes. For Internal errors, only errors that have one of a list of known
-// descriptions should be retried.
-func onCodes(bo gax.Backoff, cc ...codes.Code) gax.Retryer {
+// newRetryer returns a spannerRetryer for the given operation that uses the
+// given RetryClassifier, or DefaultRetryClassifier if rc is nil.
+func newRetryer(rc RetryClassifier, op RetryOperation, bo gax.Backoff) gax.Retryer {
+	if rc == nil {
+		rc = DefaultRetryClassifier{}
+	}
 	return &spannerRetryer{
-		Retryer: gax.OnCodes(cc, bo),
+		classifier: rc,
+		op:         op,
+		backoff:    bo,
 	}
 }
 
 // Retry returns the retry delay returned by Cloud Spanner if that is present.
-// Otherwise it returns the retry delay calculated by the generic gax Retryer.
+// Otherwise it returns the retry delay calculated by the backoff.
 func (r *spannerRetryer) Retry(err error) (time.Duration, bool) {
-	if status.Code(err) == codes.Internal &&
-		!strings.Contains(err.Error(), "stream terminated by RST_STREAM") &&
-		// See b/25451313.
-		!strings.Contains(err.Error(), "HTTP/2 error code: INTERNAL_ERROR") &&
-		// See b/27794742.
-		!strings.Contains(err.Error(), "Connection closed with unknown cause") &&
-		!strings.Contains(err.Error(), "Received unexpected EOS on DATA frame from server") {
-		return 0, false
-	}
-
-	delay, shouldRetry := r.Retryer.Retry(err)
-	if !shouldRetry {
+	if !r.classifier.IsRetryable(r.op, err) {
 		return 0, false
 	}
+	delay := r.backoff.Pause()
 	if serverDelay, hasServerDelay := ExtractRetryDelay(err); hasServerDelay {
 		delay = serverDelay
 	}
@@ -82,10 +150,12 @@ func (r *spannerRetryer) Retry(err error) (time.Duration, bool) {
 // retries it if it returns an Aborted, Session not found error or certain Internal errors. The retry
 // is delayed if the error was Aborted or Internal error. The delay between retries is the delay
 // returned by Cloud Spanner, or if none is returned, the calculated delay with
-// a minimum of 10ms and maximum of 32s. There is no delay before the retry if
-// the error was Session not found or failed inline begin transaction.
-func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Context, f func(context.Context) error) error {
-	retryer := onCodes(DefaultRetryBackoff, codes.Aborted, codes.Internal)
+// a minimum of 10ms and maximum of 32s. The delay is capped at maxDelay if
+// maxDelay is positive. There is no delay before the retry if the error was
+// Session not found or failed inline begin transaction. Other errors are
+// retried if rc classifies them as retryable for RetryOperationTransaction.
+func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Context, maxDelay time.Duration, rc RetryClassifier, f func(context.Context) error) error {
+	retryer := newRetryer(rc, RetryOperationTransaction, DefaultRetryBackoff)
 	funcWithRetry := func(ctx context.Context) error {
 		for {
 			err := f(ctx)
@@ -122,6 +192,9 @@ func runWithRetryOnAbortedOrFailedInlineBeginOrSessionNotFound(ctx context.Conte
 			if !shouldRetry {
 				return err
 			}
+			if maxDelay > 0 && delay > maxDelay {
+				delay = maxDelay
+			}
 			trace.TracePrintf(ctx, nil, "Backing off after ABORTED for %s, then retrying", delay)
 			if err := gax.Sleep(ctx, delay); err != nil {
 				return err
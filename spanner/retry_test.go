@@ -18,14 +18,18 @@ package spanner
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	. "cloud.google.com/go/spanner/internal/testutil"
 	"github.com/googleapis/gax-go/v2"
 	edpb "google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestRetryInfo(t *testing.T) {
@@ -79,7 +83,7 @@ func TestRetryerRespectsServerDelay(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error setting retry details: %v", err)
 	}
-	retryer := onCodes(gax.Backoff{}, codes.Aborted)
+	retryer := newRetryer(DefaultRetryClassifier{}, RetryOperationTransaction, gax.Backoff{})
 	err = toSpannerErrorWithCommitInfo(s.Err(), true)
 	maxSeenDelay, shouldRetry := retryer.Retry(err)
 	if !shouldRetry {
@@ -89,3 +93,159 @@ func TestRetryerRespectsServerDelay(t *testing.T) {
 		t.Fatalf("Retry delay mismatch:\ngot: %v\nwant: %v", maxSeenDelay, serverDelay)
 	}
 }
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name string
+		err  error
+		want map[RetryOperation]bool
+	}{
+		{"unavailable", status.Error(codes.Unavailable, "unavailable"), map[RetryOperation]bool{RetryOperationCreateSession: true, RetryOperationStream: true}},
+		{"resource exhausted", status.Error(codes.ResourceExhausted, "exhausted"), map[RetryOperation]bool{RetryOperationCreateSession: true, RetryOperationStream: true}},
+		{"message too large", status.Error(codes.ResourceExhausted, "grpc: received message larger than max (100 vs. 10)"), nil},
+		{"aborted", status.Error(codes.Aborted, "aborted"), map[RetryOperation]bool{RetryOperationTransaction: true}},
+		{"rst stream", status.Error(codes.Internal, "stream terminated by RST_STREAM"), map[RetryOperation]bool{RetryOperationStream: true, RetryOperationTransaction: true}},
+		{"other internal", status.Error(codes.Internal, "internal"), nil},
+		{"invalid argument", status.Error(codes.InvalidArgument, "invalid"), nil},
+		{"wrapped aborted", ToSpannerError(status.Error(codes.Aborted, "aborted")), map[RetryOperation]bool{RetryOperationTransaction: true}},
+	} {
+		for _, op := range []RetryOperation{RetryOperationCreateSession, RetryOperationStream, RetryOperationTransaction} {
+			if g, w := (DefaultRetryClassifier{}).IsRetryable(op, test.err), test.want[op]; g != w {
+				t.Errorf("%s: retryable mismatch for operation %v\nGot: %v\nWant: %v", test.name, op, g, w)
+			}
+		}
+	}
+}
+
+// noRetryClassifier is a RetryClassifier that does not retry any errors.
+type noRetryClassifier struct{}
+
+func (noRetryClassifier) IsRetryable(RetryOperation, error) bool {
+	return false
+}
+
+func TestDefaultRetryClassifier_ReceivedMessageTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// The error is returned by gRPC, and DefaultRetryClassifier recognizes it
+	// by its message.
+	sql := "SELECT Value FROM LargeValues"
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		MaxReceiveMessageSize: 16 * 1024,
+		RetryClassifier:       noRetryClassifier{},
+	})
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult(sql, &StatementResult{
+		Type: StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{
+			Metadata: &sppb.ResultSetMetadata{
+				RowType: &sppb.StructType{Fields: []*sppb.StructType_Field{{Name: "Value", Type: &sppb.Type{Code: sppb.TypeCode_STRING}}}},
+			},
+			Rows: []*structpb.ListValue{{Values: []*structpb.Value{structpb.NewStringValue(strings.Repeat("a", 64*1024))}}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	iter := client.Single().Query(context.Background(), NewStatement(sql))
+	defer iter.Stop()
+	_, err := iter.Next()
+	if g, w := ErrCode(err), codes.ResourceExhausted; g != w {
+		t.Fatalf("error code mismatch\nGot: %v (%v)\nWant: %v", g, err, w)
+	}
+	if (DefaultRetryClassifier{}).IsRetryable(RetryOperationStream, err) {
+		t.Fatalf("message too large error is retryable: %v", err)
+	}
+}
+
+// dataLossRetryClassifier is a RetryClassifier that retries DataLoss errors
+// for one operation, and uses DefaultRetryClassifier for all other errors.
+type dataLossRetryClassifier struct {
+	op RetryOperation
+}
+
+func (c dataLossRetryClassifier) IsRetryable(op RetryOperation, err error) bool {
+	if op == c.op && status.Code(err) == codes.DataLoss {
+		return true
+	}
+	return DefaultRetryClassifier{}.IsRetryable(op, err)
+}
+
+func TestRetryClassifier_Stream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		RetryClassifier: dataLossRetryClassifier{op: RetryOperationStream},
+	})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodExecuteStreamingSql, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.DataLoss, "data loss")},
+	})
+	var count int
+	if err := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)).Do(func(r *Row) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := count, 3; g != w {
+		t.Fatalf("row count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var executes int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if _, ok := req.(*sppb.ExecuteSqlRequest); ok {
+			executes++
+		}
+	}
+	if g, w := executes, 2; g != w {
+		t.Fatalf("ExecuteSqlRequest count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestRetryClassifier_Transaction(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		RetryClassifier: dataLossRetryClassifier{op: RetryOperationTransaction},
+	})
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.DataLoss, "data loss")},
+	})
+	var attempts int
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestRetryClassifier_DefaultDoesNotRetryDataLoss(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.DataLoss, "data loss")},
+	})
+	var attempts int
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	if g, w := ErrCode(err), codes.DataLoss; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := attempts, 1; g != w {
+		t.Fatalf("attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
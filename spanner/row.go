@@ -23,6 +23,7 @@ import (
 
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
 	proto3 "google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -55,13 +56,17 @@ import (
 //	*[][]byte - BYTES ARRAY
 //	*int64(not NULL), *NullInt64 - INT64
 //	*[]int64, *[]NullInt64 - INT64 ARRAY
+//	*time.Duration(not NULL), *NullDuration - INT64, as nanoseconds
+//	*[]time.Duration, *[]NullDuration - INT64 ARRAY, as nanoseconds
 //	*bool(not NULL), *NullBool - BOOL
 //	*[]bool, *[]NullBool - BOOL ARRAY
 //	*float32(not NULL), *NullFloat32 - FLOAT32
 //	*[]float32, *[]NullFloat32 - FLOAT32 ARRAY
-//	*float64(not NULL), *NullFloat64 - FLOAT64
-//	*[]float64, *[]NullFloat64 - FLOAT64 ARRAY
+//	*float64(not NULL), *NullFloat64 - FLOAT64, FLOAT32
+//	*[]float64, *[]NullFloat64 - FLOAT64 ARRAY, FLOAT32 ARRAY
 //	*big.Rat(not NULL), *NullNumeric - NUMERIC
+//	*big.Int(not NULL), **big.Int - NUMERIC, the value must be an integer
+//	*Numeric(not NULL) - NUMERIC, in the exact textual form of the value
 //	*[]big.Rat, *[]NullNumeric - NUMERIC ARRAY
 //	*time.Time(not NULL), *NullTime - TIMESTAMP
 //	*[]time.Time, *[]NullTime - TIMESTAMP ARRAY
@@ -71,6 +76,8 @@ import (
 //	*NullJSON - JSON
 //	*[]NullJSON - JSON ARRAY
 //	*GenericColumnValue - any Cloud Spanner type
+//	*interface{} - NUMERIC, see NumericDecodeMode for the Go type of the value
+//	ColumnDecoder, Decoder - any Cloud Spanner type, decoded by the custom type
 //
 // For TIMESTAMP columns, the returned time.Time object will be in UTC.
 //
@@ -91,6 +98,90 @@ import (
 type Row struct {
 	fields []*sppb.StructType_Field
 	vals   []*proto3.Value // keep decoded for now
+	// cfg contains the settings of the read or query that returned the row.
+	// It is shared by all rows of a result set, and nil for the rows that are
+	// created by the client.
+	cfg *rowConfig
+}
+
+// rowConfig contains the settings of the rows of a read or query that are
+// determined by the client and the query options.
+type rowConfig struct {
+	// numericDecodeMode determines the Go type that is used for NUMERIC
+	// columns that are decoded into an *interface{}.
+	numericDecodeMode NumericDecodeMode
+	// ignoreUnmappedCols makes ToStruct ignore the columns that do not map
+	// to a struct field, see ClientConfig.IgnoreUnmappedColumns.
+	ignoreUnmappedCols bool
+	// duplicateColumnPolicy determines how ColumnIndex handles column names
+	// that occur more than once in the row.
+	duplicateColumnPolicy DuplicateColumnPolicy
+}
+
+// config returns the rowConfig of the row.
+func (r *Row) config() rowConfig {
+	if r.cfg == nil {
+		return rowConfig{}
+	}
+	return *r.cfg
+}
+
+// DuplicateColumnPolicy determines how the rows of a query handle columns
+// with the same name, for example the columns of a join of two tables that
+// both contain an id column.
+type DuplicateColumnPolicy int
+
+const (
+	// DuplicateColumnError makes ColumnIndex, ColumnByName and ToStruct
+	// return an error for a column name that occurs more than once in the
+	// row. This is the default.
+	DuplicateColumnError DuplicateColumnPolicy = iota
+	// DuplicateColumnFirstMatch makes ColumnIndex and ColumnByName use the
+	// first column with the given name.
+	DuplicateColumnFirstMatch
+	// DuplicateColumnSuffix renames the second and following occurrences of
+	// a column name by adding a numeric suffix, so that the columns id, id
+	// and id are returned as id, id_1 and id_2. The suffix is increased
+	// further if the renamed column would have the same name as another
+	// column of the row. Columns without a name are not renamed.
+	DuplicateColumnSuffix
+)
+
+// suffixDuplicateColumns returns the given fields with duplicate column names
+// renamed according to DuplicateColumnSuffix. The input is returned as-is if
+// it does not contain any duplicate names.
+func suffixDuplicateColumns(fields []*sppb.StructType_Field) []*sppb.StructType_Field {
+	names := make(map[string]bool, len(fields))
+	dup := false
+	for _, f := range fields {
+		if f.GetName() != "" && names[f.GetName()] {
+			dup = true
+		}
+		names[f.GetName()] = true
+	}
+	if !dup {
+		return fields
+	}
+	used := make(map[string]bool, len(fields))
+	renamed := make([]*sppb.StructType_Field, len(fields))
+	for i, f := range fields {
+		name := f.GetName()
+		if name == "" || !used[name] {
+			used[name] = true
+			renamed[i] = f
+			continue
+		}
+		for n := 1; ; n++ {
+			candidate := fmt.Sprintf("%s_%d", name, n)
+			if !used[candidate] && !names[candidate] {
+				name = candidate
+				break
+			}
+		}
+		used[name] = true
+		renamed[i] = &sppb.StructType_Field{Name: name, Type: f.GetType()}
+	}
+	return renamed
 }
 
 // String implements fmt.stringer.
@@ -158,6 +249,9 @@ func (r *Row) ColumnIndex(name string) (int, error) {
 			if found {
 				return 0, errDupColName(name)
 			}
+			if r.config().duplicateColumnPolicy == DuplicateColumnFirstMatch {
+				return i, nil
+			}
 			found = true
 			index = i
 		}
@@ -236,9 +330,90 @@ func (r *Row) Column(i int, ptr interface{}) error {
 	if r.fields[i] == nil {
 		return errNilColType(i)
 	}
-	if err := decodeValue(r.vals[i], r.fields[i].Type, ptr); err != nil {
+	var opts []DecodeOptions
+	if mode := r.config().numericDecodeMode; mode != NumericDecodeModeDefault {
+		opts = append(opts, withNumericDecodeMode{mode: mode})
+	}
+	if err := decodeValue(r.vals[i], r.fields[i].Type, ptr, opts...); err != nil {
+		return errDecodeColumn(i, err)
+	}
+	return nil
+}
+
+// ColumnProto fetches the value of the PROTO column i and unmarshals it into
+// m. It returns an error if the column is not of type PROTO, if the
+// fully-qualified name of the proto type of the column is not the name of the
+// message type of m, or if the value is NULL. Use Column with a
+// NullProtoMessage to read a column that can contain NULL values.
+func (r *Row) ColumnProto(i int, m proto.Message) error {
+	if len(r.vals) != len(r.fields) {
+		return errFieldsMismatchVals(r)
+	}
+	if i < 0 || i >= len(r.fields) {
+		return errColIdxOutOfRange(i, r)
+	}
+	if r.fields[i] == nil {
+		return errNilColType(i)
+	}
+	if err := (GenericColumnValue{Type: r.fields[i].Type, Value: r.vals[i]}).DecodeProto(m); err != nil {
+		return errDecodeColumn(i, err)
+	}
+	return nil
+}
+
+// ColumnNumericString returns the value of the NUMERIC or PostgreSQL NUMERIC
+// column i in the exact textual form that was returned by Cloud Spanner, see
+// Numeric. It returns an error if the column has a different type, or if the
+// value is NULL.
+func (r *Row) ColumnNumericString(i int) (string, error) {
+	var n Numeric
+	if err := r.Column(i, &n); err != nil {
+		return "", err
+	}
+	return string(n), nil
+}
+
+// errNotArrayColumn returns error for a column that is not an ARRAY column.
+func errNotArrayColumn(i int, t *sppb.Type) error {
+	return spannerErrorf(codes.InvalidArgument, "column %d is of type %v, not ARRAY", i, t.GetCode())
+}
+
+// ColumnStream calls fn once for each element of the ARRAY column i, in the
+// order of the array. Each element is passed to fn as a GenericColumnValue
+// with the element type of the array, without decoding the other elements
+// of the array into Go values. This allows large arrays, for example of type
+// ARRAY<STRUCT<...>>, to be processed one element at a time. NULL elements
+// are passed as a GenericColumnValue with a NullValue.
+//
+// fn is not called if the value of the column is NULL. If fn returns an
+// error, ColumnStream stops and returns that error. ColumnStream returns an
+// error if column i is not an ARRAY column.
+func (r *Row) ColumnStream(i int, fn func(GenericColumnValue) error) error {
+	if len(r.vals) != len(r.fields) {
+		return errFieldsMismatchVals(r)
+	}
+	if i < 0 || i >= len(r.fields) {
+		return errColIdxOutOfRange(i, r)
+	}
+	if r.fields[i] == nil {
+		return errNilColType(i)
+	}
+	t := r.fields[i].Type
+	if t.GetCode() != sppb.TypeCode_ARRAY {
+		return errNotArrayColumn(i, t)
+	}
+	if _, isNull := r.vals[i].GetKind().(*proto3.Value_NullValue); isNull {
+		return nil
+	}
+	lv, err := getListValue(r.vals[i])
+	if err != nil {
 		return errDecodeColumn(i, err)
 	}
+	for _, v := range lv.Values {
+		if err := fn(GenericColumnValue{Type: t.ArrayElementType, Value: v}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -274,6 +449,23 @@ func (r *Row) ColumnByName(name string, ptr interface{}) error {
 	return r.Column(index, ptr)
 }
 
+// ColumnByNameOpt fetches the value from the named column, decoding it into
+// ptr, if the column is present in the row. It returns false without an error
+// if the row does not contain a column with the given name. This makes it
+// possible to distinguish a column that is absent from the result from a
+// column that contains a NULL value. See the Row documentation for the list of
+// acceptable argument types.
+func (r *Row) ColumnByNameOpt(name string, ptr interface{}) (present bool, err error) {
+	index, err := r.ColumnIndex(name)
+	if err != nil {
+		if ErrCode(err) == codes.NotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, r.Column(index, ptr)
+}
+
 // errNumOfColValue returns error for providing wrong number of values to Columns.
 func errNumOfColValue(n int, r *Row) error {
 	return spannerErrorf(codes.InvalidArgument,
@@ -323,7 +515,15 @@ func errToStructArgType(p interface{}) error {
 //
 //  3. The number of columns in the row must match the number of exported fields in the struct.
 //     There must be exactly one match for each column in the row. The method will return an error
-//     if a column in the row cannot be assigned to a field in the struct.
+//     if a column in the row cannot be assigned to a field in the struct, unless the row was
+//     returned by a Client with ClientConfig.IgnoreUnmappedColumns, in which case ToStruct
+//     decodes the row in the same way as ToStructLenient.
+//
+//  4. If the struct has a field of type map[string]spanner.GenericColumnValue
+//     with the `spanner:",remainder"` tag, then the columns in the row that do
+//     not match any other field are added to that map, keyed by column name,
+//     instead of returning an error. The remainder field does not count as an
+//     exported field for rule 3. The map is allocated if it is nil.
 //
 // The fields of the destination struct can be of any type that is acceptable
 // to spanner.Row.Column.
@@ -348,7 +548,7 @@ func (r *Row) ToStruct(p interface{}) error {
 		&sppb.StructType{Fields: r.fields},
 		&proto3.ListValue{Values: r.vals},
 		p,
-		false,
+		r.config().ignoreUnmappedCols,
 	)
 }
 
@@ -366,6 +566,13 @@ func (r *Row) ToStruct(p interface{}) error {
 //  3. The number of columns in the row and exported fields in the struct do not need to match.
 //     Any field in the struct that cannot not be assigned a value from the row is assigned its default value.
 //     Any column in the row that does not have a corresponding field in the struct is ignored.
+//     A column that does have a corresponding field must still be decodable into that field,
+//     otherwise ToStructLenient returns an error.
+//
+//  4. If the struct has a field of type map[string]spanner.GenericColumnValue
+//     with the `spanner:",remainder"` tag, then the columns in the row that do
+//     not match any other field are added to that map, keyed by column name,
+//     instead of being ignored.
 //
 // The fields of the destination struct can be of any type that is acceptable
 // to spanner.Row.Column.
@@ -411,6 +618,7 @@ func (r *Row) ToStructLenient(p interface{}) error {
 // Both singersByPtr and singersByValue are valid destinations for SelectAll function.
 //
 // Add the option `spanner.WithLenient()` to instruct SelectAll to ignore additional columns in the rows that are not present in the destination struct.
+// Additional columns are also ignored for rows of a Client with ClientConfig.IgnoreUnmappedColumns.
 // example:
 //
 //	var singersByPtr []*Singer
@@ -465,7 +673,7 @@ func SelectAll(rows rowIterator, destination interface{}, options ...DecodeOptio
 			defer func() {
 				isFirstRow = false
 			}()
-			if pointers, err = structPointers(sliceItem.Elem(), row.fields, s.Lenient); err != nil {
+			if pointers, err = structPointers(sliceItem.Elem(), row.fields, s.Lenient || row.config().ignoreUnmappedCols); err != nil {
 				return err
 			}
 		} else if isPrimitive {
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// ColumnDiff is a difference between the values of a column in two rows. It
+// is returned by DiffRows.
+type ColumnDiff struct {
+	// Column is the name of the column.
+	Column string
+	// Old is the value of the column in the first row. Old.Type is nil if
+	// the first row does not contain the column.
+	Old GenericColumnValue
+	// New is the value of the column in the second row. New.Type is nil if
+	// the second row does not contain the column.
+	New GenericColumnValue
+}
+
+// DiffRows compares two rows column by column, matching the columns by name,
+// and returns the columns that differ. Two values are equal if they have the
+// same type and the same encoded value, so that two NULL values of the same
+// type are equal, and values of different types, for example INT64 and
+// STRING, always differ. Columns that are only present in one of the rows are
+// returned with a GenericColumnValue with a nil Type for the other row.
+//
+// The differences are returned in the column order of the first row, followed
+// by the columns that are only present in the second row. DiffRows returns an
+// error if one of the rows contains multiple columns with the same name.
+func DiffRows(a, b *Row) ([]ColumnDiff, error) {
+	av, err := rowColumnValues(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := rowColumnValues(b)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []ColumnDiff
+	for i, f := range a.fields {
+		old := GenericColumnValue{Type: f.Type, Value: a.vals[i]}
+		nv, ok := bv[f.Name]
+		if !ok || !proto.Equal(old.Type, nv.Type) || !proto.Equal(old.Value, nv.Value) {
+			diffs = append(diffs, ColumnDiff{Column: f.Name, Old: old, New: nv})
+		}
+	}
+	for i, f := range b.fields {
+		if _, ok := av[f.Name]; !ok {
+			diffs = append(diffs, ColumnDiff{Column: f.Name, New: GenericColumnValue{Type: f.Type, Value: b.vals[i]}})
+		}
+	}
+	return diffs, nil
+}
+
+// rowColumnValues returns the values of the columns of a row by column name.
+func rowColumnValues(r *Row) (map[string]GenericColumnValue, error) {
+	if len(r.vals) != len(r.fields) {
+		return nil, errFieldsMismatchVals(r)
+	}
+	values := make(map[string]GenericColumnValue, len(r.fields))
+	for i, f := range r.fields {
+		if f == nil {
+			return nil, errNilColType(i)
+		}
+		if _, ok := values[f.Name]; ok {
+			return nil, errDupColName(f.Name)
+		}
+		values[f.Name] = GenericColumnValue{Type: f.Type, Value: r.vals[i]}
+	}
+	return values, nil
+}
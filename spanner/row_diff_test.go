@@ -0,0 +1,105 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"testing"
+)
+
+func mustNewRow(t *testing.T, names []string, values []interface{}) *Row {
+	t.Helper()
+	r, err := NewRow(names, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestDiffRows_Identical(t *testing.T) {
+	names := []string{"Id", "Name", "Score"}
+	a := mustNewRow(t, names, []interface{}{int64(1), "foo", NullFloat64{}})
+	b := mustNewRow(t, names, []interface{}{int64(1), "foo", NullFloat64{}})
+	diffs, err := DiffRows(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Fatalf("got diffs for identical rows: %v", diffs)
+	}
+}
+
+func TestDiffRows_DifferentValues(t *testing.T) {
+	names := []string{"Id", "Name", "Score", "Count"}
+	a := mustNewRow(t, names, []interface{}{int64(1), "foo", 1.5, int64(10)})
+	b := mustNewRow(t, names, []interface{}{int64(1), "bar", NullFloat64{}, "10"})
+	diffs, err := DiffRows(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.Column)
+	}
+	if want := []string{"Name", "Score", "Count"}; !testEqual(got, want) {
+		t.Fatalf("diff columns mismatch\nGot: %v\nWant: %v", got, want)
+	}
+
+	var oldName, newName string
+	if err := diffs[0].Old.Decode(&oldName); err != nil {
+		t.Fatal(err)
+	}
+	if err := diffs[0].New.Decode(&newName); err != nil {
+		t.Fatal(err)
+	}
+	if oldName != "foo" || newName != "bar" {
+		t.Errorf("Name diff = (%q, %q), want (%q, %q)", oldName, newName, "foo", "bar")
+	}
+	var newScore NullFloat64
+	if err := diffs[1].New.Decode(&newScore); err != nil {
+		t.Fatal(err)
+	}
+	if newScore.Valid {
+		t.Errorf("Score diff new value = %v, want NULL", newScore)
+	}
+	// The same value with a different type is a difference.
+	if g, w := diffs[2].New.Type.Code, stringType().Code; g != w {
+		t.Errorf("Count diff new type mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestDiffRows_DifferentColumns(t *testing.T) {
+	a := mustNewRow(t, []string{"Id", "OnlyA"}, []interface{}{int64(1), "a"})
+	b := mustNewRow(t, []string{"OnlyB", "Id"}, []interface{}{"b", int64(1)})
+	diffs, err := DiffRows(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(diffs), 2; g != w {
+		t.Fatalf("diff count mismatch\nGot: %v (%v)\nWant: %v", g, diffs, w)
+	}
+	if d := diffs[0]; d.Column != "OnlyA" || d.Old.Type == nil || d.New.Type != nil {
+		t.Errorf("diff for column only in first row = %v", d)
+	}
+	if d := diffs[1]; d.Column != "OnlyB" || d.Old.Type != nil || d.New.Type == nil {
+		t.Errorf("diff for column only in second row = %v", d)
+	}
+
+	dup := mustNewRow(t, []string{"Id", "Id"}, []interface{}{int64(1), int64(2)})
+	if _, err := DiffRows(a, dup); err == nil {
+		t.Error("DiffRows for duplicate column names returned no error")
+	}
+}
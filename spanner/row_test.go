@@ -20,6 +20,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
@@ -29,8 +30,10 @@ import (
 	"cloud.google.com/go/civil"
 	"cloud.google.com/go/internal/testutil"
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	pb "cloud.google.com/go/spanner/testdata/protos"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
 	proto "google.golang.org/protobuf/proto"
 	proto3 "google.golang.org/protobuf/types/known/structpb"
 )
@@ -40,7 +43,7 @@ var (
 	dt, _ = civil.ParseDate("2016-11-15")
 	// row contains a column for each unique Cloud Spanner type.
 	row = Row{
-		[]*sppb.StructType_Field{
+		fields: []*sppb.StructType_Field{
 			// STRING / STRING ARRAY
 			{Name: "STRING", Type: stringType()},
 			{Name: "NULL_STRING", Type: stringType()},
@@ -106,7 +109,7 @@ var (
 				),
 			},
 		},
-		[]*proto3.Value{
+		vals: []*proto3.Value{
 			// STRING / STRING ARRAY
 			stringProto("value"),
 			nullProto(),
@@ -302,10 +305,10 @@ func TestNilDst(t *testing.T) {
 	}{
 		{
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: stringType()},
 				},
-				[]*proto3.Value{stringProto("value")},
+				vals: []*proto3.Value{stringProto("value")},
 			},
 			nil,
 			errDecodeColumn(0, errNilDst(nil)),
@@ -314,10 +317,10 @@ func TestNilDst(t *testing.T) {
 		},
 		{
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: stringType()},
 				},
-				[]*proto3.Value{stringProto("value")},
+				vals: []*proto3.Value{stringProto("value")},
 			},
 			(*string)(nil),
 			errDecodeColumn(0, errNilDst((*string)(nil))),
@@ -326,7 +329,7 @@ func TestNilDst(t *testing.T) {
 		},
 		{
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -338,7 +341,7 @@ func TestNilDst(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{listProto(
+				vals: []*proto3.Value{listProto(
 					listProto(intProto(3), floatProto(33.3), float32Proto(0.3)),
 				)},
 			},
@@ -515,11 +518,11 @@ func TestInvalidColumnRequest(t *testing.T) {
 			func() error {
 				var s string
 				r := &Row{
-					[]*sppb.StructType_Field{
+					fields: []*sppb.StructType_Field{
 						{Name: "Val", Type: stringType()},
 						{Name: "Val", Type: stringType()},
 					},
-					[]*proto3.Value{stringProto("value1"), stringProto("value2")},
+					vals: []*proto3.Value{stringProto("value1"), stringProto("value2")},
 				}
 				return r.ColumnByName("Val", &s)
 			},
@@ -532,11 +535,11 @@ func TestInvalidColumnRequest(t *testing.T) {
 					Val string
 				}{}
 				r := &Row{
-					[]*sppb.StructType_Field{
+					fields: []*sppb.StructType_Field{
 						{Name: "Val", Type: stringType()},
 						{Name: "Val", Type: stringType()},
 					},
-					[]*proto3.Value{stringProto("value1"), stringProto("value2")},
+					vals: []*proto3.Value{stringProto("value1"), stringProto("value2")},
 				}
 				return r.ToStruct(s)
 			},
@@ -554,10 +557,10 @@ func TestInvalidColumnRequest(t *testing.T) {
 					Val string
 				}{}
 				r := &Row{
-					[]*sppb.StructType_Field{
+					fields: []*sppb.StructType_Field{
 						{Name: "", Type: stringType()},
 					},
-					[]*proto3.Value{stringProto("value1")},
+					vals: []*proto3.Value{stringProto("value1")},
 				}
 				return r.ToStruct(s)
 			},
@@ -572,11 +575,11 @@ func TestInvalidColumnRequest(t *testing.T) {
 					Val string
 				}{}
 				r := &Row{
-					[]*sppb.StructType_Field{
+					fields: []*sppb.StructType_Field{
 						{Name: "Val", Type: stringType()},
 						{Name: "Val", Type: stringType()},
 					},
-					[]*proto3.Value{stringProto("value1"), stringProto("value2")},
+					vals: []*proto3.Value{stringProto("value1"), stringProto("value2")},
 				}
 				return r.ToStructLenient(s)
 			},
@@ -594,10 +597,10 @@ func TestInvalidColumnRequest(t *testing.T) {
 					Val string
 				}{}
 				r := &Row{
-					[]*sppb.StructType_Field{
+					fields: []*sppb.StructType_Field{
 						{Name: "", Type: stringType()},
 					},
-					[]*proto3.Value{stringProto("value1")},
+					vals: []*proto3.Value{stringProto("value1")},
 				}
 				return r.ToStructLenient(s)
 			},
@@ -721,20 +724,20 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// A row with no field.
 			&Row{
-				[]*sppb.StructType_Field{},
-				[]*proto3.Value{stringProto("value")},
+				fields: []*sppb.StructType_Field{},
+				vals:   []*proto3.Value{stringProto("value")},
 			},
 			&NullString{"value", true},
 			errFieldsMismatchVals(&Row{
-				[]*sppb.StructType_Field{},
-				[]*proto3.Value{stringProto("value")},
+				fields: []*sppb.StructType_Field{},
+				vals:   []*proto3.Value{stringProto("value")},
 			}),
 		},
 		{
 			// A row with nil field.
 			&Row{
-				[]*sppb.StructType_Field{nil},
-				[]*proto3.Value{stringProto("value")},
+				fields: []*sppb.StructType_Field{nil},
+				vals:   []*proto3.Value{stringProto("value")},
 			},
 			&NullString{"value", true},
 			errNilColType(0),
@@ -742,10 +745,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field is not nil, but its type is nil.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: nil},
 				},
-				[]*proto3.Value{listProto(stringProto("value1"), stringProto("value2"))},
+				vals: []*proto3.Value{listProto(stringProto("value1"), stringProto("value2"))},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errNilSpannerType()),
@@ -753,10 +756,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field is not nil, field type is not nil, but it is an array and its array element type is nil.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: &sppb.Type{Code: sppb.TypeCode_ARRAY}},
 				},
-				[]*proto3.Value{listProto(stringProto("value1"), stringProto("value2"))},
+				vals: []*proto3.Value{listProto(stringProto("value1"), stringProto("value2"))},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errNilArrElemType(&sppb.Type{Code: sppb.TypeCode_ARRAY})),
@@ -764,10 +767,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies valid type, value is nil.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: intType()},
 				},
-				[]*proto3.Value{nil},
+				vals: []*proto3.Value{nil},
 			},
 			&NullInt64{1, true},
 			errDecodeColumn(0, errNilSrc()),
@@ -775,10 +778,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies INT64 type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: intType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
 			},
 			&NullInt64{1, true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_StringValue)(nil)}, "String")),
@@ -786,10 +789,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies INT64 type, but value is for Number type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: intType()},
 				},
-				[]*proto3.Value{floatProto(1.0)},
+				vals: []*proto3.Value{floatProto(1.0)},
 			},
 			&NullInt64{1, true},
 			errDecodeColumn(0, errSrcVal(floatProto(1.0), "String")),
@@ -797,10 +800,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies INT64 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: intType()},
 				},
-				[]*proto3.Value{stringProto("&1")},
+				vals: []*proto3.Value{stringProto("&1")},
 			},
 			proto.Int64(0),
 			errDecodeColumn(0, errBadEncoding(stringProto("&1"), func() error {
@@ -811,10 +814,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies INT64 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: intType()},
 				},
-				[]*proto3.Value{stringProto("&1")},
+				vals: []*proto3.Value{stringProto("&1")},
 			},
 			&NullInt64{},
 			errDecodeColumn(0, errBadEncoding(stringProto("&1"), func() error {
@@ -825,10 +828,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies STRING type, but value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: stringType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
 			},
 			&NullString{"value", true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_StringValue)(nil)}, "String")),
@@ -836,10 +839,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies STRING type, but value is for ARRAY type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: stringType()},
 				},
-				[]*proto3.Value{listProto(stringProto("value"))},
+				vals: []*proto3.Value{listProto(stringProto("value"))},
 			},
 			&NullString{"value", true},
 			errDecodeColumn(0, errSrcVal(listProto(stringProto("value")), "String")),
@@ -847,10 +850,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT64 type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: floatType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_NumberValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_NumberValue)(nil)}},
 			},
 			&NullFloat64{1.0, true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_NumberValue)(nil)}, "Number")),
@@ -858,10 +861,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT64 type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: floatType()},
 				},
-				[]*proto3.Value{boolProto(true)},
+				vals: []*proto3.Value{boolProto(true)},
 			},
 			&NullFloat64{1.0, true},
 			errDecodeColumn(0, errSrcVal(boolProto(true), "Number")),
@@ -869,10 +872,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT64 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: floatType()},
 				},
-				[]*proto3.Value{stringProto("nan")},
+				vals: []*proto3.Value{stringProto("nan")},
 			},
 			&NullFloat64{},
 			errDecodeColumn(0, errUnexpectedFloat64Str("nan")),
@@ -880,10 +883,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT64 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: floatType()},
 				},
-				[]*proto3.Value{stringProto("nan")},
+				vals: []*proto3.Value{stringProto("nan")},
 			},
 			proto.Float64(0),
 			errDecodeColumn(0, errUnexpectedFloat64Str("nan")),
@@ -891,10 +894,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT32 type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: float32Type()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_NumberValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_NumberValue)(nil)}},
 			},
 			&NullFloat32{1.0, true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_NumberValue)(nil)}, "Number")),
@@ -902,10 +905,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT32 type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: float32Type()},
 				},
-				[]*proto3.Value{boolProto(true)},
+				vals: []*proto3.Value{boolProto(true)},
 			},
 			&NullFloat32{1.0, true},
 			errDecodeColumn(0, errSrcVal(boolProto(true), "Number")),
@@ -913,10 +916,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT32 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: float32Type()},
 				},
-				[]*proto3.Value{stringProto("nan")},
+				vals: []*proto3.Value{stringProto("nan")},
 			},
 			&NullFloat32{},
 			errDecodeColumn(0, errUnexpectedFloat32Str("nan")),
@@ -924,10 +927,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies FLOAT32 type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: float32Type()},
 				},
-				[]*proto3.Value{stringProto("nan")},
+				vals: []*proto3.Value{stringProto("nan")},
 			},
 			proto.Float32(0),
 			errDecodeColumn(0, errUnexpectedFloat32Str("nan")),
@@ -935,10 +938,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies BYTES type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: bytesType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
 			},
 			&[]byte{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_StringValue)(nil)}, "String")),
@@ -946,10 +949,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies BYTES type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: bytesType()},
 				},
-				[]*proto3.Value{boolProto(false)},
+				vals: []*proto3.Value{boolProto(false)},
 			},
 			&[]byte{},
 			errDecodeColumn(0, errSrcVal(boolProto(false), "String")),
@@ -957,10 +960,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies BYTES type, but value is wrongly encoded.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: bytesType()},
 				},
-				[]*proto3.Value{stringProto("&&")},
+				vals: []*proto3.Value{stringProto("&&")},
 			},
 			&[]byte{},
 			errDecodeColumn(0, errBadEncoding(stringProto("&&"), func() error {
@@ -971,10 +974,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies BOOL type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: boolType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_BoolValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_BoolValue)(nil)}},
 			},
 			&NullBool{false, true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_BoolValue)(nil)}, "Bool")),
@@ -982,10 +985,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies BOOL type, but value is for STRING type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: boolType()},
 				},
-				[]*proto3.Value{stringProto("false")},
+				vals: []*proto3.Value{stringProto("false")},
 			},
 			&NullBool{false, true},
 			errDecodeColumn(0, errSrcVal(stringProto("false"), "Bool")),
@@ -993,10 +996,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies TIMESTAMP type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: timeType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
 			},
 			&NullTime{time.Now(), true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_StringValue)(nil)}, "String")),
@@ -1004,10 +1007,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies TIMESTAMP type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: timeType()},
 				},
-				[]*proto3.Value{boolProto(false)},
+				vals: []*proto3.Value{boolProto(false)},
 			},
 			&NullTime{time.Now(), true},
 			errDecodeColumn(0, errSrcVal(boolProto(false), "String")),
@@ -1015,10 +1018,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies TIMESTAMP type, but value is invalid timestamp.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: timeType()},
 				},
-				[]*proto3.Value{stringProto("junk")},
+				vals: []*proto3.Value{stringProto("junk")},
 			},
 			&NullTime{time.Now(), true},
 			errDecodeColumn(0, errBadEncoding(stringProto("junk"), func() error {
@@ -1029,10 +1032,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies DATE type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: dateType()},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_StringValue)(nil)}},
 			},
 			&NullDate{civil.Date{}, true},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_StringValue)(nil)}, "String")),
@@ -1040,10 +1043,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies DATE type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: dateType()},
 				},
-				[]*proto3.Value{boolProto(false)},
+				vals: []*proto3.Value{boolProto(false)},
 			},
 			&NullDate{civil.Date{}, true},
 			errDecodeColumn(0, errSrcVal(boolProto(false), "String")),
@@ -1051,10 +1054,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies DATE type, but value is invalid timestamp.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: dateType()},
 				},
-				[]*proto3.Value{stringProto("junk")},
+				vals: []*proto3.Value{stringProto("junk")},
 			},
 			&NullDate{civil.Date{}, true},
 			errDecodeColumn(0, errBadEncoding(stringProto("junk"), func() error {
@@ -1066,10 +1069,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<INT64> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(intType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullInt64{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1077,10 +1080,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<INT64> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(intType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullInt64{},
 			errDecodeColumn(0, errNilListValue("INT64")),
@@ -1088,10 +1091,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<INT64> type, but value is for BYTES type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(intType())},
 				},
-				[]*proto3.Value{bytesProto([]byte("value"))},
+				vals: []*proto3.Value{bytesProto([]byte("value"))},
 			},
 			&[]NullInt64{},
 			errDecodeColumn(0, errSrcVal(bytesProto([]byte("value")), "List")),
@@ -1099,10 +1102,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<INT64> type, but value is for ARRAY<BOOL> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(intType())},
 				},
-				[]*proto3.Value{listProto(boolProto(true))},
+				vals: []*proto3.Value{listProto(boolProto(true))},
 			},
 			&[]NullInt64{},
 			errDecodeColumn(0, errDecodeArrayElement(0, boolProto(true),
@@ -1111,10 +1114,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRING> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(stringType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1122,10 +1125,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRING> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(stringType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errNilListValue("STRING")),
@@ -1133,10 +1136,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRING> type, but value is for BOOL type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(stringType())},
 				},
-				[]*proto3.Value{boolProto(true)},
+				vals: []*proto3.Value{boolProto(true)},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errSrcVal(boolProto(true), "List")),
@@ -1144,10 +1147,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRING> type, but value is for ARRAY<BOOL> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(stringType())},
 				},
-				[]*proto3.Value{listProto(boolProto(true))},
+				vals: []*proto3.Value{listProto(boolProto(true))},
 			},
 			&[]NullString{},
 			errDecodeColumn(0, errDecodeArrayElement(0, boolProto(true),
@@ -1156,10 +1159,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<FLOAT64> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(floatType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullFloat64{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1167,10 +1170,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<FLOAT64> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(floatType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullFloat64{},
 			errDecodeColumn(0, errNilListValue("FLOAT64")),
@@ -1178,10 +1181,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<FLOAT64> type, but value is for STRING type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(floatType())},
 				},
-				[]*proto3.Value{stringProto("value")},
+				vals: []*proto3.Value{stringProto("value")},
 			},
 			&[]NullFloat64{},
 			errDecodeColumn(0, errSrcVal(stringProto("value"), "List")),
@@ -1189,10 +1192,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<FLOAT64> type, but value is for ARRAY<BOOL> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(floatType())},
 				},
-				[]*proto3.Value{listProto(boolProto(true))},
+				vals: []*proto3.Value{listProto(boolProto(true))},
 			},
 			&[]NullFloat64{},
 			errDecodeColumn(0, errDecodeArrayElement(0, boolProto(true),
@@ -1201,10 +1204,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BYTES> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(bytesType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[][]byte{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1212,10 +1215,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BYTES> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(bytesType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[][]byte{},
 			errDecodeColumn(0, errNilListValue("BYTES")),
@@ -1223,10 +1226,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BYTES> type, but value is for FLOAT64 type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(bytesType())},
 				},
-				[]*proto3.Value{floatProto(1.0)},
+				vals: []*proto3.Value{floatProto(1.0)},
 			},
 			&[][]byte{},
 			errDecodeColumn(0, errSrcVal(floatProto(1.0), "List")),
@@ -1234,10 +1237,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BYTES> type, but value is for ARRAY<FLOAT64> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(bytesType())},
 				},
-				[]*proto3.Value{listProto(floatProto(1.0))},
+				vals: []*proto3.Value{listProto(floatProto(1.0))},
 			},
 			&[][]byte{},
 			errDecodeColumn(0, errDecodeArrayElement(0, floatProto(1.0),
@@ -1246,10 +1249,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BOOL> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(boolType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullBool{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1257,10 +1260,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BOOL> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(boolType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullBool{},
 			errDecodeColumn(0, errNilListValue("BOOL")),
@@ -1268,10 +1271,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BOOL> type, but value is for FLOAT64 type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(boolType())},
 				},
-				[]*proto3.Value{floatProto(1.0)},
+				vals: []*proto3.Value{floatProto(1.0)},
 			},
 			&[]NullBool{},
 			errDecodeColumn(0, errSrcVal(floatProto(1.0), "List")),
@@ -1279,10 +1282,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<BOOL> type, but value is for ARRAY<FLOAT64> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(boolType())},
 				},
-				[]*proto3.Value{listProto(floatProto(1.0))},
+				vals: []*proto3.Value{listProto(floatProto(1.0))},
 			},
 			&[]NullBool{},
 			errDecodeColumn(0, errDecodeArrayElement(0, floatProto(1.0),
@@ -1291,10 +1294,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<TIMESTAMP> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(timeType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullTime{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1302,10 +1305,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<TIMESTAMP> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(timeType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullTime{},
 			errDecodeColumn(0, errNilListValue("TIMESTAMP")),
@@ -1313,10 +1316,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<TIMESTAMP> type, but value is for FLOAT64 type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(timeType())},
 				},
-				[]*proto3.Value{floatProto(1.0)},
+				vals: []*proto3.Value{floatProto(1.0)},
 			},
 			&[]NullTime{},
 			errDecodeColumn(0, errSrcVal(floatProto(1.0), "List")),
@@ -1324,10 +1327,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<TIMESTAMP> type, but value is for ARRAY<FLOAT64> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(timeType())},
 				},
-				[]*proto3.Value{listProto(floatProto(1.0))},
+				vals: []*proto3.Value{listProto(floatProto(1.0))},
 			},
 			&[]NullTime{},
 			errDecodeColumn(0, errDecodeArrayElement(0, floatProto(1.0),
@@ -1336,10 +1339,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<DATE> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(dateType())},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]NullDate{},
 			errDecodeColumn(0, errSrcVal(&proto3.Value{Kind: (*proto3.Value_ListValue)(nil)}, "List")),
@@ -1347,10 +1350,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<DATE> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(dateType())},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullDate{},
 			errDecodeColumn(0, errNilListValue("DATE")),
@@ -1358,10 +1361,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<DATE> type, but value is for FLOAT64 type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(dateType())},
 				},
-				[]*proto3.Value{floatProto(1.0)},
+				vals: []*proto3.Value{floatProto(1.0)},
 			},
 			&[]NullDate{},
 			errDecodeColumn(0, errSrcVal(floatProto(1.0), "List")),
@@ -1369,10 +1372,10 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<DATE> type, but value is for ARRAY<FLOAT64> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(dateType())},
 				},
-				[]*proto3.Value{listProto(floatProto(1.0))},
+				vals: []*proto3.Value{listProto(floatProto(1.0))},
 			},
 			&[]NullDate{},
 			errDecodeColumn(0, errDecodeArrayElement(0, floatProto(1.0),
@@ -1381,14 +1384,14 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is having a nil Kind.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(structType(
 						mkField("Col1", intType()),
 						mkField("Col2", floatType()),
 						mkField("Col3", stringType()),
 					))},
 				},
-				[]*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
+				vals: []*proto3.Value{{Kind: (*proto3.Value_ListValue)(nil)}},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1400,14 +1403,14 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "Col0", Type: listType(structType(
 						mkField("Col1", intType()),
 						mkField("Col2", floatType()),
 						mkField("Col3", stringType()),
 					))},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1419,7 +1422,7 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is having a nil ListValue.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -1431,7 +1434,7 @@ func TestBrokenRow(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
+				vals: []*proto3.Value{{Kind: &proto3.Value_ListValue{}}},
 			},
 			&[]NullRow{},
 			errDecodeColumn(0, errNilListValue("STRUCT")),
@@ -1439,7 +1442,7 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is for BYTES type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -1451,7 +1454,7 @@ func TestBrokenRow(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{bytesProto([]byte("value"))},
+				vals: []*proto3.Value{bytesProto([]byte("value"))},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1463,7 +1466,7 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is for BYTES type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -1475,7 +1478,7 @@ func TestBrokenRow(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{listProto(bytesProto([]byte("value")))},
+				vals: []*proto3.Value{listProto(bytesProto([]byte("value")))},
 			},
 			&[]NullRow{},
 			errDecodeColumn(0, errNotStructElement(0, bytesProto([]byte("value")))),
@@ -1483,7 +1486,7 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT> type, value is for ARRAY<BYTES> type.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -1495,7 +1498,7 @@ func TestBrokenRow(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{listProto(bytesProto([]byte("value")))},
+				vals: []*proto3.Value{listProto(bytesProto([]byte("value")))},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1508,12 +1511,12 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT>, but is having nil StructType.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0", Type: listType(&sppb.Type{Code: sppb.TypeCode_STRUCT}),
 					},
 				},
-				[]*proto3.Value{listProto(listProto(intProto(1), floatProto(2.0), stringProto("3")))},
+				vals: []*proto3.Value{listProto(listProto(intProto(1), floatProto(2.0), stringProto("3")))},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1526,7 +1529,7 @@ func TestBrokenRow(t *testing.T) {
 		{
 			// Field specifies ARRAY<STRUCT>, but the second struct value is for BOOL type instead of FLOAT64.
 			&Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{
 						Name: "Col0",
 						Type: listType(
@@ -1538,7 +1541,7 @@ func TestBrokenRow(t *testing.T) {
 						),
 					},
 				},
-				[]*proto3.Value{listProto(listProto(intProto(1), boolProto(true), stringProto("3")))},
+				vals: []*proto3.Value{listProto(listProto(intProto(1), boolProto(true), stringProto("3")))},
 			},
 			&[]*struct {
 				Col1 int64
@@ -1870,11 +1873,11 @@ func TestToStructEmbedded(t *testing.T) {
 		}
 	)
 	r := Row{
-		[]*sppb.StructType_Field{
+		fields: []*sppb.StructType_Field{
 			{Name: "F1", Type: stringType()},
 			{Name: "F2", Type: stringType()},
 		},
-		[]*proto3.Value{
+		vals: []*proto3.Value{
 			stringProto("v1"),
 			stringProto("v2"),
 		},
@@ -1897,6 +1900,84 @@ func TestToStructEmbedded(t *testing.T) {
 	}
 }
 
+func TestToStructRemainder(t *testing.T) {
+	type withRemainder struct {
+		F1    string
+		Extra map[string]GenericColumnValue `spanner:",remainder"`
+	}
+	r := Row{
+		fields: []*sppb.StructType_Field{
+			{Name: "F1", Type: stringType()},
+			{Name: "F2", Type: intType()},
+			{Name: "Extra", Type: stringType()},
+		},
+		vals: []*proto3.Value{
+			stringProto("v1"),
+			intProto(2),
+			stringProto("v3"),
+		},
+	}
+	want := withRemainder{
+		F1: "v1",
+		Extra: map[string]GenericColumnValue{
+			"F2":    {Type: intType(), Value: intProto(2)},
+			"Extra": {Type: stringType(), Value: stringProto("v3")},
+		},
+	}
+	var got withRemainder
+	if err := r.ToStruct(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, want) {
+		t.Errorf("ToStruct mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	var gotLenient withRemainder
+	if err := r.ToStructLenient(&gotLenient); err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(gotLenient, want) {
+		t.Errorf("ToStructLenient mismatch\nGot: %v\nWant: %v", gotLenient, want)
+	}
+	var f2 int64
+	if err := got.Extra["F2"].Decode(&f2); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := f2, int64(2); g != w {
+		t.Errorf("F2 mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// A struct without extra columns leaves the remainder field nil.
+	r = Row{
+		fields: []*sppb.StructType_Field{{Name: "F1", Type: stringType()}},
+		vals:   []*proto3.Value{stringProto("v1")},
+	}
+	var noExtra withRemainder
+	if err := r.ToStruct(&noExtra); err != nil {
+		t.Fatal(err)
+	}
+	if noExtra.Extra != nil {
+		t.Errorf("remainder mismatch\nGot: %v\nWant: nil", noExtra.Extra)
+	}
+
+	// The remainder field must be a map[string]GenericColumnValue.
+	var invalid struct {
+		F1    string
+		Extra map[string]interface{} `spanner:",remainder"`
+	}
+	if err := r.ToStruct(&invalid); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+
+	// Remainder fields are not written by InsertStruct.
+	m, err := InsertStruct("T", withRemainder{F1: "v1", Extra: want.Extra})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := m.columns, []string{"F1"}; !testEqual(g, w) {
+		t.Errorf("columns mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
 func TestToStructWithUnEqualFields(t *testing.T) {
 	type (
 		extraField struct {
@@ -1919,11 +2000,11 @@ func TestToStructWithUnEqualFields(t *testing.T) {
 			name: "destination struct has extra field",
 			dst:  &extraField{},
 			row: Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "F1", Type: stringType()},
 					{Name: "F2", Type: stringType()},
 				},
-				[]*proto3.Value{
+				vals: []*proto3.Value{
 					stringProto("v1"),
 					stringProto("v2"),
 				},
@@ -1934,12 +2015,12 @@ func TestToStructWithUnEqualFields(t *testing.T) {
 			name: "destination struct has less field",
 			dst:  &lessField{},
 			row: Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "F1", Type: stringType()},
 					{Name: "F2", Type: stringType()},
 					{Name: "F3", Type: stringType()},
 				},
-				[]*proto3.Value{
+				vals: []*proto3.Value{
 					stringProto("v1"),
 					stringProto("v2"),
 					stringProto("v3"),
@@ -1960,13 +2041,50 @@ func TestToStructWithUnEqualFields(t *testing.T) {
 	}
 }
 
+func TestToStructLenientTagsAndTypeMismatch(t *testing.T) {
+	type narrow struct {
+		ID   int64  `spanner:"SingerId"`
+		Name string `spanner:"FirstName"`
+	}
+	r := Row{
+		fields: []*sppb.StructType_Field{
+			{Name: "SingerId", Type: intType()},
+			{Name: "FirstName", Type: stringType()},
+			{Name: "LastName", Type: stringType()},
+			{Name: "Bio", Type: bytesType()},
+		},
+		vals: []*proto3.Value{
+			intProto(1),
+			stringProto("Marc"),
+			stringProto("Richards"),
+			bytesProto([]byte("bio")),
+		},
+	}
+	var got narrow
+	if err := r.ToStructLenient(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (narrow{ID: 1, Name: "Marc"}); !testEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	// A column that matches a field must still have a compatible type.
+	type mismatch struct {
+		ID   string `spanner:"SingerId"`
+		Name string `spanner:"FirstName"`
+	}
+	if err := r.ToStructLenient(&mismatch{}); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+}
+
 func TestRowToString(t *testing.T) {
 	r := Row{
-		[]*sppb.StructType_Field{
+		fields: []*sppb.StructType_Field{
 			{Name: "F1", Type: stringType()},
 			{Name: "F2", Type: stringType()},
 		},
-		[]*proto3.Value{
+		vals: []*proto3.Value{
 			stringProto("v1"),
 			stringProto("v2"),
 		},
@@ -1982,6 +2100,95 @@ func TestRowToString(t *testing.T) {
 	}
 }
 
+// testMoney is a custom type that implements ColumnDecoder. It decodes from an
+// INT64 column with an amount in cents, or from an ARRAY<INT64> column with
+// amounts in cents that are added up.
+type testMoney struct {
+	Cents int64
+	Valid bool
+}
+
+func (m *testMoney) DecodeSpanner(val GenericColumnValue) error {
+	switch val.Type.Code {
+	case sppb.TypeCode_INT64:
+		var v NullInt64
+		if err := val.Decode(&v); err != nil {
+			return err
+		}
+		*m = testMoney{Cents: v.Int64, Valid: v.Valid}
+		return nil
+	case sppb.TypeCode_ARRAY:
+		var v []NullInt64
+		if err := val.Decode(&v); err != nil {
+			return err
+		}
+		*m = testMoney{Valid: v != nil}
+		for _, c := range v {
+			m.Cents += c.Int64
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot decode %v into testMoney", val.Type.Code)
+}
+
+// Test decoding custom types that implement ColumnDecoder.
+func TestColumnDecoder(t *testing.T) {
+	r := Row{
+		fields: []*sppb.StructType_Field{
+			{Name: "Price", Type: intType()},
+			{Name: "Items", Type: listType(intType())},
+			{Name: "NullPrice", Type: intType()},
+			{Name: "NullItems", Type: listType(intType())},
+			{Name: "Name", Type: stringType()},
+		},
+		vals: []*proto3.Value{
+			intProto(1250),
+			listProto(intProto(100), intProto(250), nullProto()),
+			nullProto(),
+			nullProto(),
+			stringProto("foo"),
+		},
+	}
+	var price, items, nullPrice, nullItems testMoney
+	if err := r.Columns(&price, &items, &nullPrice, &nullItems, new(string)); err != nil {
+		t.Fatalf("failed to decode columns: %v", err)
+	}
+	for _, test := range []struct {
+		name string
+		got  testMoney
+		want testMoney
+	}{
+		{"scalar", price, testMoney{Cents: 1250, Valid: true}},
+		{"array", items, testMoney{Cents: 350, Valid: true}},
+		{"null scalar", nullPrice, testMoney{}},
+		{"null array", nullItems, testMoney{}},
+	} {
+		if !testEqual(test.got, test.want) {
+			t.Errorf("%s: value mismatch\nGot: %v\nWant: %v", test.name, test.got, test.want)
+		}
+	}
+
+	var s struct {
+		Price testMoney
+		Items testMoney
+	}
+	if err := r.ToStructLenient(&s); err != nil {
+		t.Fatalf("failed to decode row into struct: %v", err)
+	}
+	if g, w := s.Price, (testMoney{Cents: 1250, Valid: true}); !testEqual(g, w) {
+		t.Errorf("struct scalar value mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := s.Items, (testMoney{Cents: 350, Valid: true}); !testEqual(g, w) {
+		t.Errorf("struct array value mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// Errors that are returned by DecodeSpanner are returned by Column.
+	var name testMoney
+	if err := r.ColumnByName("Name", &name); err == nil {
+		t.Error("decoding STRING into testMoney: missing error")
+	}
+}
+
 // Test helpers for getting column names.
 func TestColumnNameAndIndex(t *testing.T) {
 	// Test Row.Size().
@@ -2016,6 +2223,122 @@ func TestColumnNameAndIndex(t *testing.T) {
 	}
 }
 
+// Test decoding NUMERIC columns into an *interface{} with each NumericDecodeMode.
+func TestColumnNumericDecodeMode(t *testing.T) {
+	rat, _ := (&big.Rat{}).SetString("123.456")
+	for _, test := range []struct {
+		mode     NumericDecodeMode
+		wantGSQL interface{}
+		wantPG   interface{}
+		wantNull interface{}
+	}{
+		{NumericDecodeModeDefault, *rat, PGNumeric{"123.456", true}, nil},
+		{NumericDecodeModeRat, *rat, *rat, nil},
+		{NumericDecodeModeString, "123.456", "123.456", nil},
+		{NumericDecodeModePGNumeric, PGNumeric{"123.456", true}, PGNumeric{"123.456", true}, PGNumeric{}},
+	} {
+		r := Row{
+			fields: []*sppb.StructType_Field{
+				{Name: "GSQL", Type: numericType()},
+				{Name: "PG", Type: pgNumericType()},
+				{Name: "NULL", Type: numericType()},
+			},
+			vals: []*proto3.Value{stringProto("123.456"), stringProto("123.456"), nullProto()},
+			cfg:  &rowConfig{numericDecodeMode: test.mode},
+		}
+		var gsql, pg, null interface{}
+		if err := r.Columns(&gsql, &pg, &null); err != nil {
+			t.Fatalf("mode %v: failed to decode columns: %v", test.mode, err)
+		}
+		if !testEqual(gsql, test.wantGSQL) {
+			t.Errorf("mode %v: GoogleSQL NUMERIC mismatch\nGot: %v (%T)\nWant: %v (%T)", test.mode, gsql, gsql, test.wantGSQL, test.wantGSQL)
+		}
+		if !testEqual(pg, test.wantPG) {
+			t.Errorf("mode %v: PG NUMERIC mismatch\nGot: %v (%T)\nWant: %v (%T)", test.mode, pg, pg, test.wantPG, test.wantPG)
+		}
+		if !testEqual(null, test.wantNull) {
+			t.Errorf("mode %v: NULL NUMERIC mismatch\nGot: %v (%T)\nWant: %v (%T)", test.mode, null, null, test.wantNull, test.wantNull)
+		}
+	}
+
+	// Only NUMERIC columns can be decoded into an *interface{}.
+	var v interface{}
+	if err := row.ColumnByName("STRING", &v); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("decoding STRING into *interface{}: error mismatch\nGot: %v\nWant: %v", err, codes.InvalidArgument)
+	}
+}
+
+// Test Row.ColumnByNameOpt for present, NULL and absent columns.
+func TestColumnByNameOpt(t *testing.T) {
+	var s NullString
+	present, err := row.ColumnByNameOpt("STRING", &s)
+	if err != nil {
+		t.Fatalf("ColumnByNameOpt(STRING) error: %v", err)
+	}
+	if !present || !testEqual(s, NullString{"value", true}) {
+		t.Errorf("ColumnByNameOpt(STRING) = (%v, %v), want (%v, %v)", present, s, true, NullString{"value", true})
+	}
+
+	s = NullString{"not null", true}
+	present, err = row.ColumnByNameOpt("NULL_STRING", &s)
+	if err != nil {
+		t.Fatalf("ColumnByNameOpt(NULL_STRING) error: %v", err)
+	}
+	if !present || s.Valid {
+		t.Errorf("ColumnByNameOpt(NULL_STRING) = (%v, %v), want (%v, %v)", present, s, true, NullString{})
+	}
+
+	s = NullString{"unchanged", true}
+	present, err = row.ColumnByNameOpt("MISSING", &s)
+	if err != nil {
+		t.Fatalf("ColumnByNameOpt(MISSING) error: %v", err)
+	}
+	if present || !testEqual(s, NullString{"unchanged", true}) {
+		t.Errorf("ColumnByNameOpt(MISSING) = (%v, %v), want (%v, %v)", present, s, false, NullString{"unchanged", true})
+	}
+
+	// Decoding errors are still returned for present columns.
+	var i int64
+	if present, err = row.ColumnByNameOpt("STRING", &i); err == nil || !present {
+		t.Errorf("ColumnByNameOpt(STRING) into int64 = (%v, %v), want (true, error)", present, err)
+	}
+	// Other lookup errors are returned as-is.
+	dup := Row{
+		fields: []*sppb.StructType_Field{{Name: "A", Type: stringType()}, {Name: "A", Type: stringType()}},
+		vals:   []*proto3.Value{stringProto("a"), stringProto("b")},
+	}
+	if present, err = dup.ColumnByNameOpt("A", &s); err == nil || present {
+		t.Errorf("ColumnByNameOpt(A) on duplicate columns = (%v, %v), want (false, error)", present, err)
+	}
+}
+
+func TestSuffixDuplicateColumns(t *testing.T) {
+	field := func(name string) *sppb.StructType_Field {
+		return &sppb.StructType_Field{Name: name, Type: intType()}
+	}
+	for _, test := range []struct {
+		in   []string
+		want []string
+	}{
+		{in: []string{"a", "b"}, want: []string{"a", "b"}},
+		{in: []string{"id", "id", "id"}, want: []string{"id", "id_1", "id_2"}},
+		{in: []string{"id", "id", "id_1"}, want: []string{"id", "id_2", "id_1"}},
+		{in: []string{"", "", "a"}, want: []string{"", "", "a"}},
+	} {
+		var fields []*sppb.StructType_Field
+		for _, name := range test.in {
+			fields = append(fields, field(name))
+		}
+		var got []string
+		for _, f := range suffixDuplicateColumns(fields) {
+			got = append(got, f.Name)
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("suffixDuplicateColumns(%v) mismatch\nGot: %v\nWant: %v", test.in, got, test.want)
+		}
+	}
+}
+
 // Test helpers for getting column type and value.
 func TestColumnTypeAndValue(t *testing.T) {
 	// Test Row.ColumnType()
@@ -2040,6 +2363,202 @@ func TestColumnTypeAndValue(t *testing.T) {
 	}
 }
 
+func TestColumnNumericString(t *testing.T) {
+	values := []string{
+		"0.000000001",
+		"99999999999999999999999999999.999999999",
+		"-12345678901234567890123456789",
+		"1.230000000",
+	}
+	var fields []*sppb.StructType_Field
+	var vals []*proto3.Value
+	for i, v := range values {
+		fields = append(fields, &sppb.StructType_Field{Name: fmt.Sprintf("N%d", i), Type: numericType()})
+		vals = append(vals, stringProto(v))
+	}
+	fields = append(fields,
+		&sppb.StructType_Field{Name: "PG", Type: pgNumericType()},
+		&sppb.StructType_Field{Name: "Null", Type: numericType()},
+		&sppb.StructType_Field{Name: "String", Type: stringType()},
+	)
+	vals = append(vals, stringProto("1.2300"), nullProto(), stringProto("1.5"))
+	row := &Row{fields: fields, vals: vals}
+
+	for i, want := range append(values, "1.2300") {
+		got, err := row.ColumnNumericString(i)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got != want {
+			t.Errorf("%d: numeric string mismatch\nGot: %v\nWant: %v", i, got, want)
+		}
+	}
+	// A big.Rat does not preserve the textual form of the value.
+	var r big.Rat
+	if err := row.Column(3, &r); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.String(), "123/100"; got != want {
+		t.Errorf("big.Rat mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	if _, err := row.ColumnNumericString(5); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("NULL error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+	if _, err := row.ColumnNumericString(6); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("type mismatch error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+
+	// A Numeric is encoded as-is, and can be decoded from a struct field.
+	pb, pt, err := encodeValue(Numeric(values[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(pt, numericType()) || !testEqual(pb, stringProto(values[1])) {
+		t.Errorf("encoded numeric mismatch\nGot: %v %v\nWant: %v %v", pb, pt, stringProto(values[1]), numericType())
+	}
+	var s struct{ N0 Numeric }
+	if err := (&Row{fields: fields[:1], vals: vals[:1]}).ToStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := s.N0, Numeric(values[0]); g != w {
+		t.Errorf("struct field mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestColumnProto(t *testing.T) {
+	genre := pb.Genre_ROCK
+	want := &pb.SingerInfo{
+		SingerId:    proto.Int64(1),
+		BirthDate:   proto.String("January"),
+		Nationality: proto.String("Country1"),
+		Genre:       &genre,
+	}
+	// Encode the value in the same way as it is written with a mutation.
+	m := Insert("Singers", []string{"SingerId", "SingerInfo"}, []interface{}{int64(1), want})
+	mPb, err := m.proto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := mPb.GetInsert().GetValues()[0].GetValues()
+	fqn := string(want.ProtoReflect().Descriptor().FullName())
+	r := Row{
+		fields: []*sppb.StructType_Field{
+			{Name: "SingerId", Type: intType()},
+			{Name: "SingerInfo", Type: protoMessageType(fqn)},
+			{Name: "Info", Type: protoMessageType("examples.spanner.music.OtherInfo")},
+			{Name: "Null", Type: protoMessageType(fqn)},
+		},
+		vals: []*proto3.Value{vals[0], vals[1], vals[1], nullProto()},
+	}
+
+	got := &pb.SingerInfo{}
+	if err := r.ColumnProto(1, got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Fatalf("proto mismatch\nGot: %v\nWant: %v", got, want)
+	}
+	// The value can also be decoded from a GenericColumnValue.
+	var gcv GenericColumnValue
+	if err := r.Column(1, &gcv); err != nil {
+		t.Fatal(err)
+	}
+	got = &pb.SingerInfo{}
+	if err := gcv.DecodeProto(got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Fatalf("proto mismatch\nGot: %v\nWant: %v", got, want)
+	}
+
+	for _, test := range []struct {
+		col     int
+		wantErr string
+	}{
+		{0, "type INT64 is not PROTO"},
+		{2, "does not match message type"},
+		{3, "cannot support NULL SQL values"},
+	} {
+		err := r.ColumnProto(test.col, &pb.SingerInfo{})
+		if ErrCode(err) != codes.InvalidArgument || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("column %d: error mismatch\nGot: %v\nWant: %v", test.col, err, test.wantErr)
+		}
+	}
+	if err := r.ColumnProto(4, &pb.SingerInfo{}); ErrCode(err) != codes.OutOfRange {
+		t.Errorf("out of range column: got error %v, want OutOfRange", err)
+	}
+}
+
+func TestColumnStream(t *testing.T) {
+	elemType := structType(mkField("Name", stringType()), mkField("Age", intType()))
+	r := &Row{
+		fields: []*sppb.StructType_Field{
+			mkField("Singers", listType(elemType)),
+			mkField("NullSingers", listType(elemType)),
+			mkField("Name", stringType()),
+		},
+		vals: []*proto3.Value{
+			listProto(listProto(stringProto("a"), intProto(1)), nullProto(), listProto(stringProto("b"), intProto(2))),
+			nullProto(),
+			stringProto("c"),
+		},
+	}
+	type singer struct {
+		Name string
+		Age  int64
+	}
+	var got []*singer
+	if err := r.ColumnStream(0, func(v GenericColumnValue) error {
+		if !testEqual(v.Type, elemType) {
+			return fmt.Errorf("element type mismatch\nGot: %v\nWant: %v", v.Type, elemType)
+		}
+		var s *singer
+		if fields := v.Value.GetListValue().GetValues(); fields != nil {
+			s = &singer{Name: fields[0].GetStringValue()}
+			if err := decodeValue(fields[1], intType(), &s.Age); err != nil {
+				return err
+			}
+		} else if _, isNull := v.Value.GetKind().(*proto3.Value_NullValue); !isNull {
+			return fmt.Errorf("unexpected element %v", v.Value)
+		}
+		got = append(got, s)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []*singer{{"a", 1}, nil, {"b", 2}}; !testEqual(got, want) {
+		t.Fatalf("elements mismatch\nGot: %v\nWant: %v", got, want)
+	}
+
+	// The callback is not called for a NULL array.
+	if err := r.ColumnStream(1, func(v GenericColumnValue) error {
+		return fmt.Errorf("unexpected element %v", v.Value)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The error of the callback is returned, and no more elements are read.
+	stop := errors.New("stop")
+	calls := 0
+	if err := r.ColumnStream(0, func(v GenericColumnValue) error {
+		calls++
+		return stop
+	}); err != stop {
+		t.Fatalf("error mismatch\nGot: %v\nWant: %v", err, stop)
+	}
+	if calls != 1 {
+		t.Fatalf("callback count mismatch\nGot: %v\nWant: %v", calls, 1)
+	}
+
+	// Columns that are not arrays are rejected.
+	if err := r.ColumnStream(2, func(v GenericColumnValue) error { return nil }); ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("error code mismatch for non-array column\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+	if err := r.ColumnStream(3, func(v GenericColumnValue) error { return nil }); ErrCode(err) != codes.OutOfRange {
+		t.Fatalf("error code mismatch for invalid column\nGot: %v\nWant: %v", ErrCode(err), codes.OutOfRange)
+	}
+}
+
 func TestNewRow(t *testing.T) {
 	for _, test := range []struct {
 		names   []string
@@ -2065,12 +2584,12 @@ func TestNewRow(t *testing.T) {
 			names:  []string{"a", "b", "c"},
 			values: []interface{}{5, "abc", GenericColumnValue{listType(intType()), listProto(intProto(91), nullProto(), intProto(87))}},
 			want: &Row{
-				[]*sppb.StructType_Field{
+				fields: []*sppb.StructType_Field{
 					{Name: "a", Type: intType()},
 					{Name: "b", Type: stringType()},
 					{Name: "c", Type: listType(intType())},
 				},
-				[]*proto3.Value{
+				vals: []*proto3.Value{
 					intProto(5),
 					stringProto("abc"),
 					listProto(intProto(91), nullProto(), intProto(87)),
@@ -2131,16 +2650,16 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]string{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col0", Type: stringType()},
 						},
-						[]*proto3.Value{stringProto("value")},
+						vals: []*proto3.Value{stringProto("value")},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col0", Type: stringType()},
 						},
-						[]*proto3.Value{stringProto("value2")},
+						vals: []*proto3.Value{stringProto("value2")},
 					},
 					iterator.Done,
 				),
@@ -2153,16 +2672,16 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*string{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col0", Type: stringType()},
 						},
-						[]*proto3.Value{stringProto("value")},
+						vals: []*proto3.Value{stringProto("value")},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col0", Type: stringType()},
 						},
-						[]*proto3.Value{stringProto("value2")},
+						vals: []*proto3.Value{stringProto("value2")},
 					},
 					iterator.Done,
 				),
@@ -2175,22 +2694,22 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]testStruct{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: timeType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm)},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm)},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: timeType()},
 						},
-						[]*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2"), timeProto(tm.Add(24 * time.Hour))},
+						vals: []*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2"), timeProto(tm.Add(24 * time.Hour))},
 					},
 					iterator.Done,
 				),
@@ -2206,20 +2725,20 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*testStruct{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2")},
+						vals: []*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2")},
 					},
 					iterator.Done,
 				),
@@ -2234,14 +2753,14 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*testStruct{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: timeType()},
 							{Name: "Col5", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm), stringProto("value2")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm), stringProto("value2")},
 					},
 					// failure case
 					iterator.Done,
@@ -2258,20 +2777,20 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*testStruct{{Col1: 3, COL2: 3.3, Col3: "value3"}},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2")},
+						vals: []*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2")},
 					},
 					iterator.Done,
 				),
@@ -2288,22 +2807,22 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]testStructWithTag{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Tag1", Type: intType()},
 							{Name: "Tag2", Type: floatType()},
 							{Name: "Tag3", Type: stringType()},
 							{Name: "Tag4", Type: timeType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm)},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value"), timeProto(tm)},
 					},
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Tag1", Type: intType()},
 							{Name: "Tag2", Type: floatType()},
 							{Name: "Tag3", Type: stringType()},
 							{Name: "Tag4", Type: timeType()},
 						},
-						[]*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2"), timeProto(tm.Add(24 * time.Hour))},
+						vals: []*proto3.Value{intProto(2), floatProto(2.2), stringProto("value2"), timeProto(tm.Add(24 * time.Hour))},
 					},
 					iterator.Done,
 				),
@@ -2319,12 +2838,12 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*testStruct{{Col1: 3, COL2: 3.3, Col3: "value3"}},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					// failure case
 					errors.New("some error"),
@@ -2342,13 +2861,13 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]*testStruct{{Col1: 3, COL2: 3.3, Col3: "value3"}},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					// failure case
 					iterator.Done,
@@ -2365,13 +2884,13 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]int64{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					// failure case
 					iterator.Done,
@@ -2386,13 +2905,13 @@ func TestSelectAll(t *testing.T) {
 				destination: &[]int64{},
 				mock: newMockIterator(
 					&Row{
-						[]*sppb.StructType_Field{
+						fields: []*sppb.StructType_Field{
 							{Name: "Col1", Type: intType()},
 							{Name: "Col2", Type: floatType()},
 							{Name: "Col3", Type: stringType()},
 							{Name: "Col4", Type: stringType()},
 						},
-						[]*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
+						vals: []*proto3.Value{intProto(1), floatProto(1.1), stringProto("value")},
 					},
 					// failure case
 					iterator.Done,
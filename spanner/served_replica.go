@@ -0,0 +1,43 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+)
+
+// ReplicaInfo describes the replica that served a read or query.
+type ReplicaInfo struct {
+	// Location is the location of the replica, for example "us-east1".
+	Location string
+	// Type is the type of the replica, either READ_WRITE or READ_ONLY.
+	Type sppb.DirectedReadOptions_ReplicaSelection_Type
+}
+
+// ServedReplicaFromContext returns the replica that served the last read or
+// query that was executed with ctx.
+//
+// Cloud Spanner does not return information about the replica that served a
+// read or query, neither in the response nor in the response metadata.
+// ServedReplicaFromContext therefore always returns false. It is provided so
+// that applications can be written against it, and will start returning
+// replica information if Cloud Spanner makes this information available.
+func ServedReplicaFromContext(ctx context.Context) (ReplicaInfo, bool) {
+	return ReplicaInfo{}, false
+}
@@ -100,6 +100,9 @@ type sessionHandle struct {
 	eligibleForLongRunning bool
 	// if the inner session object is long-running then the stack gets logged once.
 	isSessionLeakLogged bool
+	// op is the name of the operation that checked out the session. It is
+	// passed to the SessionUsageObserver of the client.
+	op string
 }
 
 // recycle gives the inner session object back to its home session pool. It is
@@ -114,6 +117,7 @@ func (sh *sessionHandle) recycle() {
 	p := sh.session.pool
 	tracked := sh.trackedSessionHandle
 	s := sh.session
+	checkoutTime := sh.checkoutTime
 	sh.session = nil
 	sh.trackedSessionHandle = nil
 	sh.checkoutTime = time.Time{}
@@ -126,6 +130,16 @@ func (sh *sessionHandle) recycle() {
 		p.trackedSessionHandles.Remove(tracked)
 		p.mu.Unlock()
 	}
+	sh.observeUsage(p, s, checkoutTime)
+}
+
+// observeUsage calls the SessionUsageObserver of the session pool, if any,
+// for a session that has been returned by this handle.
+func (sh *sessionHandle) observeUsage(p *sessionPool, s *session, checkoutTime time.Time) {
+	if p == nil || p.sessionUsageObserver == nil {
+		return
+	}
+	p.sessionUsageObserver(s.getID(), sh.op, p.now().Sub(checkoutTime))
 }
 
 // getID gets the Cloud Spanner session ID from the internal session object.
@@ -184,6 +198,7 @@ func (sh *sessionHandle) destroy() {
 		return
 	}
 	tracked := sh.trackedSessionHandle
+	checkoutTime := sh.checkoutTime
 	sh.session = nil
 	sh.trackedSessionHandle = nil
 	sh.checkoutTime = time.Time{}
@@ -197,6 +212,7 @@ func (sh *sessionHandle) destroy() {
 		p.trackedSessionHandles.Remove(tracked)
 		p.mu.Unlock()
 	}
+	sh.observeUsage(s.pool, s, checkoutTime)
 	s.destroy(false)
 }
 
@@ -204,7 +220,7 @@ func (sh *sessionHandle) updateLastUseTime() {
 	sh.mu.Lock()
 	defer sh.mu.Unlock()
 	if sh.session != nil {
-		sh.lastUseTime = time.Now()
+		sh.lastUseTime = sh.session.pool.now()
 	}
 }
 
@@ -245,6 +261,9 @@ type session struct {
 	// checkingHelath is true if currently this session is being processed by
 	// health checker. Must be modified under health checker lock.
 	checkingHealth bool
+	// idleSince is the time that the session was last added to the idle list
+	// of its home session pool. Must be modified under session pool lock.
+	idleSince time.Time
 	// md is the Metadata to be sent with each request.
 	md metadata.MD
 	// tx contains the transaction id if the session has been prepared for
@@ -290,6 +309,9 @@ func (s *session) ping() error {
 		Session: s.getID(),
 		Sql:     "SELECT 1",
 	})
+	if err != nil && s.pool != nil && s.pool.OnHealthCheckFailure != nil {
+		s.pool.OnHealthCheckFailure(s.getID(), err)
+	}
 	return err
 }
 
@@ -436,6 +458,18 @@ type SessionPoolConfig struct {
 	// Defaults to 0.
 	MaxIdle uint64
 
+	// IdleSessionTimeout is the maximum time that a session may stay idle in
+	// the session pool after it was created or returned to the pool. The
+	// session pool maintainer deletes sessions that have been idle for longer
+	// than IdleSessionTimeout, but does not delete sessions if the number of
+	// opened sessions would drop below MinOpened. Sessions are checked each
+	// time the maintainer runs, so a session may stay idle for up to one
+	// maintainer cycle longer than IdleSessionTimeout.
+	//
+	// Defaults to 0, which means that idle sessions are not deleted because
+	// of their idle time.
+	IdleSessionTimeout time.Duration
+
 	// MaxBurst is the maximum number of concurrent session creation requests.
 	//
 	// Deprecated: MaxBurst exists for historical compatibility and should not
@@ -475,6 +509,20 @@ type SessionPoolConfig struct {
 	// Defaults to 50m.
 	HealthCheckInterval time.Duration
 
+	// DisableSessionHealthCheck disables the health checker of the session
+	// pool. The health check workers and the pool maintainer are not started,
+	// which saves the goroutines and timers of these for clients that are
+	// only used for a short time, for example in serverless functions.
+	//
+	// Sessions are still created when they are needed and deleted when the
+	// client is closed. Idle sessions are however not pinged to keep them
+	// alive, the pool is not grown or shrunk in the background, and the
+	// settings that are applied by the maintainer, such as IdleSessionTimeout
+	// and ActionOnInactiveTransaction, have no effect.
+	//
+	// Defaults to false.
+	DisableSessionHealthCheck bool
+
 	// TrackSessionHandles determines whether the session pool will keep track
 	// of the stacktrace of the goroutines that take sessions from the pool.
 	// This setting can be used to track down session leak problems.
@@ -482,15 +530,53 @@ type SessionPoolConfig struct {
 	// Defaults to false.
 	TrackSessionHandles bool
 
+	// OnHealthCheckFailure is called with the ID of the session and the
+	// error when a health check ping of a session fails. Sessions for which
+	// the ping returns a 'Session not found' error are removed from the pool.
+	// The function is called by the health check workers and the session
+	// pool, and should return quickly.
+	//
+	// Defaults to nil.
+	OnHealthCheckFailure func(sessionID string, err error)
+
+	// DatabaseRoleProvider is called by the session pool each time that it
+	// sends a request to create one or more sessions, and returns the
+	// database role that the new sessions are created with. It replaces
+	// ClientConfig.DatabaseRole for the sessions of the pool, and can for
+	// example be used to rotate the database role.
+	//
+	// A session keeps the role that it was created with, and the session
+	// pool hands out its sessions without regard to their role. A role that
+	// is returned by DatabaseRoleProvider is therefore not guaranteed to be
+	// used for any specific operation, and DatabaseRoleProvider cannot be used
+	// to select a role per tenant or per operation. All roles that it returns
+	// should have the privileges that are needed for all operations of the
+	// client. The function should return quickly, as it is called while
+	// sessions are being created.
+	//
+	// Defaults to nil, which means that ClientConfig.DatabaseRole is used.
+	DatabaseRoleProvider func() string
+
 	// healthCheckSampleInterval is how often the health checker samples live
 	// session (for use in maintaining session pool size).
 	//
 	// Defaults to 1m.
 	healthCheckSampleInterval time.Duration
 
+	// clock is the clock that is used for the maintenance of the session pool
+	// and for the health checks of the sessions. Tests can set a clock that is
+	// advanced manually.
+	//
+	// Defaults to the wall clock.
+	clock clock
+
 	// sessionLabels for the sessions created in the session pool.
 	sessionLabels map[string]string
 
+	// sessionUsageObserver is the ClientConfig.SessionUsageObserver of the
+	// client that created the session pool.
+	sessionUsageObserver func(sessionID string, op string, held time.Duration)
+
 	InactiveTransactionRemovalOptions
 }
 
@@ -560,6 +646,10 @@ type sessionPool struct {
 	mu sync.Mutex
 	// valid marks the validity of the session pool.
 	valid bool
+	// closing indicates that the session pool is being closed by
+	// closeWithTimeout. A closing pool does not hand out sessions, but still
+	// accepts the sessions that are returned to it.
+	closing bool
 	// sc is used to create the sessions for the pool.
 	sc *sessionClient
 	// trackedSessionHandles contains all sessions handles that have been
@@ -615,7 +705,61 @@ type sessionPool struct {
 	// This is valid only when ActionOnInactiveTransaction is WarnAndClose or ActionOnInactiveTransaction is Close in InactiveTransactionRemovalOptions.
 	numOfLeakedSessionsRemoved uint64
 
+	// numOfIdleSessionsEvicted is the number of sessions that have been
+	// deleted because they were idle for longer than IdleSessionTimeout.
+	numOfIdleSessionsEvicted uint64
+
 	otConfig *openTelemetryConfig
+
+	// numSessionsTaken is the total number of times that a session has been
+	// checked out of the session pool.
+	numSessionsTaken uint64
+}
+
+// PoolStats is a snapshot of the utilization of the session pool of a Client.
+type PoolStats struct {
+	// NumOpened is the number of sessions that are opened by the pool,
+	// including the sessions that are being created.
+	NumOpened uint64
+	// NumInUse is the number of sessions that are checked out of the pool.
+	NumInUse uint64
+	// NumIdle is the number of sessions that are idle in the pool.
+	NumIdle uint64
+	// NumBeingCreated is the number of sessions that are being created.
+	NumBeingCreated uint64
+	// MaxAllowed is the maximum number of sessions that the pool may open.
+	MaxAllowed uint64
+	// NumSessionsTaken is the total number of times that a session has been
+	// checked out of the pool since the pool was created.
+	NumSessionsTaken uint64
+	// NumIdleSessionsEvicted is the total number of sessions that have been
+	// deleted because they were idle for longer than
+	// SessionPoolConfig.IdleSessionTimeout.
+	NumIdleSessionsEvicted uint64
+}
+
+// stats returns a snapshot of the utilization of the session pool.
+func (p *sessionPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		NumOpened:              p.numOpened,
+		NumInUse:               p.numInUse,
+		NumIdle:                uint64(p.idleList.Len()),
+		NumBeingCreated:        p.createReqs,
+		MaxAllowed:             p.MaxOpened,
+		NumSessionsTaken:       p.numSessionsTaken,
+		NumIdleSessionsEvicted: p.numOfIdleSessionsEvicted,
+	}
+}
+
+// now returns the current time of the clock of the session pool. It returns
+// the wall clock time if p is nil or does not have a clock.
+func (p *sessionPool) now() time.Time {
+	if p == nil || p.clock == nil {
+		return time.Now()
+	}
+	return p.clock.Now()
 }
 
 // newSessionPool creates a new session pool.
@@ -638,6 +782,9 @@ func newSessionPool(sc *sessionClient, config SessionPoolConfig) (*sessionPool,
 	if config.healthCheckSampleInterval == 0 {
 		config.healthCheckSampleInterval = time.Minute
 	}
+	if config.clock == nil {
+		config.clock = wallClock{}
+	}
 	if config.ActionOnInactiveTransaction == actionUnspecified {
 		config.ActionOnInactiveTransaction = DefaultSessionPoolConfig.ActionOnInactiveTransaction
 	}
@@ -723,6 +870,15 @@ func (p *sessionPool) recordOTStat(ctx context.Context, m metric.Int64Counter, v
 	}
 }
 
+// recordWaitTime records the time that a request for a session waited for a
+// session to become available.
+func (p *sessionPool) recordWaitTime(ctx context.Context, d time.Duration) {
+	if p.otConfig == nil || p.otConfig.sessionPoolWaitTime == nil {
+		return
+	}
+	p.otConfig.sessionPoolWaitTime.Record(ctx, float64(d)/float64(time.Millisecond), metric.WithAttributes(p.otConfig.attributeMap...))
+}
+
 func (p *sessionPool) getRatioOfSessionsInUseLocked() float64 {
 	maxSessions := p.MaxOpened
 	if maxSessions == 0 {
@@ -746,7 +902,7 @@ func (p *sessionPool) getLongRunningSessionsLocked() []*sessionHandle {
 				element = element.Next()
 				continue
 			}
-			diff := time.Since(sh.lastUseTime)
+			diff := p.now().Sub(sh.lastUseTime)
 			if !sh.eligibleForLongRunning && diff.Seconds() >= p.idleTimeThreshold.Seconds() {
 				if (p.ActionOnInactiveTransaction == Warn || p.ActionOnInactiveTransaction == WarnAndClose) && !sh.isSessionLeakLogged {
 					if p.ActionOnInactiveTransaction == Warn {
@@ -828,6 +984,7 @@ func (p *sessionPool) sessionReady(s *session) {
 	// Insert the session at a random position in the pool to prevent all
 	// sessions affiliated with a channel to be placed at sequentially in the
 	// pool.
+	s.idleSince = p.now()
 	if p.idleList.Len() > 0 {
 		pos := rand.Intn(p.idleList.Len())
 		before := p.idleList.Front()
@@ -860,6 +1017,15 @@ func (p *sessionPool) sessionCreationFailed(err error, numSessions int32) {
 	p.mayGetSession = make(chan struct{})
 }
 
+// batchComplete is called by the SessionClient when a batch of sessions that
+// was requested by the pool has finished. It logs the number of sessions that
+// could not be created if the batch was only partially successful.
+func (p *sessionPool) batchComplete(created, failed int32) {
+	if failed > 0 && p.isValid() {
+		logf(p.sc.logger, "Created %d of %d requested sessions, %d sessions could not be created", created, created+failed, failed)
+	}
+}
+
 // isValid checks if the session pool is still valid.
 func (p *sessionPool) isValid() bool {
 	if p == nil {
@@ -875,13 +1041,83 @@ func (p *sessionPool) isValid() bool {
 // ignored, except for DeadlineExceeded errors, which are ignored and not
 // logged.
 func (p *sessionPool) close(ctx context.Context) {
-	if p == nil {
+	if p == nil || !p.invalidate() {
 		return
 	}
+	p.deleteSessions(ctx, false)
+}
+
+// closeWithTimeout stops handing out sessions and waits until all sessions
+// that are in use have been returned to the pool, or until ctx is done. It
+// then marks the session pool as closed and deletes all sessions from Cloud
+// Spanner, using deleteCtx for the DeleteSession RPCs.
+//
+// closeWithTimeout returns an error that lists the sessions that are still in
+// use if ctx is done before all sessions have been returned.
+func (p *sessionPool) closeWithTimeout(ctx, deleteCtx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	if !p.valid || p.closing {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closing = true
+	p.mu.Unlock()
+	var err error
+	for {
+		p.mu.Lock()
+		inUse := p.numOpened - uint64(p.idleList.Len()) - p.createReqs
+		mayGetSession := p.mayGetSession
+		p.mu.Unlock()
+		if inUse == 0 {
+			break
+		}
+		select {
+		case <-mayGetSession:
+		case <-ctx.Done():
+			err = errSessionsInUseOnClose(p.sessionsInUse())
+		}
+		if err != nil {
+			break
+		}
+	}
+	if p.invalidate() {
+		p.deleteSessions(deleteCtx, true)
+	}
+	return err
+}
+
+// sessionsInUse returns the IDs of the sessions of the pool that are checked
+// out.
+func (p *sessionPool) sessionsInUse() []string {
+	p.hc.mu.Lock()
+	allSessions := make([]*session, len(p.hc.queue.sessions))
+	copy(allSessions, p.hc.queue.sessions)
+	p.hc.mu.Unlock()
+	var ids []string
+	for _, s := range allSessions {
+		if s.isValid() && s.getIdleList() == nil {
+			ids = append(ids, s.getID())
+		}
+	}
+	return ids
+}
+
+// errSessionsInUseOnClose returns error for closing a session pool while
+// sessions are still in use.
+func errSessionsInUseOnClose(ids []string) error {
+	return spannerErrorf(codes.DeadlineExceeded, "%d session(s) still in use when the client was closed: %v", len(ids), ids)
+}
+
+// invalidate marks the session pool as closed. It returns false if the pool
+// had already been closed.
+func (p *sessionPool) invalidate() bool {
 	p.mu.Lock()
 	if !p.valid {
 		p.mu.Unlock()
-		return
+		return false
 	}
 	p.valid = false
 	if p.otConfig != nil && p.otConfig.otMetricRegistration != nil {
@@ -891,6 +1127,13 @@ func (p *sessionPool) close(ctx context.Context) {
 		}
 	}
 	p.mu.Unlock()
+	return true
+}
+
+// deleteSessions stops the health checker and removes all sessions from the
+// pool in parallel. The sessions are also deleted from Cloud Spanner if
+// deleteFromServer is true.
+func (p *sessionPool) deleteSessions(ctx context.Context, deleteFromServer bool) {
 	p.hc.close()
 	// destroy all the sessions
 	p.hc.mu.Lock()
@@ -900,14 +1143,16 @@ func (p *sessionPool) close(ctx context.Context) {
 	wg := sync.WaitGroup{}
 	for _, s := range allSessions {
 		wg.Add(1)
-		go deleteSession(ctx, s, &wg)
+		go deleteSession(ctx, s, deleteFromServer, &wg)
 	}
 	wg.Wait()
 }
 
-func deleteSession(ctx context.Context, s *session, wg *sync.WaitGroup) {
+func deleteSession(ctx context.Context, s *session, deleteFromServer bool, wg *sync.WaitGroup) {
 	defer wg.Done()
-	s.destroyWithContext(ctx, false)
+	if s.destroyWithContext(ctx, false) && deleteFromServer {
+		s.delete(ctx)
+	}
 }
 
 // errInvalidSessionPool is the error for using an invalid session pool.
@@ -922,7 +1167,8 @@ var errGetSessionTimeout = spannerErrorf(codes.Canceled, "timeout / context canc
 // stack if the session pool has been configured to track the call stacks of
 // sessions being checked out of the pool.
 func (p *sessionPool) newSessionHandle(s *session) (sh *sessionHandle) {
-	sh = &sessionHandle{session: s, checkoutTime: time.Now(), lastUseTime: time.Now()}
+	now := p.now()
+	sh = &sessionHandle{session: s, checkoutTime: now, lastUseTime: now}
 	if p.TrackSessionHandles || p.ActionOnInactiveTransaction == Warn || p.ActionOnInactiveTransaction == WarnAndClose || p.ActionOnInactiveTransaction == Close {
 		p.mu.Lock()
 		sh.trackedSessionHandle = p.trackedSessionHandles.PushBack(sh)
@@ -1019,7 +1265,7 @@ func (p *sessionPool) createSession(ctx context.Context) (*session, error) {
 }
 
 func (p *sessionPool) isHealthy(s *session) bool {
-	if s.getNextCheck().Add(2 * p.hc.getInterval()).Before(time.Now()) {
+	if s.getNextCheck().Add(2 * p.hc.getInterval()).Before(p.now()) {
 		if err := s.ping(); isSessionNotFoundError(err) {
 			// The session is already bad, continue to fetch/create a new one.
 			s.destroy(false)
@@ -1030,15 +1276,35 @@ func (p *sessionPool) isHealthy(s *session) bool {
 	return true
 }
 
+// takeFor takes a session from the pool for the given operation. The name of
+// the operation is passed to the SessionUsageObserver of the client when the
+// session is returned.
+func (p *sessionPool) takeFor(ctx context.Context, op string) (*sessionHandle, error) {
+	sh, err := p.take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sh.op = op
+	return sh, nil
+}
+
 // take returns a cached session if there are available ones; if there isn't
 // any, it tries to allocate a new one.
 func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 	trace.TracePrintf(ctx, nil, "Acquiring a session")
+	// waitStart is the time that take started to wait for a session, or zero
+	// if take has not waited.
+	var waitStart time.Time
+	defer func() {
+		if !waitStart.IsZero() {
+			p.recordWaitTime(ctx, p.now().Sub(waitStart))
+		}
+	}()
 	for {
 		var s *session
 
 		p.mu.Lock()
-		if !p.valid {
+		if !p.valid || p.closing {
 			p.mu.Unlock()
 			return nil, errInvalidSessionPool
 		}
@@ -1080,6 +1346,9 @@ func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 		p.numWaiters++
 		mayGetSession := p.mayGetSession
 		p.mu.Unlock()
+		if waitStart.IsZero() {
+			waitStart = p.now()
+		}
 		trace.TracePrintf(ctx, nil, "Waiting for read-only session to become available")
 		select {
 		case <-ctx.Done():
@@ -1106,6 +1375,45 @@ func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 	}
 }
 
+// warmUp blocks until MinOpened sessions have been created, or ctx is done.
+// The creation of the sessions that are missing is started if no sessions are
+// being created. warmUp returns the error of the session creation if fewer
+// than MinOpened sessions could be created.
+func (p *sessionPool) warmUp(ctx context.Context) error {
+	grown := false
+	for {
+		p.mu.Lock()
+		if !p.valid || p.closing {
+			p.mu.Unlock()
+			return errInvalidSessionPool
+		}
+		// numOpened includes the sessions that are being created.
+		if p.numOpened-p.createReqs >= p.MinOpened {
+			p.mu.Unlock()
+			return nil
+		}
+		if p.createReqs == 0 {
+			if grown && p.sessionCreationError != nil {
+				err := p.sessionCreationError
+				p.mu.Unlock()
+				return err
+			}
+			if err := p.growPoolLocked(p.MinOpened-p.numOpened, true); err != nil {
+				p.mu.Unlock()
+				return err
+			}
+			grown = true
+		}
+		mayGetSession := p.mayGetSession
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ToSpannerError(ctx.Err())
+		case <-mayGetSession:
+		}
+	}
+}
+
 // recycle puts session s back to the session pool's idle list, it returns true
 // if the session pool successfully recycles session s.
 func (p *sessionPool) recycle(s *session) bool {
@@ -1122,6 +1430,7 @@ func (p *sessionPool) recycleLocked(s *session) bool {
 	ctx := context.Background()
 	// Put session at the top of the list to be handed out in LIFO order for load balancing
 	// across channels.
+	s.idleSince = p.now()
 	s.setIdleList(p.idleList.PushFront(s))
 	p.incNumSessionsLocked(ctx)
 	// Broadcast that a session has been returned to idle list.
@@ -1175,6 +1484,7 @@ func (p *sessionPool) incNumInUse(ctx context.Context) {
 
 func (p *sessionPool) incNumInUseLocked(ctx context.Context) {
 	p.numInUse++
+	p.numSessionsTaken++
 	p.recordStat(ctx, SessionsCount, int64(p.numInUse), tagNumInUseSessions)
 	p.recordStat(ctx, AcquiredSessionsCount, 1)
 	if p.otConfig != nil {
@@ -1341,6 +1651,9 @@ type healthChecker struct {
 	// once is used for closing channel done only once.
 	once             sync.Once
 	maintainerCancel func()
+	// paused indicates that the health checks and the maintenance of the
+	// pool have been paused.
+	paused bool
 }
 
 // newHealthChecker initializes new instance of healthChecker.
@@ -1357,6 +1670,9 @@ func newHealthChecker(interval time.Duration, workers int, sampleInterval time.D
 		done:             make(chan struct{}),
 		maintainerCancel: func() {},
 	}
+	if pool.DisableSessionHealthCheck {
+		return hc
+	}
 	hc.waitWorkers.Add(1)
 	go hc.maintainer()
 	for i := 1; i <= hc.workers; i++ {
@@ -1385,6 +1701,22 @@ func (hc *healthChecker) isClosing() bool {
 	}
 }
 
+// setPaused pauses or resumes the health checks and the maintenance of the
+// pool.
+func (hc *healthChecker) setPaused(paused bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.paused = paused
+}
+
+// isPaused reports whether the health checks and the maintenance of the pool
+// have been paused.
+func (hc *healthChecker) isPaused() bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return hc.paused
+}
+
 // getInterval gets the healthcheck interval.
 func (hc *healthChecker) getInterval() time.Duration {
 	hc.mu.Lock()
@@ -1411,7 +1743,7 @@ func (hc *healthChecker) scheduledHCLocked(s *session) {
 	}
 	// math.Ceil makes the value to be at least 1 ns.
 	nsFromNow := int64(math.Ceil(constPart + randPart))
-	s.setNextCheck(time.Now().Add(time.Duration(nsFromNow)))
+	s.setNextCheck(hc.pool.now().Add(time.Duration(nsFromNow)))
 	if hi := s.getHcIndex(); hi != -1 {
 		// Session is still being tracked by healthcheck workers.
 		heap.Fix(&hc.queue, hi)
@@ -1459,6 +1791,10 @@ func (hc *healthChecker) healthCheck(s *session) {
 		s.destroy(false)
 		return
 	}
+	if hc.isPaused() {
+		// Health checks were paused after the session was scheduled.
+		return
+	}
 	if err := s.ping(); isSessionNotFoundError(err) {
 		// Ping failed, destroy the session.
 		s.destroy(false)
@@ -1474,12 +1810,16 @@ func (hc *healthChecker) worker(i int) {
 		defer hc.pool.mu.Unlock()
 		hc.mu.Lock()
 		defer hc.mu.Unlock()
+		if hc.paused {
+			// Health checks have been paused.
+			return nil
+		}
 		if hc.queue.Len() <= 0 {
 			// Queue is empty.
 			return nil
 		}
 		s := hc.queue.sessions[0]
-		if s.getNextCheck().After(time.Now()) && hc.pool.valid {
+		if s.getNextCheck().After(hc.pool.now()) && hc.pool.valid {
 			// All sessions have been checked recently.
 			return nil
 		}
@@ -1505,9 +1845,11 @@ func (hc *healthChecker) worker(i int) {
 			if pause > int64(hc.interval) {
 				pause = int64(hc.interval)
 			}
+			timer := hc.pool.clock.NewTimer(time.Duration(rand.Int63n(pause) + pause/2))
 			select {
-			case <-time.After(time.Duration(rand.Int63n(pause) + pause/2)):
+			case <-timer.C():
 			case <-hc.done:
+				timer.Stop()
 			}
 			continue
 		}
@@ -1540,7 +1882,7 @@ func (hc *healthChecker) maintainer() {
 
 		// Reset the start time for recording the maximum number of sessions
 		// in the pool.
-		now := time.Now()
+		now := hc.pool.clock.Now()
 		if now.After(hc.pool.lastResetTime.Add(10 * time.Minute)) {
 			hc.pool.maxNumInUse = hc.pool.numInUse
 			hc.pool.recordStat(context.Background(), MaxInUseSessionsCount, int64(hc.pool.maxNumInUse))
@@ -1548,17 +1890,25 @@ func (hc *healthChecker) maintainer() {
 		}
 		hc.pool.mu.Unlock()
 
+		paused := hc.isPaused()
+
 		// task to remove or log sessions which are unexpectedly long-running
-		if now.After(hc.pool.InactiveTransactionRemovalOptions.lastExecutionTime.Add(hc.pool.executionFrequency)) {
+		if !paused && now.After(hc.pool.InactiveTransactionRemovalOptions.lastExecutionTime.Add(hc.pool.executionFrequency)) {
 			if hc.pool.ActionOnInactiveTransaction == Warn || hc.pool.ActionOnInactiveTransaction == WarnAndClose || hc.pool.ActionOnInactiveTransaction == Close {
 				hc.pool.removeLongRunningSessions()
 			}
 			hc.pool.InactiveTransactionRemovalOptions.lastExecutionTime = now
 		}
 
+		// task to delete sessions that have been idle for too long
+		if !paused && hc.pool.IdleSessionTimeout > 0 {
+			hc.pool.evictIdleSessions(now)
+		}
+
 		// Get the maximum number of sessions in use during the current
 		// maintenance window.
 		maxSessionsInUseDuringWindow := hc.pool.mw.maxSessionsCheckedOutDuringWindow()
+		timer := hc.pool.clock.NewTimer(hc.sampleInterval)
 		hc.mu.Lock()
 		ctx, cancel := context.WithTimeout(context.Background(), hc.sampleInterval)
 		hc.maintainerCancel = cancel
@@ -1567,19 +1917,21 @@ func (hc *healthChecker) maintainer() {
 		// Grow or shrink pool if needed.
 		// The number of sessions in the pool should be in the range
 		// [Config.MinOpened, Config.MaxIdle+maxSessionsInUseDuringWindow]
-		if currSessionsOpened < minOpened {
+		// The pool is not resized while maintenance is paused.
+		if !paused && currSessionsOpened < minOpened {
 			if err := hc.growPoolInBatch(ctx, minOpened); err != nil {
 				logf(hc.pool.sc.logger, "failed to grow pool: %v", err)
 			}
-		} else if maxIdle+maxSessionsInUseDuringWindow < currSessionsOpened {
+		} else if !paused && maxIdle+maxSessionsInUseDuringWindow < currSessionsOpened {
 			hc.shrinkPool(ctx, maxIdle+maxSessionsInUseDuringWindow)
 		}
 
 		select {
-		case <-ctx.Done():
+		case <-timer.C():
 		case <-hc.done:
-			cancel()
+			timer.Stop()
 		}
+		cancel()
 		// Cycle the maintenance window. This will remove the oldest cycle and
 		// add a new cycle at the beginning of the maintenance window with the
 		// currently checked out number of sessions as the max number of
@@ -1592,6 +1944,40 @@ func (hc *healthChecker) maintainer() {
 	}
 }
 
+// evictIdleSessions deletes the sessions in the pool that have been idle for
+// longer than IdleSessionTimeout at the given time. It stops deleting
+// sessions when the number of opened sessions has reached MinOpened.
+func (p *sessionPool) evictIdleSessions(now time.Time) {
+	p.mu.Lock()
+	if p.numOpened <= p.MinOpened {
+		p.mu.Unlock()
+		return
+	}
+	maxToEvict := p.numOpened - p.MinOpened
+	var idle []*session
+	for e := p.idleList.Back(); e != nil && uint64(len(idle)) < maxToEvict; e = e.Prev() {
+		s := e.Value.(*session)
+		if now.Sub(s.idleSince) > p.IdleSessionTimeout {
+			idle = append(idle, s)
+		}
+	}
+	p.mu.Unlock()
+
+	var evicted uint64
+	for _, s := range idle {
+		// destroy only removes the session if it is still idle and the pool
+		// has more than MinOpened sessions.
+		if s.destroy(true) {
+			evicted++
+		}
+	}
+	if evicted > 0 {
+		p.mu.Lock()
+		p.numOfIdleSessionsEvicted += evicted
+		p.mu.Unlock()
+	}
+}
+
 func (hc *healthChecker) growPoolInBatch(ctx context.Context, growToNumSessions uint64) error {
 	hc.pool.mu.Lock()
 	defer hc.pool.mu.Unlock()
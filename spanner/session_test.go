@@ -23,7 +23,9 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -1360,6 +1362,329 @@ func TestSessionHealthCheck(t *testing.T) {
 	}
 }
 
+func TestSessionHealthCheck_OnHealthCheckFailure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	type failure struct {
+		sessionID string
+		err       error
+	}
+	failures := make(chan failure, 100)
+	server, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				HealthCheckInterval:       time.Nanosecond,
+				healthCheckSampleInterval: 10 * time.Millisecond,
+				incStep:                   1,
+				OnHealthCheckFailure: func(sessionID string, err error) {
+					select {
+					case failures <- failure{sessionID, err}:
+					default:
+					}
+				},
+			},
+		})
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT 1", &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: &sppb.ResultSetMetadata{RowType: &sppb.StructType{}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sp := client.idleSessions
+
+	sh, err := sp.take(ctx)
+	if err != nil {
+		t.Fatalf("cannot get session from session pool: %v", err)
+	}
+	// Wait for a successful health check of the session.
+	waitFor(t, func() error {
+		for _, id := range server.TestSpanner.DumpPings() {
+			if id == sh.getID() {
+				return nil
+			}
+		}
+		return fmt.Errorf("healthchecker didn't send any ping to session %v", sh.getID())
+	})
+	select {
+	case f := <-failures:
+		t.Fatalf("unexpected health check failure for session %v: %v", f.sessionID, f.err)
+	default:
+	}
+
+	server.TestSpanner.Freeze()
+	server.TestSpanner.PutExecutionTime(MethodExecuteSql,
+		SimulatedExecutionTime{
+			Errors:    []error{newSessionNotFoundError("projects/p/instances/i/databases/d/sessions/s")},
+			KeepError: true,
+		})
+	server.TestSpanner.Unfreeze()
+
+	// The health check of the session that was taken from the pool fails.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case f := <-failures:
+			if !isSessionNotFoundError(f.err) {
+				t.Fatalf("error mismatch\nGot: %v\nWant: Session not found", f.err)
+			}
+			if f.sessionID == sh.getID() {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("OnHealthCheckFailure was not called for session %v", sh.getID())
+		}
+	}
+}
+
+func TestSessionPool_DisableSessionHealthCheck(t *testing.T) {
+	// This test is not run in parallel, as it counts the health checker
+	// goroutines of all session pools in the process.
+	countGoroutines := func(fn string) int {
+		buf := make([]byte, 1<<20)
+		for {
+			n := runtime.Stack(buf, true)
+			if n < len(buf) {
+				buf = buf[:n]
+				break
+			}
+			buf = make([]byte, 2*len(buf))
+		}
+		return strings.Count(string(buf), fn)
+	}
+	const maintainer = "(*healthChecker).maintainer("
+	const worker = "(*healthChecker).worker("
+	maintainersBefore, workersBefore := countGoroutines(maintainer), countGoroutines(worker)
+
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened:                 2,
+				DisableSessionHealthCheck: true,
+			},
+		})
+	defer teardown()
+	if g, w := countGoroutines(maintainer), maintainersBefore; g > w {
+		t.Fatalf("maintainer goroutines mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := countGoroutines(worker), workersBefore; g > w {
+		t.Fatalf("health check worker goroutines mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// The pool still hands out and takes back sessions.
+	sp := client.idleSessions
+	for i := 0; i < 2; i++ {
+		if err := executeSingerQuery(ctx, client.Single()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sh, err := sp.take(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh.recycle()
+	sp.mu.Lock()
+	numOpened, numIdle := sp.numOpened, sp.idleList.Len()
+	sp.mu.Unlock()
+	if g, w := uint64(numIdle), numOpened; g != w {
+		t.Fatalf("idle sessions mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestSessionPool_IdleSessionTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened:                 2,
+				MaxOpened:                 10,
+				MaxIdle:                   10,
+				IdleSessionTimeout:        200 * time.Millisecond,
+				healthCheckSampleInterval: 10 * time.Millisecond,
+			},
+		})
+	defer teardown()
+	sp := client.idleSessions
+	waitFor(t, func() error {
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		if sp.idleList.Len() != 2 {
+			return fmt.Errorf("got %d idle sessions, want 2", sp.idleList.Len())
+		}
+		return nil
+	})
+
+	// Check out more sessions than MinOpened and return them to the pool. MaxIdle
+	// prevents the pool from shrinking before the sessions time out.
+	var shs []*sessionHandle
+	for i := 0; i < 6; i++ {
+		sh, err := sp.take(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shs = append(shs, sh)
+	}
+	for _, sh := range shs {
+		sh.recycle()
+	}
+	sp.mu.Lock()
+	opened := sp.numOpened
+	evicted := sp.numOfIdleSessionsEvicted
+	sp.mu.Unlock()
+	if opened < 6 {
+		t.Fatalf("got %d opened sessions, want at least 6", opened)
+	}
+	if evicted != 0 {
+		t.Fatalf("%d sessions were evicted before they reached the idle timeout", evicted)
+	}
+
+	// The idle sessions are deleted down to MinOpened.
+	waitFor(t, func() error {
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		if g, w := sp.numOpened, uint64(2); g != w {
+			return fmt.Errorf("opened session count mismatch\nGot: %d\nWant: %d", g, w)
+		}
+		return nil
+	})
+	// The pool does not go below MinOpened.
+	time.Sleep(300 * time.Millisecond)
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if g, w := sp.numOpened, uint64(2); g != w {
+		t.Fatalf("opened session count mismatch after timeout\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := sp.numOfIdleSessionsEvicted, opened-2; g != w {
+		t.Fatalf("evicted session count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+func TestSessionPool_IdleSessionTimeoutWithFakeClock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clock := newFakeClock()
+	_, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened:          2,
+				MaxOpened:          10,
+				MaxIdle:            10,
+				IdleSessionTimeout: 10 * time.Minute,
+				clock:              clock,
+			},
+		})
+	defer teardown()
+	sp := client.idleSessions
+	waitFor(t, func() error {
+		sp.mu.Lock()
+		defer sp.mu.Unlock()
+		if sp.idleList.Len() != 2 {
+			return fmt.Errorf("got %d idle sessions, want 2", sp.idleList.Len())
+		}
+		return nil
+	})
+
+	var shs []*sessionHandle
+	for i := 0; i < 6; i++ {
+		sh, err := sp.take(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shs = append(shs, sh)
+	}
+	for _, sh := range shs {
+		sh.recycle()
+	}
+	sp.mu.Lock()
+	opened := sp.numOpened
+	sp.mu.Unlock()
+
+	// Run a maintenance cycle before the sessions reach the idle timeout.
+	interval := sp.healthCheckSampleInterval
+	n := clock.numTimers(interval)
+	clock.advance(5 * time.Minute)
+	clock.waitForTimers(t, interval, n+1)
+	sp.mu.Lock()
+	if g, w := sp.numOfIdleSessionsEvicted, uint64(0); g != w {
+		sp.mu.Unlock()
+		t.Fatalf("evicted session count mismatch before timeout\nGot: %d\nWant: %d", g, w)
+	}
+	sp.mu.Unlock()
+
+	// Run a maintenance cycle after the sessions reached the idle timeout.
+	n = clock.numTimers(interval)
+	clock.advance(6 * time.Minute)
+	clock.waitForTimers(t, interval, n+1)
+	stats := client.PoolStats()
+	if g, w := stats.NumOpened, uint64(2); g != w {
+		t.Fatalf("opened session count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+	if g, w := stats.NumIdleSessionsEvicted, opened-2; g != w {
+		t.Fatalf("evicted session count mismatch\nGot: %d\nWant: %d", g, w)
+	}
+}
+
+func TestClient_PauseMaintenance(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServerWithConfig(t,
+		ClientConfig{
+			SessionPoolConfig: SessionPoolConfig{
+				MinOpened:                 5,
+				HealthCheckInterval:       20 * time.Millisecond,
+				healthCheckSampleInterval: 10 * time.Millisecond,
+			},
+		})
+	defer teardown()
+	if err := server.TestSpanner.PutStatementResult("SELECT 1", &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: &sppb.ResultSet{Metadata: &sppb.ResultSetMetadata{RowType: &sppb.StructType{}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sp := client.idleSessions
+
+	// Wait until the health checker has started to send pings.
+	waitFor(t, func() error {
+		if len(server.TestSpanner.DumpPings()) == 0 {
+			return fmt.Errorf("healthchecker didn't send any ping")
+		}
+		return nil
+	})
+
+	client.PauseMaintenance()
+	// Pings that were already in flight when maintenance was paused can still
+	// reach the server. No new pings are sent after those have finished.
+	waitFor(t, func() error {
+		server.TestSpanner.ClearPings()
+		time.Sleep(100 * time.Millisecond)
+		if g := len(server.TestSpanner.DumpPings()); g != 0 {
+			return fmt.Errorf("got %d pings while maintenance was paused, want 0", g)
+		}
+		return nil
+	})
+	server.TestSpanner.ClearPings()
+	time.Sleep(200 * time.Millisecond)
+	if g := len(server.TestSpanner.DumpPings()); g != 0 {
+		t.Fatalf("got %d pings while maintenance was paused, want 0", g)
+	}
+	sp.mu.Lock()
+	numOpened := sp.numOpened
+	sp.mu.Unlock()
+	if numOpened == 0 {
+		t.Fatal("session pool was closed while maintenance was paused")
+	}
+
+	client.ResumeMaintenance()
+	waitFor(t, func() error {
+		if len(server.TestSpanner.DumpPings()) == 0 {
+			return fmt.Errorf("healthchecker didn't send any ping after maintenance was resumed")
+		}
+		return nil
+	})
+}
+
 // TestStressSessionPool does stress test on session pool by the following concurrent operations:
 //  1. Test worker gets a session from the pool.
 //  2. Test worker turns a session back into the pool.
@@ -1672,6 +1997,92 @@ func (s1 *session) Equal(s2 *session) bool {
 		bytes.Equal(s1.tx, s2.tx)
 }
 
+// fakeClock is a clock that only advances when advance is called.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	// created counts the timers that have been created per duration.
+	created map[time.Duration]int
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now(), created: make(map[time.Duration]int)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) clockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{c: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.created[d]++
+	return t
+}
+
+// advance advances the clock and fires all timers that have expired.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	var pending []*fakeTimer
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// numTimers returns the number of timers with the given duration that have
+// been created.
+func (c *fakeClock) numTimers(d time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.created[d]
+}
+
+// waitForTimers waits until n timers with the given duration have been
+// created.
+func (c *fakeClock) waitForTimers(t *testing.T, d time.Duration, n int) {
+	t.Helper()
+	waitFor(t, func() error {
+		if g := c.numTimers(d); g < n {
+			return fmt.Errorf("timer count mismatch\nGot: %d\nWant: %d", g, n)
+		}
+		return nil
+	})
+}
+
+type fakeTimer struct {
+	c        *fakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	for i, pending := range t.c.timers {
+		if pending == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func waitFor(t *testing.T, assert func() error) {
 	t.Helper()
 	timeout := 15 * time.Second
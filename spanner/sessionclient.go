@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/internal/trace"
@@ -36,6 +38,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var cidGen = newClientIDGenerator()
@@ -78,6 +81,52 @@ type sessionConsumer interface {
 	// sessions that could not be created as a result of this error. A
 	// consumer may receive multiple errors per batch.
 	sessionCreationFailed(err error, numSessions int32)
+
+	// batchComplete is called once after all sessions and errors of a call to
+	// batchCreateSessions have been passed to the consumer. The created and
+	// failed arguments specify the total number of sessions that were created
+	// and that could not be created for the batch.
+	batchComplete(created, failed int32)
+}
+
+// batchCreateSessionsTracker counts the sessions that are created and that
+// fail for a call to batchCreateSessions, and calls batchComplete on the
+// consumer when all sub-batches of the call have finished.
+type batchCreateSessionsTracker struct {
+	consumer sessionConsumer
+	// pending is the number of sub-batches that are still being executed,
+	// plus one for the batchCreateSessions call itself while it is starting
+	// the sub-batches.
+	pending int32
+	created int32
+	failed  int32
+}
+
+func newBatchCreateSessionsTracker(consumer sessionConsumer) *batchCreateSessionsTracker {
+	return &batchCreateSessionsTracker{consumer: consumer, pending: 1}
+}
+
+func (t *batchCreateSessionsTracker) sessionReady(s *session) {
+	atomic.AddInt32(&t.created, 1)
+	t.consumer.sessionReady(s)
+}
+
+func (t *batchCreateSessionsTracker) sessionCreationFailed(err error, numSessions int32) {
+	atomic.AddInt32(&t.failed, numSessions)
+	t.consumer.sessionCreationFailed(err, numSessions)
+}
+
+// start registers a sub-batch that is being executed.
+func (t *batchCreateSessionsTracker) start() {
+	atomic.AddInt32(&t.pending, 1)
+}
+
+// done marks a sub-batch, or the batchCreateSessions call itself, as
+// finished, and calls batchComplete on the consumer if it was the last one.
+func (t *batchCreateSessionsTracker) done() {
+	if atomic.AddInt32(&t.pending, -1) == 0 {
+		t.consumer.batchComplete(atomic.LoadInt32(&t.created), atomic.LoadInt32(&t.failed))
+	}
 }
 
 // sessionClient creates sessions for a database, either in batches or one at a
@@ -89,17 +138,18 @@ type sessionClient struct {
 	closed               bool
 	disableRouteToLeader bool
 
-	connPool      gtransport.ConnPool
-	database      string
-	id            string
-	userAgent     string
-	sessionLabels map[string]string
-	databaseRole  string
-	md            metadata.MD
-	batchTimeout  time.Duration
-	logger        *log.Logger
-	callOptions   *vkit.CallOptions
-	otConfig      *openTelemetryConfig
+	connPool             gtransport.ConnPool
+	database             string
+	id                   string
+	userAgent            string
+	sessionLabels        map[string]string
+	databaseRole         string
+	databaseRoleProvider func() string
+	md                   metadata.MD
+	batchTimeout         time.Duration
+	logger               *log.Logger
+	callOptions          *vkit.CallOptions
+	otConfig             *openTelemetryConfig
 }
 
 // newSessionClient creates a session client to use for a database.
@@ -119,6 +169,14 @@ func newSessionClient(connPool gtransport.ConnPool, database, userAgent string,
 	}
 }
 
+// creatorRole returns the database role to create new sessions with.
+func (sc *sessionClient) creatorRole() string {
+	if sc.databaseRoleProvider != nil {
+		return sc.databaseRoleProvider()
+	}
+	return sc.databaseRole
+}
+
 func (sc *sessionClient) close() error {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
@@ -143,7 +201,7 @@ func (sc *sessionClient) createSession(ctx context.Context) (*session, error) {
 	var md metadata.MD
 	sid, err := client.CreateSession(contextWithOutgoingMetadata(ctx, sc.md, sc.disableRouteToLeader), &sppb.CreateSessionRequest{
 		Database: sc.database,
-		Session:  &sppb.Session{Labels: sc.sessionLabels, CreatorRole: sc.databaseRole},
+		Session:  &sppb.Session{Labels: sc.sessionLabels, CreatorRole: sc.creatorRole()},
 	}, gax.WithGRPCOptions(grpc.Header(&md)))
 
 	if getGFELatencyMetricsFlag() && md != nil {
@@ -183,7 +241,9 @@ func (sc *sessionClient) createSession(ctx context.Context) (*session, error) {
 // receive any number of sessions + any number of errors, where each error will
 // include the number of sessions that could not be created as a result of the
 // error. The sum of returned sessions and errored sessions will be equal to
-// the number of requested sessions.
+// the number of requested sessions. The sessionConsumer will receive one call
+// to batchComplete with these totals after all sessions and errors have been
+// returned.
 // If distributeOverChannels is true, the sessions will be equally distributed
 // over all the channels that are in use by the client.
 func (sc *sessionClient) batchCreateSessions(createSessionCount int32, distributeOverChannels bool, consumer sessionConsumer) error {
@@ -206,6 +266,8 @@ func (sc *sessionClient) batchCreateSessions(createSessionCount int32, distribut
 	if sc.closed {
 		return spannerErrorf(codes.FailedPrecondition, "SessionClient is closed")
 	}
+	tracker := newBatchCreateSessionsTracker(consumer)
+	defer tracker.done()
 	// Spread the session creation over all available gRPC channels. Spanner
 	// will maintain server side caches for a session on the gRPC channel that
 	// is used by the session. A session should therefore always use the same
@@ -231,7 +293,11 @@ func (sc *sessionClient) batchCreateSessions(createSessionCount int32, distribut
 			createCountForChannel += remainder
 		}
 		if createCountForChannel > 0 {
-			go sc.executeBatchCreateSessions(client, createCountForChannel, sc.sessionLabels, sc.md, consumer)
+			tracker.start()
+			go func(client *vkit.Client, createCount int32) {
+				defer tracker.done()
+				sc.executeBatchCreateSessions(client, createCount, sc.sessionLabels, sc.md, tracker)
+			}(client, createCountForChannel)
 			numBeingCreated += createCountForChannel
 		}
 	}
@@ -240,7 +306,7 @@ func (sc *sessionClient) batchCreateSessions(createSessionCount int32, distribut
 
 // executeBatchCreateSessions executes the gRPC call for creating a batch of
 // sessions.
-func (sc *sessionClient) executeBatchCreateSessions(client *vkit.Client, createCount int32, labels map[string]string, md metadata.MD, consumer sessionConsumer) {
+func (sc *sessionClient) executeBatchCreateSessions(client *vkit.Client, createCount int32, labels map[string]string, md metadata.MD, consumer *batchCreateSessionsTracker) {
 	ctx, cancel := context.WithTimeout(context.Background(), sc.batchTimeout)
 	defer cancel()
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.BatchCreateSessions")
@@ -266,7 +332,7 @@ func (sc *sessionClient) executeBatchCreateSessions(client *vkit.Client, createC
 		response, err := client.BatchCreateSessions(contextWithOutgoingMetadata(ctx, sc.md, sc.disableRouteToLeader), &sppb.BatchCreateSessionsRequest{
 			SessionCount:    remainingCreateCount,
 			Database:        sc.database,
-			SessionTemplate: &sppb.Session{Labels: labels, CreatorRole: sc.databaseRole},
+			SessionTemplate: &sppb.Session{Labels: labels, CreatorRole: sc.creatorRole()},
 		}, gax.WithGRPCOptions(grpc.Header(&mdForGFELatency)))
 
 		if getGFELatencyMetricsFlag() && mdForGFELatency != nil {
@@ -375,3 +441,124 @@ func mergeCallOptions(a *vkit.CallOptions, b *vkit.CallOptions) *vkit.CallOption
 	}
 	return res
 }
+
+// SessionCreationBackoff configures the backoff between the retries of the
+// CreateSession and BatchCreateSessions RPCs that the client uses to create
+// sessions. These RPCs are retried for UNAVAILABLE and RESOURCE_EXHAUSTED
+// errors. The zero value keeps the default backoff of the client.
+//
+// After each retry, the delay is multiplied by Multiplier, up to Max. Jitter
+// is the fraction of the delay that is random: a delay d is replaced by a
+// random duration between d*(1-Jitter) and d. Spreading the retries of many
+// clients over time prevents them from retrying in lockstep after an outage.
+type SessionCreationBackoff struct {
+	// Initial is the delay before the first retry. Defaults to 250ms.
+	Initial time.Duration
+	// Max is the maximum delay between two retries. Defaults to 32s.
+	Max time.Duration
+	// Multiplier is the factor by which the delay increases after each retry.
+	// Must be at least 1. Defaults to 1.3.
+	Multiplier float64
+	// Jitter is the fraction of each delay that is random. Must be between 0
+	// and 1, where 0 disables the jitter. Defaults to 1 if nil, in which case
+	// each delay is a random duration between 0 and the calculated delay.
+	Jitter *float64
+}
+
+// errInvalidSessionCreationBackoff returns error for a SessionCreationBackoff
+// with invalid values.
+func errInvalidSessionCreationBackoff(b SessionCreationBackoff) error {
+	jitter := "nil"
+	if b.Jitter != nil {
+		jitter = fmt.Sprint(*b.Jitter)
+	}
+	return spannerErrorf(codes.InvalidArgument,
+		"require ClientConfig.SessionCreationBackoff with Initial >= 0, Max >= 0, Multiplier == 0 || Multiplier >= 1 and 0 <= Jitter <= 1, got Initial: %v, Max: %v, Multiplier: %v, Jitter: %v", b.Initial, b.Max, b.Multiplier, jitter)
+}
+
+// validate returns an error if the backoff contains invalid values.
+func (b SessionCreationBackoff) validate() error {
+	if b.Initial < 0 || b.Max < 0 || (b.Multiplier != 0 && b.Multiplier < 1) || (b.Jitter != nil && (*b.Jitter < 0 || *b.Jitter > 1)) {
+		return errInvalidSessionCreationBackoff(b)
+	}
+	return nil
+}
+
+// callOptions returns the given CallOptions with the retry settings of the
+// RPCs that create sessions replaced by retry settings that use this
+// backoff and retry the errors that rc classifies as retryable. The given
+// CallOptions are returned as is if b is the zero value and rc is nil.
+func (b SessionCreationBackoff) callOptions(co *vkit.CallOptions, rc RetryClassifier) *vkit.CallOptions {
+	if b == (SessionCreationBackoff{}) && rc == nil {
+		return co
+	}
+	if rc == nil {
+		rc = DefaultRetryClassifier{}
+	}
+	if co == nil {
+		co = &vkit.CallOptions{}
+	}
+	retry := gax.WithRetry(func() gax.Retryer {
+		return newJitterRetryer(b, rc)
+	})
+	return mergeCallOptions(co, &vkit.CallOptions{
+		CreateSession:       []gax.CallOption{retry},
+		BatchCreateSessions: []gax.CallOption{retry},
+	})
+}
+
+// jitterRetryer is a gax.Retryer that retries the errors that a
+// RetryClassifier classifies as retryable for session creation with an
+// exponential backoff with a configurable amount of jitter.
+type jitterRetryer struct {
+	classifier RetryClassifier
+	cur        time.Duration
+	max        time.Duration
+	multiplier float64
+	jitter     float64
+	rand       *rand.Rand
+}
+
+func newJitterRetryer(b SessionCreationBackoff, rc RetryClassifier) *jitterRetryer {
+	r := &jitterRetryer{
+		classifier: rc,
+		cur:        b.Initial,
+		max:        b.Max,
+		multiplier: b.Multiplier,
+		jitter:     1,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if r.cur == 0 {
+		r.cur = 250 * time.Millisecond
+	}
+	if r.max == 0 {
+		r.max = 32 * time.Second
+	}
+	if r.multiplier == 0 {
+		r.multiplier = 1.3
+	}
+	if b.Jitter != nil {
+		r.jitter = *b.Jitter
+	}
+	return r
+}
+
+// Retry implements gax.Retryer.
+func (r *jitterRetryer) Retry(err error) (time.Duration, bool) {
+	if _, ok := status.FromError(err); !ok {
+		return 0, false
+	}
+	if !r.classifier.IsRetryable(RetryOperationCreateSession, err) {
+		return 0, false
+	}
+	delay := r.cur
+	if delay > r.max {
+		delay = r.max
+	}
+	delay -= time.Duration(r.rand.Float64() * r.jitter * float64(delay))
+	r.cur = time.Duration(float64(r.cur) * r.multiplier)
+	if r.cur > r.max {
+		r.cur = r.max
+	}
+	return delay, true
+}
@@ -50,6 +50,11 @@ type testConsumer struct {
 	numErr   int32
 
 	receivedAll chan struct{}
+
+	completeCreated int32
+	completeFailed  int32
+	numComplete     int32
+	complete        chan struct{}
 }
 
 func (tc *testConsumer) sessionReady(s *session) {
@@ -70,6 +75,17 @@ func (tc *testConsumer) sessionCreationFailed(err error, num int32) {
 	tc.checkReceivedAll()
 }
 
+func (tc *testConsumer) batchComplete(created, failed int32) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.completeCreated = created
+	tc.completeFailed = failed
+	tc.numComplete++
+	if tc.numComplete == 1 {
+		close(tc.complete)
+	}
+}
+
 func (tc *testConsumer) checkReceivedAll() {
 	if int32(len(tc.sessions))+tc.numErr == tc.numExpected {
 		close(tc.receivedAll)
@@ -80,6 +96,7 @@ func newTestConsumer(numExpected int32) *testConsumer {
 	return &testConsumer{
 		numExpected: numExpected,
 		receivedAll: make(chan struct{}),
+		complete:    make(chan struct{}),
 	}
 }
 
@@ -390,6 +407,52 @@ func TestBatchCreateSessionsWithExceptions(t *testing.T) {
 	}
 }
 
+func TestBatchCreateSessions_BatchComplete(t *testing.T) {
+	t.Parallel()
+
+	numSessions := int32(100)
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		NumChannels: numChannels,
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 0,
+			MaxOpened: 400,
+		},
+	})
+	defer teardown()
+	// Make the second BatchCreateSessions call fail.
+	server.TestSpanner.PutExecutionTime(MethodBatchCreateSession, SimulatedExecutionTime{
+		Errors: []error{nil, status.Errorf(codes.FailedPrecondition, "session creation failed")},
+	})
+	consumer := newTestConsumer(numSessions)
+	if err := client.sc.batchCreateSessions(numSessions, true, consumer); err != nil {
+		t.Fatal(err)
+	}
+	<-consumer.receivedAll
+	select {
+	case <-consumer.complete:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batchComplete was not called")
+	}
+
+	consumer.mu.Lock()
+	defer consumer.mu.Unlock()
+	if g, w := consumer.numComplete, int32(1); g != w {
+		t.Fatalf("batchComplete call count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := consumer.completeCreated, int32(len(consumer.sessions)); g != w {
+		t.Fatalf("created count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := consumer.completeFailed, consumer.numErr; g != w {
+		t.Fatalf("failed count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if consumer.completeFailed == 0 || consumer.completeCreated == 0 {
+		t.Fatalf("expected a partial failure, got %d created and %d failed sessions", consumer.completeCreated, consumer.completeFailed)
+	}
+	if g, w := consumer.completeCreated+consumer.completeFailed, numSessions; g != w {
+		t.Fatalf("total session count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
 func TestBatchCreateSessions_ServerReturnsLessThanRequestedSessions(t *testing.T) {
 	t.Parallel()
 
@@ -588,3 +651,101 @@ func TestMergeCallOptions(t *testing.T) {
 		t.Fatalf("merged CallOptions is incorrect: got %v, want %v", got, want)
 	}
 }
+
+func TestJitterRetryer(t *testing.T) {
+	jitter := 0.5
+	r := newJitterRetryer(SessionCreationBackoff{
+		Initial:    100 * time.Millisecond,
+		Max:        300 * time.Millisecond,
+		Multiplier: 2,
+		Jitter:     &jitter,
+	}, DefaultRetryClassifier{})
+	for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond} {
+		delay, retry := r.Retry(status.Error(codes.Unavailable, "unavailable"))
+		if !retry {
+			t.Fatalf("%d: Unavailable error was not retried", i)
+		}
+		if delay < want/2 || delay > want {
+			t.Fatalf("%d: delay %v is not between %v and %v", i, delay, want/2, want)
+		}
+	}
+	if _, retry := r.Retry(status.Error(codes.InvalidArgument, "invalid")); retry {
+		t.Fatal("InvalidArgument error was retried")
+	}
+
+	// A Jitter of 0 disables the jitter.
+	noJitter := 0.0
+	r = newJitterRetryer(SessionCreationBackoff{
+		Initial:    100 * time.Millisecond,
+		Max:        300 * time.Millisecond,
+		Multiplier: 2,
+		Jitter:     &noJitter,
+	}, DefaultRetryClassifier{})
+	for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		if delay, _ := r.Retry(status.Error(codes.Unavailable, "unavailable")); delay != want {
+			t.Fatalf("%d: delay mismatch without jitter\nGot: %v\nWant: %v", i, delay, want)
+		}
+	}
+}
+
+func TestSessionCreationBackoff_CallOptions(t *testing.T) {
+	co := &vkit.CallOptions{}
+	if got := (SessionCreationBackoff{}).callOptions(co, nil); got != co {
+		t.Fatalf("zero SessionCreationBackoff changed the CallOptions: %v", got)
+	}
+	for _, merged := range []*vkit.CallOptions{
+		SessionCreationBackoff{Initial: time.Millisecond}.callOptions(co, nil),
+		SessionCreationBackoff{}.callOptions(co, DefaultRetryClassifier{}),
+	} {
+		for _, opts := range [][]gax.CallOption{merged.CreateSession, merged.BatchCreateSessions} {
+			cs := &gax.CallSettings{}
+			for _, o := range opts {
+				o.Resolve(cs)
+			}
+			if _, ok := cs.Retry().(*jitterRetryer); !ok {
+				t.Fatalf("retryer type mismatch\nGot: %T\nWant: %T", cs.Retry(), &jitterRetryer{})
+			}
+		}
+	}
+	invalidJitter := 1.5
+	for _, b := range []SessionCreationBackoff{
+		{Initial: -time.Millisecond},
+		{Multiplier: 0.5},
+		{Jitter: &invalidJitter},
+	} {
+		if err := b.validate(); ErrCode(err) != codes.InvalidArgument {
+			t.Fatalf("error code mismatch for %+v\nGot: %v\nWant: %v", b, ErrCode(err), codes.InvalidArgument)
+		}
+	}
+}
+
+func TestBatchCreateSessions_SessionCreationBackoff(t *testing.T) {
+	t.Parallel()
+
+	jitter := 0.5
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened: 0,
+			MaxOpened: 100,
+		},
+		SessionCreationBackoff: SessionCreationBackoff{Initial: time.Millisecond, Jitter: &jitter},
+	})
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodBatchCreateSession, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.Unavailable, "unavailable"), status.Error(codes.Unavailable, "unavailable")},
+	})
+	iter := client.Single().Query(context.Background(), NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	defer iter.Stop()
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+	var attempts int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if _, ok := req.(*sppb.BatchCreateSessionsRequest); ok {
+			attempts++
+		}
+	}
+	if g, w := attempts, 3; g != w {
+		t.Fatalf("BatchCreateSessions attempt count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
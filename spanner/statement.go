@@ -18,6 +18,8 @@ package spanner
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	"google.golang.org/grpc/codes"
@@ -68,6 +70,41 @@ func (s *Statement) convertParams() (*structpb.Struct, map[string]*sppb.Type, er
 	return params, paramTypes, nil
 }
 
+// checkZeroTime returns an error if any of the parameters of the statement
+// contains a zero time.Time value.
+func (s *Statement) checkZeroTime() error {
+	for k, v := range s.Params {
+		if containsZeroTime(v) {
+			return errZeroTime(fmt.Sprintf("parameter %q", k))
+		}
+	}
+	return nil
+}
+
+// emptyStringsAsNull returns a copy of the statement in which the parameters
+// that are empty strings are replaced by NULL values. The statement is
+// returned as is if it does not contain any empty strings.
+func (s Statement) emptyStringsAsNull() Statement {
+	var params map[string]interface{}
+	for k, v := range s.Params {
+		nv, ok := emptyStringAsNull(v)
+		if !ok {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]interface{}, len(s.Params))
+			for pk, pv := range s.Params {
+				params[pk] = pv
+			}
+		}
+		params[k] = nv
+	}
+	if params != nil {
+		s.Params = params
+	}
+	return s
+}
+
 // errBindParam returns error for not being able to bind parameter to query
 // request.
 func errBindParam(k string, v interface{}, err error) error {
@@ -81,3 +118,63 @@ func errBindParam(k string, v interface{}, err error) error {
 	se.decorate(fmt.Sprintf("failed to bind query parameter(name: %q, value: %v)", k, v))
 	return se
 }
+
+// InStatement returns a SQL fragment that checks whether column is equal to
+// one of the given values, together with the array parameter that must be
+// bound to the fragment and the name of that parameter. values must be a Go
+// slice of a type that can be used as a query parameter, for example []int64,
+// []string or [][]byte. The returned parameter is a copy of values, so values
+// may be modified after InStatement returns.
+//
+// The fragment uses the form "column IN UNNEST(@name)", where the name of the
+// parameter is derived from the column name. The result can be composed into
+// a larger statement:
+//
+//	fragment, param, name := spanner.InStatement("SingerId", []int64{1, 2, 3})
+//	stmt := spanner.Statement{
+//		SQL:    "SELECT FirstName FROM Singers WHERE " + fragment,
+//		Params: map[string]interface{}{name: param},
+//	}
+func InStatement(column string, values interface{}) (fragment string, param interface{}, name string) {
+	name = inParamName(column)
+	return fmt.Sprintf("%s IN UNNEST(@%s)", column, name), copySliceParam(values), name
+}
+
+// inParamName returns a valid query parameter name for an IN-list on the
+// given column. Characters that are not allowed in a parameter name, such as
+// the dot in a qualified column name, are replaced by underscores.
+func inParamName(column string) string {
+	var b strings.Builder
+	b.WriteString("in_")
+	for _, r := range column {
+		switch {
+		case r == '`':
+			// Skip quotes of quoted identifiers.
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// copySliceParam returns a copy of the given slice, or the given array as a
+// slice of the same element type. Other values are returned unchanged.
+func copySliceParam(values interface{}) interface{} {
+	v := reflect.ValueOf(values)
+	var c reflect.Value
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return values
+		}
+		c = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	case reflect.Array:
+		c = reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), v.Len(), v.Len())
+	default:
+		return values
+	}
+	reflect.Copy(c, v)
+	return c.Interface()
+}
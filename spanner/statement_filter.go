@@ -0,0 +1,123 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrStatementRejected is wrapped in the error that is returned for a SQL
+// statement that is not allowed by ClientConfig.StatementAllowPrefixes or
+// ClientConfig.StatementDenyPrefixes. Use errors.Is to check for this error.
+var ErrStatementRejected = errors.New("spanner: statement rejected")
+
+// statementFilter checks the SQL statements of a Client against the allowed
+// and denied statement prefixes of the Client.
+type statementFilter struct {
+	// allow and deny contain the lower case prefixes.
+	allow []string
+	deny  []string
+}
+
+// newStatementFilter returns a statementFilter for the given prefixes, or nil
+// if no prefixes are given.
+func newStatementFilter(allow, deny []string) *statementFilter {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+	f := &statementFilter{}
+	for _, p := range allow {
+		f.allow = append(f.allow, strings.ToLower(p))
+	}
+	for _, p := range deny {
+		f.deny = append(f.deny, strings.ToLower(p))
+	}
+	return f
+}
+
+// check returns an error that wraps ErrStatementRejected if sql is not
+// allowed by f. A nil statementFilter allows all statements.
+func (f *statementFilter) check(sql string) error {
+	if f == nil {
+		return nil
+	}
+	s := strings.ToLower(trimLeadingComments(sql))
+	for _, p := range f.deny {
+		if strings.HasPrefix(s, p) {
+			return errStatementRejected(sql, fmt.Sprintf("matches denied prefix %q", p))
+		}
+	}
+	if len(f.allow) == 0 {
+		return nil
+	}
+	for _, p := range f.allow {
+		if strings.HasPrefix(s, p) {
+			return nil
+		}
+	}
+	return errStatementRejected(sql, "does not match any allowed prefix")
+}
+
+// errStatementRejected returns an error that wraps ErrStatementRejected.
+func errStatementRejected(sql, reason string) error {
+	return &Error{
+		Code: codes.InvalidArgument,
+		err:  ErrStatementRejected,
+		Desc: fmt.Sprintf("statement %q %s", sql, reason),
+	}
+}
+
+// trimLeadingComments returns sql without the leading white space, comments
+// and statement hints, so these cannot be used to hide the prefix of a
+// statement.
+func trimLeadingComments(sql string) string {
+	for {
+		sql = strings.TrimLeftFunc(sql, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(sql, "--"), strings.HasPrefix(sql, "#"):
+			i := strings.IndexByte(sql, '\n')
+			if i < 0 {
+				return ""
+			}
+			sql = sql[i+1:]
+		case strings.HasPrefix(sql, "/*"):
+			i := strings.Index(sql[2:], "*/")
+			if i < 0 {
+				return ""
+			}
+			sql = sql[i+4:]
+		case strings.HasPrefix(sql, "@"):
+			// A statement hint, for example @{USE_ADDITIONAL_PARALLELISM=TRUE}.
+			rest := strings.TrimLeftFunc(sql[1:], unicode.IsSpace)
+			if !strings.HasPrefix(rest, "{") {
+				return sql
+			}
+			i := strings.IndexByte(rest, '}')
+			if i < 0 {
+				return ""
+			}
+			sql = rest[i+1:]
+		default:
+			return sql
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	. "cloud.google.com/go/spanner/internal/testutil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatementFilter(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		allow, deny []string
+		sql         string
+		want        bool
+	}{
+		{nil, nil, "DELETE FROM Singers WHERE TRUE", true},
+		{nil, []string{"DELETE"}, "SELECT * FROM Singers", true},
+		{nil, []string{"DELETE"}, "DELETE FROM Singers WHERE TRUE", false},
+		{nil, []string{"DELETE"}, "  delete from Singers where true", false},
+		{nil, []string{"DELETE"}, "-- comment\n/* block\ncomment */ # comment\n DELETE FROM Singers WHERE TRUE", false},
+		{[]string{"SELECT"}, nil, "SELECT 1", true},
+		{[]string{"SELECT"}, nil, "select 1", true},
+		{[]string{"SELECT"}, nil, "UPDATE Singers SET Name='' WHERE TRUE", false},
+		{[]string{"SELECT", "UPDATE"}, []string{"UPDATE Singers"}, "UPDATE Albums SET Title='' WHERE TRUE", true},
+		{[]string{"SELECT", "UPDATE"}, []string{"UPDATE Singers"}, "UPDATE Singers SET Name='' WHERE TRUE", false},
+		{[]string{"SELECT"}, nil, "/* unterminated comment SELECT 1", false},
+		{nil, []string{"DELETE"}, "@{PDML_MAX_PARALLELISM=10} DELETE FROM Singers WHERE TRUE", false},
+		{nil, []string{"DELETE"}, "/* hint */ @ {LOCK_SCANNED_RANGES=exclusive}\n@{USE_ADDITIONAL_PARALLELISM=TRUE} DELETE FROM Singers WHERE TRUE", false},
+		{[]string{"SELECT"}, nil, "@{USE_ADDITIONAL_PARALLELISM=TRUE} SELECT 1", true},
+		{[]string{"SELECT"}, nil, "@{unterminated hint SELECT 1", false},
+	} {
+		err := newStatementFilter(tt.allow, tt.deny).check(tt.sql)
+		if got := err == nil; got != tt.want {
+			t.Errorf("allowed mismatch for %q with allow %v and deny %v\nGot: %v\nWant: %v", tt.sql, tt.allow, tt.deny, got, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrStatementRejected) {
+			t.Errorf("error for %q does not wrap ErrStatementRejected: %v", tt.sql, err)
+		}
+	}
+}
+
+func TestClient_StatementPrefixes(t *testing.T) {
+	t.Parallel()
+
+	const deleteAll = "DELETE FROM Albums WHERE TRUE"
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		StatementAllowPrefixes: []string{"SELECT", "UPDATE", "DELETE"},
+		StatementDenyPrefixes:  []string{"DELETE FROM Albums"},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if err := iter.Do(func(r *Row) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error for allowed query: %v", err)
+	}
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}); err != nil {
+		t.Fatalf("Unexpected error for allowed update: %v", err)
+	}
+	drainRequestsFromServer(server.TestSpanner)
+
+	checkRejected := func(name string, err error) {
+		t.Helper()
+		if !errors.Is(err, ErrStatementRejected) {
+			t.Fatalf("%s: error mismatch\nGot: %v\nWant: %v", name, err, ErrStatementRejected)
+		}
+		if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+			t.Fatalf("%s: error code mismatch\nGot: %v\nWant: %v", name, g, w)
+		}
+	}
+	iter = client.Single().Query(ctx, NewStatement("INSERT INTO Albums (AlbumId) VALUES (1)"))
+	_, err := iter.Next()
+	iter.Stop()
+	checkRejected("query not matching an allowed prefix", err)
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, NewStatement("/* all */ "+deleteAll))
+		return err
+	})
+	checkRejected("update", err)
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.BatchUpdate(ctx, []Statement{NewStatement(UpdateBarSetFoo), NewStatement(deleteAll)})
+		return err
+	})
+	checkRejected("batch update", err)
+	_, err = client.PartitionedUpdate(ctx, NewStatement(deleteAll))
+	checkRejected("partitioned update", err)
+
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req := req.(type) {
+		case *sppb.ExecuteSqlRequest:
+			t.Fatalf("rejected statement was sent to Spanner: %v", req.Sql)
+		case *sppb.ExecuteBatchDmlRequest:
+			t.Fatal("rejected batch was sent to Spanner")
+		}
+	}
+
+	// A rejected batch as the first statement does not start the
+	// transaction, so the transaction can still be used.
+	if _, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.BatchUpdate(ctx, []Statement{NewStatement(deleteAll)})
+		checkRejected("first batch update", err)
+		_, err = tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}); err != nil {
+		t.Fatalf("Unexpected error after rejected batch update: %v", err)
+	}
+}
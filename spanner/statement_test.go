@@ -219,3 +219,74 @@ func TestNewStatement(t *testing.T) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestInStatement(t *testing.T) {
+	for _, test := range []struct {
+		column       string
+		values       interface{}
+		wantFragment string
+		wantName     string
+		wantParam    interface{}
+		wantType     *sppb.Type
+	}{
+		{
+			column:       "SingerId",
+			values:       []int64{1, 2, 3},
+			wantFragment: "SingerId IN UNNEST(@in_SingerId)",
+			wantName:     "in_SingerId",
+			wantParam:    []int64{1, 2, 3},
+			wantType:     listType(intType()),
+		},
+		{
+			column:       "Singers.LastName",
+			values:       []string{"Richards", "Smith"},
+			wantFragment: "Singers.LastName IN UNNEST(@in_Singers_LastName)",
+			wantName:     "in_Singers_LastName",
+			wantParam:    []string{"Richards", "Smith"},
+			wantType:     listType(stringType()),
+		},
+		{
+			column:       "`Key`",
+			values:       [][]byte{[]byte("a"), []byte("b")},
+			wantFragment: "`Key` IN UNNEST(@in_Key)",
+			wantName:     "in_Key",
+			wantParam:    [][]byte{[]byte("a"), []byte("b")},
+			wantType:     listType(bytesType()),
+		},
+		{
+			column:       "SingerId",
+			values:       [2]int64{4, 5},
+			wantFragment: "SingerId IN UNNEST(@in_SingerId)",
+			wantName:     "in_SingerId",
+			wantParam:    []int64{4, 5},
+			wantType:     listType(intType()),
+		},
+	} {
+		fragment, param, name := InStatement(test.column, test.values)
+		if fragment != test.wantFragment {
+			t.Errorf("%v: fragment mismatch\nGot: %v\nWant: %v", test.column, fragment, test.wantFragment)
+		}
+		if name != test.wantName {
+			t.Errorf("%v: name mismatch\nGot: %v\nWant: %v", test.column, name, test.wantName)
+		}
+		if !testEqual(param, test.wantParam) {
+			t.Errorf("%v: param mismatch\nGot: %v\nWant: %v", test.column, param, test.wantParam)
+		}
+		stmt := Statement{SQL: "SELECT * FROM T WHERE " + fragment, Params: map[string]interface{}{name: param}}
+		_, paramTypes, err := stmt.convertParams()
+		if err != nil {
+			t.Fatalf("%v: failed to convert params: %v", test.column, err)
+		}
+		if !proto.Equal(paramTypes[name], test.wantType) {
+			t.Errorf("%v: param type mismatch\nGot: %v\nWant: %v", test.column, paramTypes[name], test.wantType)
+		}
+	}
+
+	// The parameter is a copy of the given values.
+	values := []int64{1, 2}
+	_, param, _ := InStatement("SingerId", values)
+	values[0] = 10
+	if g, w := param, []int64{1, 2}; !testEqual(g, w) {
+		t.Errorf("param changed after modifying values\nGot: %v\nWant: %v", g, w)
+	}
+}
@@ -253,6 +253,75 @@ func TestOTMetrics_SessionPool_GetSessionTimeoutsCount(t *testing.T) {
 	validateOTMetric(ctx1, t, te, expectedMetricData.Name, expectedMetricData)
 }
 
+func TestOTMetrics_SessionPool_WaitTime(t *testing.T) {
+	ctx := context.Background()
+	te := newOpenTelemetryTestExporter(false, false)
+	t.Cleanup(func() {
+		te.Unregister(ctx)
+	})
+	spanner.EnableOpenTelemetryMetrics()
+	_, client, teardown := setupMockedTestServerWithConfig(t, spanner.ClientConfig{
+		OpenTelemetryMeterProvider: te.mp,
+		SessionPoolConfig: spanner.SessionPoolConfig{
+			MinOpened: 1,
+			MaxOpened: 1,
+		},
+	})
+	defer teardown()
+
+	// Check out the only session of the pool, and release it after a while,
+	// so the following read must wait for the session.
+	tx := client.ReadOnlyTransaction()
+	iter := tx.Query(ctx, spanner.NewStatement(stestutil.SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if err := iter.Do(func(r *spanner.Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	const holdTime = 50 * time.Millisecond
+	go func() {
+		time.Sleep(holdTime)
+		tx.Close()
+	}()
+	iter = client.Single().Query(ctx, spanner.NewStatement(stestutil.SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if err := iter.Do(func(r *spanner.Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	rm, err := te.metrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(rm.ScopeMetrics), 1; got != want {
+		t.Fatalf("ScopeMetrics length mismatch, got %v, want %v", got, want)
+	}
+	const metricName = "spanner/session_pool_wait_time"
+	idx := getMetricIndex(rm.ScopeMetrics[0].Metrics, metricName)
+	if idx == -1 {
+		t.Fatalf("Metric Name %s not found", metricName)
+	}
+	m := rm.ScopeMetrics[0].Metrics[idx]
+	if got, want := m.Unit, "ms"; got != want {
+		t.Fatalf("unit mismatch, got %v, want %v", got, want)
+	}
+	hist, ok := m.Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("data type mismatch, got %T, want metricdata.Histogram[float64]", m.Data)
+	}
+	if got, want := len(hist.DataPoints), 1; got != want {
+		t.Fatalf("data points length mismatch, got %v, want %v", got, want)
+	}
+	dp := hist.DataPoints[0]
+	if got, want := dp.Attributes, attribute.NewSet(getAttributes(client.ClientID())...); !got.Equals(&want) {
+		t.Fatalf("attributes mismatch, got %v, want %v", got.ToSlice(), want.ToSlice())
+	}
+	if dp.Count == 0 {
+		t.Fatal("no wait time recorded")
+	}
+	maxWait, ok := dp.Max.Value()
+	if !ok || maxWait < float64(holdTime/2)/float64(time.Millisecond) {
+		t.Fatalf("max wait time mismatch, got %v, want at least %v", maxWait, holdTime/2)
+	}
+}
+
 func TestOTMetrics_GFELatency(t *testing.T) {
 	ctx := context.Background()
 	te := newOpenTelemetryTestExporter(false, false)
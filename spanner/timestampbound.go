@@ -153,6 +153,17 @@ func MaxStaleness(d time.Duration) TimestampBound {
 	}
 }
 
+// MaxStalenessDuration returns a TimestampBound that will perform reads and
+// queries at a timestamp chosen by Cloud Spanner that is at most d before the
+// current time. It is equivalent to MaxStaleness.
+//
+// Bounded staleness can only be used with single-use reads and queries, for
+// example with Client.Single. Beginning a multi-use read-only transaction
+// with this bound returns an InvalidArgument error.
+func MaxStalenessDuration(d time.Duration) TimestampBound {
+	return MaxStaleness(d)
+}
+
 // MinReadTimestamp returns a TimestampBound that bound that will perform reads
 // and queries at a time chosen to be at least "t".
 func MinReadTimestamp(t time.Time) TimestampBound {
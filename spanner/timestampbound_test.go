@@ -52,6 +52,15 @@ func TestMaxStaleness(t *testing.T) {
 	}
 }
 
+// Test generating TimestampBound for reads with max staleness duration.
+func TestMaxStalenessDuration(t *testing.T) {
+	got := MaxStalenessDuration(10 * time.Second)
+	want := MaxStaleness(10 * time.Second)
+	if !testEqual(got, want) {
+		t.Errorf("MaxStalenessDuration(10*time.Second) = %v; want %v", got, want)
+	}
+}
+
 // Test generating TimestampBound for reads with minimum freshness requirement.
 func TestMinReadTimestamp(t *testing.T) {
 	ts := time.Now()
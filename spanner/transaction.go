@@ -18,6 +18,9 @@ package spanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,6 +31,7 @@ import (
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -83,6 +87,10 @@ type txReadOnly struct {
 	// qo provides options for executing a sql query.
 	qo QueryOptions
 
+	// clientSettings are the settings of the client that created the
+	// transaction.
+	*clientSettings
+
 	// ro provides options for reading rows from a database.
 	ro ReadOptions
 
@@ -109,16 +117,65 @@ type TransactionOptions struct {
 	TransactionTag string
 
 	// CommitPriority is the priority to use for the Commit RPC for the
-	// transaction.
+	// transaction. It only applies to the Commit RPC. The priority of the
+	// reads and queries in the transaction is set with ReadOptions.Priority
+	// and QueryOptions.Priority, and can differ from the commit priority.
 	CommitPriority sppb.RequestOptions_Priority
 
-	// the transaction lock mode is used to specify a concurrency mode for the
-	// read/query operations. It works for a read/write transaction only.
+	// ReadLockMode specifies the concurrency mode for the reads and queries
+	// of a read/write transaction. It is ignored for other transactions.
+	//
+	// With PESSIMISTIC locking, which is the default of Spanner, reads acquire
+	// read locks, and a transaction that wants to write data that another
+	// transaction has read must wait for the lock. This avoids aborts after
+	// the work of a transaction has been done, but transactions can block
+	// each other under high contention.
+	//
+	// With OPTIMISTIC locking, reads do not acquire locks. Conflicts are
+	// instead detected when the transaction is committed, and the commit is
+	// aborted if the data that was read has been changed by another
+	// transaction. This gives more throughput for transactions that rarely
+	// conflict, but can cause many retries of transactions that often
+	// conflict.
+	//
+	// READ_LOCK_MODE_UNSPECIFIED uses the default of Spanner. A value that is
+	// set for a specific transaction has precedence over the value in the
+	// ClientConfig.
 	ReadLockMode sppb.TransactionOptions_ReadWrite_ReadLockMode
 
 	// Controls whether to exclude recording modifications in current transaction
 	// from the allowed tracking change streams(with DDL option allow_txn_exclusion=true).
 	ExcludeTxnFromChangeStreams bool
+
+	// DetectConcurrentUse enables detection of concurrent use of a
+	// read/write transaction. When it is set, a call to Update, BatchUpdate or
+	// BufferWrite on a ReadWriteTransaction, or the commit of the
+	// transaction, fails with ErrTransactionConcurrentUse if another of these
+	// calls on the same transaction is still in progress in a different
+	// goroutine. Reads and queries are not checked, as the rows of a
+	// RowIterator may be consumed while other statements are executed.
+	DetectConcurrentUse bool
+
+	// MaxCommitBytes is the maximum estimated size in bytes of the mutations
+	// that are buffered in a read/write transaction. The size is estimated
+	// from the serialized size of the mutations when the transaction is
+	// committed. If the estimate exceeds MaxCommitBytes, the commit fails
+	// with ErrCommitTooLarge without sending a Commit request to Spanner, and
+	// the transaction is rolled back. Zero means that the size is not
+	// checked.
+	MaxCommitBytes int
+
+	// VerifyMutationCount makes a read/write transaction compare the number
+	// of mutations that is estimated by the client with the mutation count in
+	// the commit statistics that are returned by Spanner. The commit
+	// statistics are requested for the commit of the transaction, and
+	// CommitResponse.MutationCountMismatch is set if the counts differ. The
+	// transaction has been committed in that case, and no error is returned.
+	// The estimate is the count that is returned by
+	// ReadWriteTransaction.BufferedMutationCount, so the counts also differ
+	// for transactions that execute DML statements or write to tables with
+	// secondary indexes. VerifyMutationCount is intended for testing.
+	VerifyMutationCount bool
 }
 
 // merge combines two TransactionOptions that the input parameter will have higher
@@ -128,7 +185,14 @@ func (to TransactionOptions) merge(opts TransactionOptions) TransactionOptions {
 		CommitOptions:               to.CommitOptions.merge(opts.CommitOptions),
 		TransactionTag:              to.TransactionTag,
 		CommitPriority:              to.CommitPriority,
+		ReadLockMode:                to.ReadLockMode,
 		ExcludeTxnFromChangeStreams: to.ExcludeTxnFromChangeStreams || opts.ExcludeTxnFromChangeStreams,
+		MaxCommitBytes:              to.MaxCommitBytes,
+		DetectConcurrentUse:         to.DetectConcurrentUse || opts.DetectConcurrentUse,
+		VerifyMutationCount:         to.VerifyMutationCount || opts.VerifyMutationCount,
+	}
+	if opts.MaxCommitBytes != 0 {
+		merged.MaxCommitBytes = opts.MaxCommitBytes
 	}
 	if opts.TransactionTag != "" {
 		merged.TransactionTag = opts.TransactionTag
@@ -142,6 +206,15 @@ func (to TransactionOptions) merge(opts TransactionOptions) TransactionOptions {
 	return merged
 }
 
+// validate returns an error if the options cannot be used for a read/write
+// transaction. It is called before the transaction is started.
+func (to TransactionOptions) validate() error {
+	if _, err := maxCommitDelayProto(to.CommitOptions.MaxCommitDelay); err != nil {
+		return err
+	}
+	return nil
+}
+
 // errSessionClosed returns error for using a recycled/destroyed session
 func errSessionClosed(sh *sessionHandle) error {
 	return spannerErrorf(codes.FailedPrecondition,
@@ -181,19 +254,106 @@ type ReadOptions struct {
 
 	// ReadOptions option used to set the DirectedReadOptions for all ReadRequests which indicate
 	// which replicas or regions should be used for running read operations.
+	// DirectedReadOptions can only be used in read-only transactions. A read
+	// in a read/write transaction with DirectedReadOptions fails with
+	// codes.InvalidArgument.
 	DirectedReadOptions *sppb.DirectedReadOptions
+
+	// AssertMaxStaleness is the maximum age of the data that is returned by a
+	// read in a read-only transaction. The read timestamp that is chosen by
+	// Spanner is compared with the current time of the client, and the read
+	// fails with ErrStaleRead if the difference is larger than
+	// AssertMaxStaleness. This can be used to detect replica lag for
+	// bounded-staleness and exact-staleness reads. Zero means that the
+	// staleness of the read is not checked.
+	//
+	// The AssertMaxStaleness of ClientConfig.ReadOptions also applies to the
+	// queries of the client.
+	AssertMaxStaleness time.Duration
+
+	// RetryWithFreshTimestamp computes the timestamp bound of a single-use
+	// read with a MaxStaleness or ExactStaleness bound on the client at the
+	// start of each attempt of the read. A read that fails with Unavailable
+	// before any data has been returned is retried with a bound that is
+	// computed from the time of the retry, so that the read timestamp is
+	// re-chosen for the retry. The option has no effect on reads with other
+	// bounds and on reads in multi-use transactions.
+	RetryWithFreshTimestamp bool
+
+	// DeadlinePerRow is the time that a read may take for each row that it is
+	// expected to return. If both DeadlinePerRow and ExpectedRowCount are
+	// larger than zero, the read is executed with a deadline of DeadlinePerRow
+	// times ExpectedRowCount, but at least MinDeadline and at most
+	// MaxDeadline if these are larger than zero. The derived deadline does not
+	// extend a deadline that has already been set on the context of the read.
+	DeadlinePerRow time.Duration
+
+	// ExpectedRowCount is the number of rows that the read is expected to
+	// return. It is only used to derive the deadline of the read from
+	// DeadlinePerRow.
+	ExpectedRowCount int64
+
+	// MinDeadline is the minimum deadline that is derived from
+	// DeadlinePerRow.
+	MinDeadline time.Duration
+
+	// MaxDeadline is the maximum deadline that is derived from
+	// DeadlinePerRow.
+	MaxDeadline time.Duration
+}
+
+// rowDeadline returns the deadline of a read that is derived from
+// DeadlinePerRow and ExpectedRowCount, and false if no deadline should be
+// derived.
+func (ro ReadOptions) rowDeadline() (time.Duration, bool) {
+	if ro.DeadlinePerRow <= 0 || ro.ExpectedRowCount <= 0 {
+		return 0, false
+	}
+	d := ro.DeadlinePerRow * time.Duration(ro.ExpectedRowCount)
+	// Guard against overflow for very large row counts.
+	if d/time.Duration(ro.ExpectedRowCount) != ro.DeadlinePerRow {
+		d = time.Duration(math.MaxInt64)
+	}
+	if ro.MinDeadline > 0 && d < ro.MinDeadline {
+		d = ro.MinDeadline
+	}
+	if ro.MaxDeadline > 0 && d > ro.MaxDeadline {
+		d = ro.MaxDeadline
+	}
+	return d, true
 }
 
 // merge combines two ReadOptions that the input parameter will have higher
 // order of precedence.
 func (ro ReadOptions) merge(opts ReadOptions) ReadOptions {
 	merged := ReadOptions{
-		Index:               ro.Index,
-		Limit:               ro.Limit,
-		Priority:            ro.Priority,
-		RequestTag:          ro.RequestTag,
-		DataBoostEnabled:    ro.DataBoostEnabled,
-		DirectedReadOptions: ro.DirectedReadOptions,
+		Index:                   ro.Index,
+		Limit:                   ro.Limit,
+		Priority:                ro.Priority,
+		RequestTag:              ro.RequestTag,
+		DataBoostEnabled:        ro.DataBoostEnabled,
+		DirectedReadOptions:     ro.DirectedReadOptions,
+		AssertMaxStaleness:      ro.AssertMaxStaleness,
+		RetryWithFreshTimestamp: ro.RetryWithFreshTimestamp || opts.RetryWithFreshTimestamp,
+		DeadlinePerRow:          ro.DeadlinePerRow,
+		ExpectedRowCount:        ro.ExpectedRowCount,
+		MinDeadline:             ro.MinDeadline,
+		MaxDeadline:             ro.MaxDeadline,
+	}
+	if opts.DeadlinePerRow > 0 {
+		merged.DeadlinePerRow = opts.DeadlinePerRow
+	}
+	if opts.ExpectedRowCount > 0 {
+		merged.ExpectedRowCount = opts.ExpectedRowCount
+	}
+	if opts.MinDeadline > 0 {
+		merged.MinDeadline = opts.MinDeadline
+	}
+	if opts.MaxDeadline > 0 {
+		merged.MaxDeadline = opts.MaxDeadline
+	}
+	if opts.AssertMaxStaleness > 0 {
+		merged.AssertMaxStaleness = opts.AssertMaxStaleness
 	}
 	if opts.Index != "" {
 		merged.Index = opts.Index
@@ -245,7 +405,11 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 	requestTag := t.ro.RequestTag
 	dataBoostEnabled := t.ro.DataBoostEnabled
 	directedReadOptions := t.ro.DirectedReadOptions
+	maxStaleness := t.ro.AssertMaxStaleness
+	retryWithFreshTimestamp := t.ro.RetryWithFreshTimestamp
+	deadlineOpts := t.ro
 	if opts != nil {
+		deadlineOpts = t.ro.merge(*opts)
 		index = opts.Index
 		if opts.Limit > 0 {
 			limit = opts.Limit
@@ -258,6 +422,35 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 		if opts.DirectedReadOptions != nil {
 			directedReadOptions = opts.DirectedReadOptions
 		}
+		if opts.AssertMaxStaleness > 0 {
+			maxStaleness = opts.AssertMaxStaleness
+		}
+		if opts.RetryWithFreshTimestamp {
+			retryWithFreshTimestamp = true
+		}
+	}
+	if directedReadOptions != nil && t.isReadWrite() {
+		return &RowIterator{err: errDirectedReadInReadWriteTransaction()}
+	}
+	// getTransactionSelector returns the selector for a (re)started stream.
+	// A stream that is resumed with a resume token must use the same read
+	// timestamp, so the bound is only refreshed for a stream that starts
+	// from the beginning.
+	getTransactionSelector := func(resumeToken []byte) *sppb.TransactionSelector {
+		return t.getTransactionSelector()
+	}
+	if ro, ok := t.txReadEnv.(*ReadOnlyTransaction); ok && ro.singleUse && retryWithFreshTimestamp {
+		var fresh *sppb.TransactionSelector
+		getTransactionSelector = func(resumeToken []byte) *sppb.TransactionSelector {
+			if len(resumeToken) == 0 || fresh == nil {
+				fresh = ro.getFreshTransactionSelector()
+			}
+			return fresh
+		}
+	}
+	checkReadTimestamp, err := t.readTimestampCheck(maxStaleness)
+	if err != nil {
+		return &RowIterator{err: err}
 	}
 	var setTransactionID func(transactionID)
 	if _, ok := ts.Selector.(*sppb.TransactionSelector_Begin); ok {
@@ -265,8 +458,13 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 	} else {
 		setTransactionID = nil
 	}
-	return streamWithReplaceSessionFunc(
-		contextWithOutgoingMetadata(ctx, sh.getMetadata(), t.disableRouteToLeader),
+	streamCtx := ctx
+	var cancelDeadline context.CancelFunc
+	if d, ok := deadlineOpts.rowDeadline(); ok {
+		streamCtx, cancelDeadline = context.WithTimeout(ctx, d)
+	}
+	iter := streamWithReplaceSessionFunc(
+		contextWithOutgoingMetadata(streamCtx, sh.getMetadata(), t.disableRouteToLeader),
 		sh.session.logger,
 		func(ctx context.Context, resumeToken []byte) (streamingReceiver, error) {
 			if t.sh != nil {
@@ -275,7 +473,7 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 			client, err := client.StreamingRead(ctx,
 				&sppb.ReadRequest{
 					Session:             t.sh.getID(),
-					Transaction:         t.getTransactionSelector(),
+					Transaction:         getTransactionSelector(resumeToken),
 					Table:               table,
 					Index:               index,
 					Columns:             columns,
@@ -309,6 +507,57 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 		t.setTimestamp,
 		t.release,
 	)
+	iter.checkReadTimestamp = checkReadTimestamp
+	iter.rowd.rowConfig = &rowConfig{
+		numericDecodeMode:  t.numericDecodeMode,
+		ignoreUnmappedCols: t.ignoreUnmappedCols,
+	}
+	iter.streamd.retryClassifier = t.retryClassifier
+	if cancelDeadline != nil {
+		cancel := iter.cancel
+		iter.cancel = func() {
+			cancel()
+			cancelDeadline()
+		}
+	}
+	return iter
+}
+
+// ErrStaleRead is wrapped in the error that is returned when the read
+// timestamp of a read is older than ReadOptions.AssertMaxStaleness. Use
+// errors.Is to check for this error.
+var ErrStaleRead = errors.New("spanner: stale read")
+
+// readTimestampCheck returns the function that checks the read timestamp that
+// is returned by a stream against maxStaleness, or nil if the read timestamp
+// does not need to be checked. The read timestamp of a multi-use read-only
+// transaction that has already been chosen is checked directly, as the stream
+// will not return it.
+func (t *txReadOnly) readTimestampCheck(maxStaleness time.Duration) (func(time.Time) error, error) {
+	if maxStaleness <= 0 {
+		return nil, nil
+	}
+	if ro, ok := t.txReadEnv.(*ReadOnlyTransaction); ok && !ro.singleUse {
+		if rts, err := ro.Timestamp(); err == nil {
+			return nil, assertMaxStaleness(rts, maxStaleness)
+		}
+	}
+	return func(rts time.Time) error {
+		return assertMaxStaleness(rts, maxStaleness)
+	}, nil
+}
+
+// assertMaxStaleness returns an error that wraps ErrStaleRead if the given
+// read timestamp is older than maxStaleness.
+func assertMaxStaleness(rts time.Time, maxStaleness time.Duration) error {
+	if staleness := time.Since(rts); staleness > maxStaleness {
+		return &Error{
+			Code: codes.FailedPrecondition,
+			err:  ErrStaleRead,
+			Desc: fmt.Sprintf("read timestamp %v is %v old, which exceeds AssertMaxStaleness (%v)", rts.UTC().Format(time.RFC3339Nano), staleness, maxStaleness),
+		}
+	}
+	return nil
 }
 
 // errRowNotFound returns error for not being able to read the row identified by
@@ -345,6 +594,9 @@ func (t *txReadOnly) ReadRow(ctx context.Context, table string, key Key, columns
 // If no row is present with the given key, then ReadRowWithOptions returns an error where
 // spanner.ErrCode(err) is codes.NotFound.
 func (t *txReadOnly) ReadRowWithOptions(ctx context.Context, table string, key Key, columns []string, opts *ReadOptions) (*Row, error) {
+	if err := checkKeyArity(t.tableKeyArity, table, key); err != nil {
+		return nil, err
+	}
 	iter := t.ReadWithOptions(ctx, table, key, columns, opts)
 	defer iter.Stop()
 	row, err := iter.Next()
@@ -358,6 +610,26 @@ func (t *txReadOnly) ReadRowWithOptions(ctx context.Context, table string, key K
 	}
 }
 
+// TryReadRow reads a single row from the database. It returns the row and true
+// if a row with the given key exists, and nil and false if no row is present
+// with the given key. The returned error is only non-nil if the read failed.
+func (t *txReadOnly) TryReadRow(ctx context.Context, table string, key Key, columns []string) (*Row, bool, error) {
+	if err := checkKeyArity(t.tableKeyArity, table, key); err != nil {
+		return nil, false, err
+	}
+	iter := t.ReadWithOptions(ctx, table, key, columns, nil)
+	defer iter.Stop()
+	row, err := iter.Next()
+	switch err {
+	case iterator.Done:
+		return nil, false, nil
+	case nil:
+		return row, true, nil
+	default:
+		return nil, false, err
+	}
+}
+
 // ReadRowUsingIndex reads a single row from the database using an index.
 //
 // If no row is present with the given index, then ReadRowUsingIndex returns an
@@ -405,12 +677,35 @@ type QueryOptions struct {
 
 	// QueryOptions option used to set the DirectedReadOptions for all ExecuteSqlRequests which indicate
 	// which replicas or regions should be used for executing queries.
+	// DirectedReadOptions can only be used in read-only transactions. A query
+	// or DML statement in a read/write transaction with DirectedReadOptions
+	// fails with codes.InvalidArgument.
 	DirectedReadOptions *sppb.DirectedReadOptions
 
 	// Controls whether to exclude recording modifications in current partitioned update operation
 	// from the allowed tracking change streams(with DDL option allow_txn_exclusion=true). Setting
 	// this value for any sql/dml requests other than partitioned udpate will receive an error.
 	ExcludeTxnFromChangeStreams bool
+
+	// DuplicateColumnPolicy determines how the rows of the query handle
+	// columns with the same name. The default returns an error when a column
+	// name that occurs more than once is looked up by name.
+	DuplicateColumnPolicy DuplicateColumnPolicy
+
+	// Compression is the name of the gRPC compressor to use for the
+	// ExecuteStreamingSql call of a query, for example "gzip". The compressor
+	// must have been registered with gRPC, e.g. by importing the package
+	// google.golang.org/grpc/encoding/gzip. The default empty name means that
+	// no compression is used.
+	Compression string
+
+	// InactivityTimeout is the maximum time that a query waits for the next
+	// chunk of results from Cloud Spanner. The timer is reset each time a
+	// chunk is received, and the query fails with codes.DeadlineExceeded if
+	// no chunk arrives within this time. The time that the application spends
+	// between calls to RowIterator.Next is not counted. The default zero value
+	// means that there is no inactivity timeout.
+	InactivityTimeout time.Duration
 }
 
 // merge combines two QueryOptions that the input parameter will have higher
@@ -424,6 +719,8 @@ func (qo QueryOptions) merge(opts QueryOptions) QueryOptions {
 		DataBoostEnabled:            qo.DataBoostEnabled,
 		DirectedReadOptions:         qo.DirectedReadOptions,
 		ExcludeTxnFromChangeStreams: qo.ExcludeTxnFromChangeStreams || opts.ExcludeTxnFromChangeStreams,
+		DuplicateColumnPolicy:       qo.DuplicateColumnPolicy,
+		InactivityTimeout:           qo.InactivityTimeout,
 	}
 	if opts.Mode != nil {
 		merged.Mode = opts.Mode
@@ -440,6 +737,15 @@ func (qo QueryOptions) merge(opts QueryOptions) QueryOptions {
 	if opts.DirectedReadOptions != nil {
 		merged.DirectedReadOptions = opts.DirectedReadOptions
 	}
+	if opts.DuplicateColumnPolicy != DuplicateColumnError {
+		merged.DuplicateColumnPolicy = opts.DuplicateColumnPolicy
+	}
+	if opts.Compression != "" {
+		merged.Compression = opts.Compression
+	}
+	if opts.InactivityTimeout != 0 {
+		merged.InactivityTimeout = opts.InactivityTimeout
+	}
 	proto.Merge(merged.Options, qo.Options)
 	proto.Merge(merged.Options, opts.Options)
 	return merged
@@ -468,10 +774,13 @@ func createRequestOptions(prio sppb.RequestOptions_Priority, requestTag, transac
 func (t *txReadOnly) Query(ctx context.Context, statement Statement) *RowIterator {
 	mode := sppb.ExecuteSqlRequest_NORMAL
 	return t.query(ctx, statement, QueryOptions{
-		Mode:                &mode,
-		Options:             t.qo.Options,
-		Priority:            t.qo.Priority,
-		DirectedReadOptions: t.qo.DirectedReadOptions,
+		Mode:                  &mode,
+		Options:               t.qo.Options,
+		Priority:              t.qo.Priority,
+		DirectedReadOptions:   t.qo.DirectedReadOptions,
+		DuplicateColumnPolicy: t.qo.DuplicateColumnPolicy,
+		Compression:           t.qo.Compression,
+		InactivityTimeout:     t.qo.InactivityTimeout,
 	})
 }
 
@@ -488,10 +797,13 @@ func (t *txReadOnly) QueryWithOptions(ctx context.Context, statement Statement,
 func (t *txReadOnly) QueryWithStats(ctx context.Context, statement Statement) *RowIterator {
 	mode := sppb.ExecuteSqlRequest_PROFILE
 	return t.query(ctx, statement, QueryOptions{
-		Mode:                &mode,
-		Options:             t.qo.Options,
-		Priority:            t.qo.Priority,
-		DirectedReadOptions: t.qo.DirectedReadOptions,
+		Mode:                  &mode,
+		Options:               t.qo.Options,
+		Priority:              t.qo.Priority,
+		DirectedReadOptions:   t.qo.DirectedReadOptions,
+		DuplicateColumnPolicy: t.qo.DuplicateColumnPolicy,
+		Compression:           t.qo.Compression,
+		InactivityTimeout:     t.qo.InactivityTimeout,
 	})
 }
 
@@ -520,13 +832,59 @@ func (t *txReadOnly) AnalyzeQuery(ctx context.Context, statement Statement) (*sp
 	return iter.QueryPlan, nil
 }
 
+// ProfileQuery executes statement with query mode PROFILE and calls f for each
+// row of the result. It returns the statistics of the query, including the
+// query plan and the execution statistics, after all rows have been returned.
+//
+// ProfileQuery stops and returns the error if f returns an error.
+func (t *txReadOnly) ProfileQuery(ctx context.Context, statement Statement, f func(r *Row) error) (*sppb.ResultSetStats, error) {
+	mode := sppb.ExecuteSqlRequest_PROFILE
+	iter := t.query(ctx, statement, QueryOptions{
+		Mode:                  &mode,
+		Options:               t.qo.Options,
+		Priority:              t.qo.Priority,
+		DirectedReadOptions:   t.qo.DirectedReadOptions,
+		DuplicateColumnPolicy: t.qo.DuplicateColumnPolicy,
+		Compression:           t.qo.Compression,
+		InactivityTimeout:     t.qo.InactivityTimeout,
+	})
+	if err := iter.Do(f); err != nil {
+		return nil, err
+	}
+	if iter.stats == nil {
+		return nil, spannerErrorf(codes.Internal, "query statistics unavailable")
+	}
+	return iter.stats, nil
+}
+
 func (t *txReadOnly) query(ctx context.Context, statement Statement, options QueryOptions) (ri *RowIterator) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Query")
 	defer func() { trace.EndSpan(ctx, ri.err) }()
+	var statsSink func(*sppb.ResultSetStats)
+	if t.queryStatsSink != nil && (options.Mode == nil || *options.Mode != sppb.ExecuteSqlRequest_PLAN) {
+		if options.Mode == nil || *options.Mode == sppb.ExecuteSqlRequest_NORMAL {
+			mode := sppb.ExecuteSqlRequest_PROFILE
+			options.Mode = &mode
+		}
+		statsSink = func(stats *sppb.ResultSetStats) {
+			t.queryStatsSink(stats, statement)
+		}
+	}
+	var callOptions []gax.CallOption
+	if options.Compression != "" {
+		if encoding.GetCompressor(options.Compression) == nil {
+			return &RowIterator{err: errUnknownCompressor(options.Compression)}
+		}
+		callOptions = append(callOptions, gax.WithGRPCOptions(grpc.UseCompressor(options.Compression)))
+	}
 	req, sh, err := t.prepareExecuteSQL(ctx, statement, options)
 	if err != nil {
 		return &RowIterator{err: err}
 	}
+	checkReadTimestamp, err := t.readTimestampCheck(t.ro.AssertMaxStaleness)
+	if err != nil {
+		return &RowIterator{err: err}
+	}
 	var setTransactionID func(transactionID)
 	if _, ok := req.Transaction.GetSelector().(*sppb.TransactionSelector_Begin); ok {
 		setTransactionID = t.setTransactionID
@@ -534,7 +892,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 		setTransactionID = nil
 	}
 	client := sh.getClient()
-	return streamWithReplaceSessionFunc(
+	iter := streamWithReplaceSessionFunc(
 		contextWithOutgoingMetadata(ctx, sh.getMetadata(), t.disableRouteToLeader),
 		sh.session.logger,
 		func(ctx context.Context, resumeToken []byte) (streamingReceiver, error) {
@@ -543,7 +901,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 			req.Transaction = t.getTransactionSelector()
 			t.sh.updateLastUseTime()
 
-			client, err := client.ExecuteStreamingSql(ctx, req)
+			client, err := client.ExecuteStreamingSql(ctx, req, callOptions...)
 			if err != nil {
 				if _, ok := req.Transaction.GetSelector().(*sppb.TransactionSelector_Begin); ok {
 					t.setTransactionID(nil)
@@ -566,9 +924,25 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 		setTransactionID,
 		t.setTimestamp,
 		t.release)
+	iter.statsSink = statsSink
+	iter.checkReadTimestamp = checkReadTimestamp
+	iter.rowd.rowConfig = &rowConfig{
+		numericDecodeMode:     t.numericDecodeMode,
+		ignoreUnmappedCols:    t.ignoreUnmappedCols,
+		duplicateColumnPolicy: options.DuplicateColumnPolicy,
+	}
+	iter.streamd.retryClassifier = t.retryClassifier
+	iter.streamd.inactivityTimeout = options.InactivityTimeout
+	return iter
 }
 
 func (t *txReadOnly) prepareExecuteSQL(ctx context.Context, stmt Statement, options QueryOptions) (*sppb.ExecuteSqlRequest, *sessionHandle, error) {
+	if options.DirectedReadOptions != nil && t.isReadWrite() {
+		return nil, nil, errDirectedReadInReadWriteTransaction()
+	}
+	if err := t.statementFilter.check(stmt.SQL); err != nil {
+		return nil, nil, err
+	}
 	sh, ts, err := t.acquire(ctx)
 	if err != nil {
 		return nil, nil, err
@@ -579,6 +953,11 @@ func (t *txReadOnly) prepareExecuteSQL(ctx context.Context, stmt Statement, opti
 		// Might happen if transaction is closed in the middle of a API call.
 		return nil, nil, errSessionClosed(sh)
 	}
+	if t.rejectZeroTime {
+		if err := stmt.checkZeroTime(); err != nil {
+			return nil, nil, err
+		}
+	}
 	params, paramTypes, err := stmt.convertParams()
 	if err != nil {
 		return nil, nil, err
@@ -628,6 +1007,33 @@ func errTxClosed() error {
 	return spannerErrorf(codes.InvalidArgument, "cannot use a closed transaction")
 }
 
+// errBoundedStalenessMultiUse returns error for beginning a multi-use
+// read-only transaction with a bounded staleness timestamp bound.
+func errBoundedStalenessMultiUse(tb TimestampBound) error {
+	return spannerErrorf(codes.InvalidArgument, "timestamp bound %v can only be used with single-use reads and queries", tb)
+}
+
+// errUnknownCompressor returns error for a QueryOptions.Compression that is
+// not the name of a registered gRPC compressor.
+func errUnknownCompressor(name string) error {
+	return spannerErrorf(codes.InvalidArgument, "compressor %q is not registered with gRPC", name)
+}
+
+// errDirectedReadInReadWriteTransaction returns error for using
+// DirectedReadOptions in a read/write transaction.
+func errDirectedReadInReadWriteTransaction() error {
+	return spannerErrorf(codes.InvalidArgument, "DirectedReadOptions can only be used in read-only transactions")
+}
+
+// isReadWrite returns true if t is a part of a read/write transaction.
+func (t *txReadOnly) isReadWrite() bool {
+	switch t.txReadEnv.(type) {
+	case *ReadWriteTransaction, *ReadWriteStmtBasedTransaction:
+		return true
+	}
+	return false
+}
+
 // errUnexpectedTxState returns error for transaction enters an unexpected state.
 func errUnexpectedTxState(ts txState) error {
 	return spannerErrorf(codes.FailedPrecondition, "unexpected transaction state: %v", ts)
@@ -726,9 +1132,16 @@ func (t *ReadOnlyTransaction) begin(ctx context.Context) error {
 			sh.recycle()
 		}
 	}()
+	// Cloud Spanner only supports bounded staleness for single-use
+	// transactions, as the read timestamp is negotiated for the rows that are
+	// read.
+	if tb := t.getTimestampBound(); tb.mode == maxStaleness || tb.mode == minReadTimestamp {
+		err = errBoundedStalenessMultiUse(tb)
+		return err
+	}
 	// Retry the BeginTransaction call if a 'Session not found' is returned.
 	for {
-		sh, err = t.sp.take(ctx)
+		sh, err = t.sp.takeFor(ctx, "ReadOnlyTransaction")
 		if err != nil {
 			return err
 		}
@@ -818,7 +1231,7 @@ func (t *ReadOnlyTransaction) acquireSingleUse(ctx context.Context) (*sessionHan
 				},
 			},
 		}
-		sh, err := t.sp.take(ctx)
+		sh, err := t.sp.takeFor(ctx, "SingleUse")
 		if err != nil {
 			return nil, nil, err
 		}
@@ -916,6 +1329,32 @@ func (t *ReadOnlyTransaction) getTransactionSelector() *sppb.TransactionSelector
 	}
 }
 
+// getFreshTransactionSelector returns the transaction selector of a
+// single-use transaction where a MaxStaleness or ExactStaleness bound is
+// converted to an absolute bound that is computed from the current time.
+func (t *ReadOnlyTransaction) getFreshTransactionSelector() *sppb.TransactionSelector {
+	t.mu.Lock()
+	tb := t.tb
+	t.mu.Unlock()
+	switch tb.mode {
+	case maxStaleness:
+		tb = MinReadTimestamp(time.Now().Add(-tb.d))
+	case exactStaleness:
+		tb = ReadTimestamp(time.Now().Add(-tb.d))
+	default:
+		return t.getTransactionSelector()
+	}
+	return &sppb.TransactionSelector{
+		Selector: &sppb.TransactionSelector_SingleUse{
+			SingleUse: &sppb.TransactionOptions{
+				Mode: &sppb.TransactionOptions_ReadOnly_{
+					ReadOnly: buildTransactionOptionsReadOnly(tb, true),
+				},
+			},
+		},
+	}
+}
+
 func (t *ReadOnlyTransaction) setTimestamp(ts time.Time) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -980,7 +1419,9 @@ func (t *ReadOnlyTransaction) Timestamp() (time.Time, error) {
 // WithTimestampBound specifies the TimestampBound to use for read or query.
 // This can only be used before the first read or query is invoked. Note:
 // bounded staleness is not available with general ReadOnlyTransactions; use a
-// single-use ReadOnlyTransaction instead.
+// single-use ReadOnlyTransaction instead. Beginning a general
+// ReadOnlyTransaction with a bounded staleness returns an InvalidArgument
+// error.
 //
 // The returned value is the ReadOnlyTransaction so calls can be chained.
 func (t *ReadOnlyTransaction) WithTimestampBound(tb TimestampBound) *ReadOnlyTransaction {
@@ -1086,6 +1527,47 @@ type ReadWriteTransaction struct {
 	wb []*Mutation
 	// isLongRunningTransaction indicates whether the transaction is long-running or not.
 	isLongRunningTransaction bool
+	// inFlight is 1 while a DML statement, BufferWrite or the commit is
+	// being executed on the transaction. It is only used if
+	// TransactionOptions.DetectConcurrentUse is set.
+	// Atomic.
+	inFlight int32
+	// authorizeMutations checks the mutations that are buffered with
+	// BufferWrite. It is nil if the client has no MutationAuthorizer.
+	authorizeMutations func([]*Mutation) error
+	// authorizeErr is the first error that was returned by
+	// authorizeMutations. A transaction with an authorizeErr cannot be
+	// committed. Guarded by mu.
+	authorizeErr error
+}
+
+// ErrTransactionConcurrentUse is wrapped in the error that is returned when a
+// read/write transaction with TransactionOptions.DetectConcurrentUse is used
+// by multiple goroutines at the same time. Use errors.Is to check for this
+// error.
+var ErrTransactionConcurrentUse = errors.New("spanner: concurrent use of transaction")
+
+// errTransactionConcurrentUse returns error for calling a method on a
+// read/write transaction while another call is in progress.
+func errTransactionConcurrentUse() error {
+	return &Error{
+		Code: codes.FailedPrecondition,
+		err:  ErrTransactionConcurrentUse,
+		Desc: "the transaction is already being used by another goroutine",
+	}
+}
+
+// enter marks the start of a call on the transaction and returns a function
+// that must be called when the call has finished. It returns an error if
+// concurrent use detection is enabled and another call is in progress.
+func (t *ReadWriteTransaction) enter() (func(), error) {
+	if !t.txOpts.DetectConcurrentUse {
+		return func() {}, nil
+	}
+	if !atomic.CompareAndSwapInt32(&t.inFlight, 0, 1) {
+		return nil, errTransactionConcurrentUse()
+	}
+	return func() { atomic.StoreInt32(&t.inFlight, 0) }, nil
 }
 
 // BufferWrite adds a list of mutations to the set of updates that will be
@@ -1096,6 +1578,32 @@ type ReadWriteTransaction struct {
 //
 // See the example for Client.ReadWriteTransaction.
 func (t *ReadWriteTransaction) BufferWrite(ms []*Mutation) error {
+	exit, err := t.enter()
+	if err != nil {
+		return err
+	}
+	defer exit()
+	if t.emptyStringAsNull {
+		ms = emptyStringsAsNull(ms)
+	}
+	if t.rejectZeroTime {
+		if err := checkZeroTime(ms); err != nil {
+			return err
+		}
+	}
+	if err := checkMutationKeyArity(t.tableKeyArity, ms); err != nil {
+		return err
+	}
+	if t.authorizeMutations != nil {
+		if err := t.authorizeMutations(ms); err != nil {
+			t.mu.Lock()
+			if t.authorizeErr == nil {
+				t.authorizeErr = err
+			}
+			t.mu.Unlock()
+			return err
+		}
+	}
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	if t.state == txClosed {
@@ -1105,6 +1613,34 @@ func (t *ReadWriteTransaction) BufferWrite(ms []*Mutation) error {
 	return nil
 }
 
+// BufferedMutationCount returns an estimate of how many mutations the writes
+// that have been buffered with BufferWrite count towards the mutation limit
+// of a commit. An insert, update, replace or insert-or-update
+// counts as one mutation per column, and a delete counts as one mutation.
+// The mutations of secondary indexes, and of DML statements, are not
+// included. The count is computed by the client, and starts at zero for each
+// attempt of the transaction function.
+func (t *ReadWriteTransaction) BufferedMutationCount() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return mutationsCount(t.wb)
+}
+
+// SetTag sets the transaction tag of the transaction. The tag is included in
+// the RequestOptions of the statements and reads that are executed after
+// SetTag has been called, and of the commit of the transaction. It replaces
+// the tag that was set with TransactionOptions.TransactionTag. The request tag
+// of each statement can still be set with QueryOptions.RequestTag and
+// ReadOptions.RequestTag.
+//
+// SetTag must be called again in each attempt of the transaction function, and
+// must not be called concurrently with other methods of the transaction.
+func (t *ReadWriteTransaction) SetTag(tag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.txOpts.TransactionTag = tag
+}
+
 // Update executes a DML statement against the database. It returns the number
 // of affected rows. Update returns an error if the statement is a query.
 // However, the query is executed, and any data read will be validated upon
@@ -1120,7 +1656,9 @@ func (t *ReadWriteTransaction) Update(ctx context.Context, stmt Statement) (rowC
 
 // UpdateWithOptions executes a DML statement against the database. It returns
 // the number of affected rows. The given QueryOptions will be used for the
-// execution of this statement.
+// execution of this statement. The Priority and RequestTag of the options are
+// sent with the statement, so statements in the same transaction can have
+// different priorities.
 func (t *ReadWriteTransaction) UpdateWithOptions(ctx context.Context, stmt Statement, opts QueryOptions) (rowCount int64, err error) {
 	if opts.ExcludeTxnFromChangeStreams {
 		return 0, errExcludeRequestLevelDmlFromChangeStreams()
@@ -1132,6 +1670,14 @@ func (t *ReadWriteTransaction) UpdateWithOptions(ctx context.Context, stmt State
 func (t *ReadWriteTransaction) update(ctx context.Context, stmt Statement, opts QueryOptions) (rowCount int64, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Update")
 	defer func() { trace.EndSpan(ctx, err) }()
+	exit, err := t.enter()
+	if err != nil {
+		return 0, err
+	}
+	defer exit()
+	if t.emptyStringAsNull {
+		stmt = stmt.emptyStringsAsNull()
+	}
 	req, sh, err := t.prepareExecuteSQL(ctx, stmt, opts)
 	if err != nil {
 		return 0, err
@@ -1207,6 +1753,37 @@ func (t *ReadWriteTransaction) BatchUpdateWithOptions(ctx context.Context, stmts
 func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts []Statement, opts QueryOptions) (_ []int64, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.BatchUpdate")
 	defer func() { trace.EndSpan(ctx, err) }()
+	exit, err := t.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer exit()
+
+	// Check all statements before the transaction is acquired, so that an
+	// inline begin transaction is not left without a transaction ID.
+	var sppbStmts []*sppb.ExecuteBatchDmlRequest_Statement
+	for _, st := range stmts {
+		if err := t.statementFilter.check(st.SQL); err != nil {
+			return nil, err
+		}
+		if t.emptyStringAsNull {
+			st = st.emptyStringsAsNull()
+		}
+		if t.rejectZeroTime {
+			if err := st.checkZeroTime(); err != nil {
+				return nil, err
+			}
+		}
+		params, paramTypes, err := st.convertParams()
+		if err != nil {
+			return nil, err
+		}
+		sppbStmts = append(sppbStmts, &sppb.ExecuteBatchDmlRequest_Statement{
+			Sql:        st.SQL,
+			Params:     params,
+			ParamTypes: paramTypes,
+		})
+	}
 
 	sh, ts, err := t.acquire(ctx)
 	if err != nil {
@@ -1226,19 +1803,6 @@ func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts
 	t.mu.Unlock()
 	t.setSessionEligibilityForLongRunning(sh)
 
-	var sppbStmts []*sppb.ExecuteBatchDmlRequest_Statement
-	for _, st := range stmts {
-		params, paramTypes, err := st.convertParams()
-		if err != nil {
-			return nil, err
-		}
-		sppbStmts = append(sppbStmts, &sppb.ExecuteBatchDmlRequest_Statement{
-			Sql:        st.SQL,
-			Params:     params,
-			ParamTypes: paramTypes,
-		})
-	}
-
 	hasInlineBeginTransaction := false
 	if _, ok := ts.GetSelector().(*sppb.TransactionSelector_Begin); ok {
 		hasInlineBeginTransaction = true
@@ -1319,7 +1883,9 @@ func (t *ReadWriteTransaction) acquire(ctx context.Context) (*sessionHandle, *sp
 				Selector: &sppb.TransactionSelector_Begin{
 					Begin: &sppb.TransactionOptions{
 						Mode: &sppb.TransactionOptions_ReadWrite_{
-							ReadWrite: &sppb.TransactionOptions_ReadWrite{},
+							ReadWrite: &sppb.TransactionOptions_ReadWrite{
+								ReadLockMode: t.txOpts.ReadLockMode,
+							},
 						},
 						ExcludeTxnFromChangeStreams: t.txOpts.ExcludeTxnFromChangeStreams,
 					},
@@ -1498,7 +2064,7 @@ func (t *ReadWriteTransaction) begin(ctx context.Context) error {
 		tx, err = beginTransaction(contextWithOutgoingMetadata(ctx, sh.getMetadata(), t.disableRouteToLeader), sh.getID(), sh.getClient(), t.txOpts)
 		if isSessionNotFoundError(err) {
 			sh.destroy()
-			sh, err = t.sp.take(ctx)
+			sh, err = t.sp.takeFor(ctx, sh.op)
 			if err != nil {
 				return err
 			}
@@ -1521,18 +2087,74 @@ func (t *ReadWriteTransaction) begin(ctx context.Context) error {
 	return err
 }
 
+// ErrCommitTooLarge is wrapped in the error that is returned when the
+// estimated size of the mutations of a read/write transaction exceeds
+// TransactionOptions.MaxCommitBytes. Use errors.Is to check for this error.
+var ErrCommitTooLarge = errors.New("spanner: commit too large")
+
+// errCommitTooLarge returns error for committing mutations with an estimated
+// size that exceeds the configured maximum.
+func errCommitTooLarge(size, maxBytes int) error {
+	msg := fmt.Sprintf("estimated size of the mutations (%d bytes) exceeds MaxCommitBytes (%d bytes)", size, maxBytes)
+	return &Error{
+		Code: codes.InvalidArgument,
+		err:  ErrCommitTooLarge,
+		Desc: msg,
+	}
+}
+
+// commitSize returns the estimated size in bytes of the given mutations when
+// they are sent to Spanner.
+func commitSize(mPb []*sppb.Mutation) int {
+	size := 0
+	for _, m := range mPb {
+		size += proto.Size(m)
+	}
+	return size
+}
+
 // CommitResponse provides a response of a transaction commit in a database.
 type CommitResponse struct {
 	// CommitTs is the commit time for a transaction.
 	CommitTs time.Time
 	// CommitStats is the commit statistics for a transaction.
 	CommitStats *sppb.CommitResponse_CommitStats
+	// MutationCountMismatch is set for a transaction with
+	// TransactionOptions.VerifyMutationCount if the mutation count that is
+	// returned by Spanner differs from the estimate of the client.
+	MutationCountMismatch bool
 }
 
 // CommitOptions provides options for committing a transaction in a database.
 type CommitOptions struct {
 	ReturnCommitStats bool
-	MaxCommitDelay    *time.Duration
+	// MaxCommitDelay is the amount of latency that the commit may be delayed
+	// by Cloud Spanner to batch it with other commits to improve throughput.
+	// It must be between 0 and 500ms. The default is nil, which lets Cloud
+	// Spanner choose the delay.
+	MaxCommitDelay *time.Duration
+}
+
+// maxCommitDelayLimit is the largest MaxCommitDelay that is accepted by Cloud
+// Spanner.
+const maxCommitDelayLimit = 500 * time.Millisecond
+
+// errInvalidMaxCommitDelay returns error for a MaxCommitDelay that is outside
+// the range that is accepted by Cloud Spanner.
+func errInvalidMaxCommitDelay(d time.Duration) error {
+	return spannerErrorf(codes.InvalidArgument, "MaxCommitDelay must be between 0 and %v, got %v", maxCommitDelayLimit, d)
+}
+
+// maxCommitDelayProto validates the given max commit delay and converts it to
+// a proto Duration. It returns nil if d is nil.
+func maxCommitDelayProto(d *time.Duration) (*durationpb.Duration, error) {
+	if d == nil {
+		return nil, nil
+	}
+	if *d < 0 || *d > maxCommitDelayLimit {
+		return nil, errInvalidMaxCommitDelay(*d)
+	}
+	return durationpb.New(*d), nil
 }
 
 // merge combines two CommitOptions that the input parameter will have higher
@@ -1550,10 +2172,30 @@ func (co CommitOptions) merge(opts CommitOptions) CommitOptions {
 	return newOpts
 }
 
+// authorizationError returns the first error that the MutationAuthorizer
+// returned for a BufferWrite call on the transaction, or nil.
+func (t *ReadWriteTransaction) authorizationError() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.authorizeErr
+}
+
 // commit tries to commit a readwrite transaction to Cloud Spanner. It also
 // returns the commit response for the transactions.
 func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions) (CommitResponse, error) {
 	resp := CommitResponse{}
+	exit, err := t.enter()
+	if err != nil {
+		return resp, err
+	}
+	defer exit()
+	if err := t.authorizationError(); err != nil {
+		return resp, err
+	}
+	maxCommitDelay, err := maxCommitDelayProto(options.MaxCommitDelay)
+	if err != nil {
+		return resp, err
+	}
 	t.mu.Lock()
 	if t.tx == nil {
 		if t.state == txClosed {
@@ -1571,11 +2213,17 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 	t.state = txClosed // No further operations after commit.
 	close(t.txReadyOrClosed)
 	mPb, err := mutationsProto(t.wb)
+	estimatedMutationCount := mutationsCount(t.wb)
 
 	t.mu.Unlock()
 	if err != nil {
 		return resp, err
 	}
+	if maxBytes := t.txOpts.MaxCommitBytes; maxBytes > 0 {
+		if size := commitSize(mPb); size > maxBytes {
+			return resp, errCommitTooLarge(size, maxBytes)
+		}
+	}
 
 	// In case that sessionHandle was destroyed but transaction body fails to
 	// report it.
@@ -1586,10 +2234,6 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 	t.sh.updateLastUseTime()
 
 	var md metadata.MD
-	var maxCommitDelay *durationpb.Duration
-	if options.MaxCommitDelay != nil {
-		maxCommitDelay = durationpb.New(*(options.MaxCommitDelay))
-	}
 	res, e := client.Commit(contextWithOutgoingMetadata(ctx, t.sh.getMetadata(), t.disableRouteToLeader), &sppb.CommitRequest{
 		Session: sid,
 		Transaction: &sppb.CommitRequest_TransactionId{
@@ -1597,7 +2241,7 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 		},
 		RequestOptions:    createRequestOptions(t.txOpts.CommitPriority, "", t.txOpts.TransactionTag),
 		Mutations:         mPb,
-		ReturnCommitStats: options.ReturnCommitStats,
+		ReturnCommitStats: options.ReturnCommitStats || t.txOpts.VerifyMutationCount,
 		MaxCommitDelay:    maxCommitDelay,
 	}, gax.WithGRPCOptions(grpc.Header(&md)))
 	if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
@@ -1617,6 +2261,9 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 	if options.ReturnCommitStats {
 		resp.CommitStats = res.CommitStats
 	}
+	if t.txOpts.VerifyMutationCount {
+		resp.MutationCountMismatch = res.GetCommitStats().GetMutationCount() != estimatedMutationCount
+	}
 	if isSessionNotFoundError(err) {
 		t.sh.destroy()
 	}
@@ -1658,9 +2305,16 @@ func (t *ReadWriteTransaction) runInTransaction(ctx context.Context, f func(cont
 		errDuringCommit bool
 	)
 	if err = f(context.WithValue(ctx, transactionInProgressKey{}, 1), t); err == nil {
+		// A mutation that was rejected by the MutationAuthorizer fails the
+		// transaction, also if f ignored the error that BufferWrite returned.
+		err = t.authorizationError()
+	}
+	if err == nil {
 		// Try to commit if transaction body returns no error.
 		resp, err = t.commit(ctx, t.txOpts.CommitOptions)
-		errDuringCommit = err != nil
+		// The transaction must still be rolled back if the commit was
+		// rejected before it was sent to Spanner.
+		errDuringCommit = err != nil && !errors.Is(err, ErrCommitTooLarge)
 	}
 	if err != nil {
 		if isAbortedErr(err) {
@@ -1734,7 +2388,10 @@ func NewReadWriteStmtBasedTransactionWithOptions(ctx context.Context, c *Client,
 		err error
 		t   *ReadWriteStmtBasedTransaction
 	)
-	sh, err = c.idleSessions.take(ctx)
+	if err := c.txo.merge(options).validate(); err != nil {
+		return nil, err
+	}
+	sh, err = c.idleSessions.takeFor(ctx, "ReadWriteStmtBasedTransaction")
 	if err != nil {
 		// If session retrieval fails, just fail the transaction.
 		return nil, err
@@ -1747,8 +2404,10 @@ func NewReadWriteStmtBasedTransactionWithOptions(ctx context.Context, c *Client,
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.clientSettings = c.clientSettings
 	t.txReadOnly.ro = c.ro
 	t.txReadOnly.disableRouteToLeader = c.disableRouteToLeader
+	t.authorizeMutations = c.mutationAuthorizerFunc(ctx)
 	t.txOpts = c.txo.merge(options)
 	t.ct = c.ct
 	t.otConfig = c.otConfig
@@ -1774,7 +2433,7 @@ func (t *ReadWriteStmtBasedTransaction) Commit(ctx context.Context) (time.Time,
 // the commit timestamp and stats for the transactions.
 func (t *ReadWriteStmtBasedTransaction) CommitWithReturnResp(ctx context.Context) (CommitResponse, error) {
 	resp, err := t.commit(ctx, t.txOpts.CommitOptions)
-	// Rolling back an aborted transaction is not necessary.
+	// Rolling back an aborted or committed transaction is not necessary.
 	if err != nil && status.Code(err) != codes.Aborted {
 		t.rollback(ctx)
 	}
@@ -1793,6 +2452,58 @@ func (t *ReadWriteStmtBasedTransaction) Rollback(ctx context.Context) {
 	}
 }
 
+// ErrAbortedRetryable matches any Spanner error with code Aborted when it is
+// used with errors.Is. Cloud Spanner may abort a read/write transaction at any
+// moment, and a transaction that has been aborted can be retried by running
+// all of its statements again in a new transaction.
+var ErrAbortedRetryable = errors.New("spanner: transaction aborted")
+
+// RunStmtBased runs f in a new statement-based read/write transaction and
+// commits the transaction if f returns nil. The transaction is rolled back if
+// f returns an error.
+//
+// If f or the commit returns an error that matches ErrAbortedRetryable, the
+// transaction is retried by calling f again with a new transaction. The delay
+// between the attempts is the delay returned by Cloud Spanner, or if none is
+// returned, the delay calculated with exponential backoff. The delay is capped
+// at ClientConfig.MaxAbortedRetryDelay. Any other error is returned without
+// retrying the transaction. RunStmtBased stops retrying and returns the error
+// of ctx when ctx is done. As f may be called multiple times, it should not
+// have side effects other than the statements that it executes on the given
+// transaction.
+func RunStmtBased(ctx context.Context, c *Client, f func(*ReadWriteStmtBasedTransaction) error) (CommitResponse, error) {
+	retryer := newRetryer(c.retryClassifier, RetryOperationTransaction, DefaultRetryBackoff)
+	for {
+		if err := ctx.Err(); err != nil {
+			return CommitResponse{}, ToSpannerError(err)
+		}
+		t, err := NewReadWriteStmtBasedTransaction(ctx, c)
+		if err != nil {
+			return CommitResponse{}, err
+		}
+		var resp CommitResponse
+		if err = f(t); err == nil {
+			resp, err = t.CommitWithReturnResp(ctx)
+		} else {
+			t.Rollback(ctx)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, ErrAbortedRetryable) {
+			return resp, err
+		}
+		delay, _ := retryer.Retry(err)
+		if c.maxAbortedRetryDelay > 0 && delay > c.maxAbortedRetryDelay {
+			delay = c.maxAbortedRetryDelay
+		}
+		trace.TracePrintf(ctx, nil, "Retrying statement-based transaction after Aborted in %s", delay)
+		if err := gax.Sleep(ctx, delay); err != nil {
+			return resp, ToSpannerError(err)
+		}
+	}
+}
+
 // writeOnlyTransaction provides the most efficient way of doing write-only
 // transactions. It essentially does blind writes to Cloud Spanner.
 type writeOnlyTransaction struct {
@@ -1810,6 +2521,14 @@ type writeOnlyTransaction struct {
 	// current transaction from the allowed tracking change streams with DDL option
 	// allow_txn_exclusion=true.
 	excludeTxnFromChangeStreams bool
+	// returnCommitStats makes the transaction request the commit statistics
+	// of the commit.
+	returnCommitStats bool
+	// maxCommitDelay is the max commit delay to use for the commit.
+	maxCommitDelay *time.Duration
+	// clientSettings are the settings of the client that created the
+	// transaction.
+	*clientSettings
 }
 
 // applyAtLeastOnce commits a list of mutations to Cloud Spanner at least once,
@@ -1818,30 +2537,45 @@ type writeOnlyTransaction struct {
 //  1. Context times out.
 //  2. An unretryable error (e.g. database not found) occurs.
 //  3. There is a malformed Mutation object.
-func (t *writeOnlyTransaction) applyAtLeastOnce(ctx context.Context, ms ...*Mutation) (time.Time, error) {
+func (t *writeOnlyTransaction) applyAtLeastOnce(ctx context.Context, ms ...*Mutation) (CommitResponse, error) {
 	var (
-		ts time.Time
-		sh *sessionHandle
+		resp CommitResponse
+		sh   *sessionHandle
 	)
 	defer func() {
 		if sh != nil {
 			sh.recycle()
 		}
 	}()
+	if t.emptyStringAsNull {
+		ms = emptyStringsAsNull(ms)
+	}
 	mPb, err := mutationsProto(ms)
 	if err != nil {
 		// Malformed mutation found, just return the error.
-		return ts, err
+		return resp, err
+	}
+	if t.rejectZeroTime {
+		if err := checkZeroTime(ms); err != nil {
+			return resp, err
+		}
+	}
+	if err := checkMutationKeyArity(t.tableKeyArity, ms); err != nil {
+		return resp, err
+	}
+	maxCommitDelay, err := maxCommitDelayProto(t.maxCommitDelay)
+	if err != nil {
+		return resp, err
 	}
 
 	// Make a retryer for Aborted and certain Internal errors.
-	retryer := onCodes(DefaultRetryBackoff, codes.Aborted, codes.Internal)
+	retryer := newRetryer(t.retryClassifier, RetryOperationTransaction, DefaultRetryBackoff)
 	// Apply the mutation and retry if the commit is aborted.
 	applyMutationWithRetry := func(ctx context.Context) error {
 		for {
 			if sh == nil || sh.getID() == "" || sh.getClient() == nil {
 				// No usable session for doing the commit, take one from pool.
-				sh, err = t.sp.take(ctx)
+				sh, err = t.sp.takeFor(ctx, "ApplyAtLeastOnce")
 				if err != nil {
 					// sessionPool.Take already retries for session
 					// creations/retrivals.
@@ -1859,30 +2593,39 @@ func (t *writeOnlyTransaction) applyAtLeastOnce(ctx context.Context, ms ...*Muta
 						ExcludeTxnFromChangeStreams: t.excludeTxnFromChangeStreams,
 					},
 				},
-				Mutations:      mPb,
-				RequestOptions: createRequestOptions(t.commitPriority, "", t.transactionTag),
+				Mutations:         mPb,
+				RequestOptions:    createRequestOptions(t.commitPriority, "", t.transactionTag),
+				ReturnCommitStats: t.returnCommitStats,
+				MaxCommitDelay:    maxCommitDelay,
 			})
-			if err != nil && !isAbortedErr(err) {
-				if isSessionNotFoundError(err) {
-					// Discard the bad session.
-					sh.destroy()
-				}
-				return toSpannerErrorWithCommitInfo(err, true)
-			} else if err == nil {
+			if err == nil {
+				// Only the timestamp of the attempt that succeeded is
+				// returned, also if an earlier attempt failed with an error
+				// that could have been caused by a commit that succeeded.
 				if tstamp := res.GetCommitTimestamp(); tstamp != nil {
-					ts = time.Unix(tstamp.Seconds, int64(tstamp.Nanos))
+					resp.CommitTs = time.Unix(tstamp.Seconds, int64(tstamp.Nanos))
 				}
+				resp.CommitStats = res.GetCommitStats()
+				return nil
+			}
+			if isSessionNotFoundError(err) {
+				// Discard the bad session.
+				sh.destroy()
+				return toSpannerErrorWithCommitInfo(err, true)
 			}
 			delay, shouldRetry := retryer.Retry(err)
 			if !shouldRetry {
-				return err
+				return toSpannerErrorWithCommitInfo(err, true)
 			}
 			if err := gax.Sleep(ctx, delay); err != nil {
 				return err
 			}
 		}
 	}
-	return ts, applyMutationWithRetry(ctx)
+	if err := applyMutationWithRetry(ctx); err != nil {
+		return CommitResponse{}, err
+	}
+	return resp, nil
 }
 
 // isAbortedErr returns true if the error indicates that an gRPC call is
@@ -506,6 +506,247 @@ func testReadWriteStmtBasedTransaction(t *testing.T, executionTimes map[string]S
 	return rowCount, attempts, err
 }
 
+func TestRunStmtBased_UpdateAborted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodExecuteSql,
+		SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.Aborted, "Transaction aborted")},
+		})
+
+	var attempts int
+	var errs []error
+	resp, err := RunStmtBased(ctx, client, func(tx *ReadWriteStmtBasedTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		if err != nil {
+			errs = append(errs, err)
+			return fmt.Errorf("update failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if resp.CommitTs.IsZero() {
+		t.Fatal("missing commit timestamp")
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := len(errs), 1; g != w {
+		t.Fatalf("number of errors mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if !errors.Is(errs[0], ErrAbortedRetryable) {
+		t.Fatalf("error does not match ErrAbortedRetryable: %v", errs[0])
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var begins, commits int
+	for _, req := range requests {
+		switch req.(type) {
+		case *sppb.BeginTransactionRequest:
+			begins++
+		case *sppb.CommitRequest:
+			commits++
+		}
+	}
+	if g, w := begins, 2; g != w {
+		t.Fatalf("number of BeginTransaction requests mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := commits, 1; g != w {
+		t.Fatalf("number of Commit requests mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestReadWriteTransaction_AbortedHonorsRetryDelay(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	delay := 300 * time.Millisecond
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{newAbortedErrorWithRetryDelay(delay)},
+		})
+
+	var attempts int
+	start := time.Now()
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if elapsed < delay {
+		t.Fatalf("transaction was retried before the retry delay\nGot: %v\nWant: >= %v", elapsed, delay)
+	}
+}
+
+func TestReadWriteTransaction_AbortedRetryDelayIsCapped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		MaxAbortedRetryDelay: 10 * time.Millisecond,
+	})
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{newAbortedErrorWithRetryDelay(time.Minute)},
+		})
+
+	var attempts int
+	start := time.Now()
+	_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if elapsed > 10*time.Second {
+		t.Fatalf("retry delay was not capped\nGot: %v\nWant: < %v", elapsed, 10*time.Second)
+	}
+}
+
+func TestRunStmtBased_CommitAborted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.Aborted, "Transaction aborted")},
+		})
+
+	var attempts int
+	_, err := RunStmtBased(ctx, client, func(tx *ReadWriteStmtBasedTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if g, w := attempts, 2; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
+func TestRunStmtBased_AbortedRetryDelayIsCapped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		MaxAbortedRetryDelay: 10 * time.Millisecond,
+	})
+	defer teardown()
+	aborted := newAbortedErrorWithRetryDelay(time.Minute)
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{aborted, aborted, aborted},
+		})
+
+	var attempts int
+	start := time.Now()
+	resp, err := RunStmtBased(ctx, client, func(tx *ReadWriteStmtBasedTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if resp.CommitTs.IsZero() {
+		t.Fatal("missing commit timestamp")
+	}
+	if g, w := attempts, 4; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if elapsed > 10*time.Second {
+		t.Fatalf("retry delay was not capped\nGot: %v\nWant: < %v", elapsed, 10*time.Second)
+	}
+}
+
+func TestRunStmtBased_ContextDoneWhileWaitingForRetry(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{newAbortedErrorWithRetryDelay(time.Minute)},
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var attempts int
+	start := time.Now()
+	_, err := RunStmtBased(ctx, client, func(tx *ReadWriteStmtBasedTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	elapsed := time.Since(start)
+	if g, w := ErrCode(err), codes.DeadlineExceeded; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := attempts, 1; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if elapsed > 10*time.Second {
+		t.Fatalf("RunStmtBased did not stop when the context was done\nGot: %v\nWant: < %v", elapsed, 10*time.Second)
+	}
+}
+
+func TestRunStmtBased_NonAbortedErrorReturned(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	server.TestSpanner.PutExecutionTime(MethodExecuteSql,
+		SimulatedExecutionTime{
+			Errors: []error{status.Error(codes.InvalidArgument, "Invalid statement")},
+		})
+
+	var attempts int
+	_, err := RunStmtBased(ctx, client, func(tx *ReadWriteStmtBasedTransaction) error {
+		attempts++
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	})
+	if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+		t.Fatalf("error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if errors.Is(err, ErrAbortedRetryable) {
+		t.Fatalf("error unexpectedly matches ErrAbortedRetryable: %v", err)
+	}
+	if g, w := attempts, 1; g != w {
+		t.Fatalf("number of attempts mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var rollbacks int
+	for _, req := range requests {
+		if _, ok := req.(*sppb.RollbackRequest); ok {
+			rollbacks++
+		}
+	}
+	if g, w := rollbacks, 1; g != w {
+		t.Fatalf("number of Rollback requests mismatch\nGot: %v\nWant: %v", g, w)
+	}
+}
+
 func TestReadWriteStmtBasedTransactionWithOptions(t *testing.T) {
 	t.Parallel()
 
@@ -739,9 +980,13 @@ loop:
 }
 
 func newAbortedErrorWithMinimalRetryDelay() error {
+	return newAbortedErrorWithRetryDelay(time.Nanosecond)
+}
+
+func newAbortedErrorWithRetryDelay(delay time.Duration) error {
 	st := gstatus.New(codes.Aborted, "Transaction has been aborted")
 	retry := &errdetails.RetryInfo{
-		RetryDelay: durationpb.New(time.Nanosecond),
+		RetryDelay: durationpb.New(delay),
 	}
 	st, _ = st.WithDetails(retry)
 	return st.Err()
@@ -105,12 +105,51 @@ func validateNumeric(r *big.Rat) error {
 	return nil
 }
 
+// errNumericIntOutOfRange returns error for a *big.Int that has more digits
+// than the whole component of a NUMERIC value can hold.
+func errNumericIntOutOfRange(i *big.Int) error {
+	return spannerErrorf(codes.InvalidArgument, "integer %v exceeds the max precision of %d digits for the whole component of a numeric", i, NumericPrecisionDigits-NumericScaleDigits)
+}
+
+// validateNumericInt returns an error if the given integer cannot be stored
+// in a NUMERIC value.
+func validateNumericInt(i *big.Int) error {
+	if i == nil {
+		return nil
+	}
+	if len(new(big.Int).Abs(i).String()) > NumericPrecisionDigits-NumericScaleDigits {
+		return errNumericIntOutOfRange(i)
+	}
+	return nil
+}
+
+// errNonIntegralNumeric returns error for decoding a NUMERIC value that is not
+// an integer into a *big.Int.
+func errNonIntegralNumeric(s string) error {
+	return spannerErrorf(codes.InvalidArgument, "numeric value %v is not an integer and cannot be decoded into a *big.Int", s)
+}
+
+// decodeNumericInt decodes the string representation of a NUMERIC value into
+// a *big.Int. It returns an error if the value is not an integer.
+func decodeNumericInt(s string) (*big.Int, error) {
+	r, ok := (&big.Rat{}).SetString(s)
+	if !ok {
+		return nil, errUnexpectedNumericStr(s)
+	}
+	if !r.IsInt() {
+		return nil, errNonIntegralNumeric(s)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
 var (
 	// CommitTimestamp is a special value used to tell Cloud Spanner to insert
 	// the commit timestamp of the transaction into a column. It can be used in
 	// a Mutation, or directly used in InsertStruct or InsertMap. See
 	// ExampleCommitTimestamp. This is just a placeholder and the actual value
-	// stored in this variable has no meaning.
+	// stored in this variable has no meaning. Decoding a placeholder for a
+	// commit timestamp that has not yet been committed into a TIMESTAMP
+	// destination returns an error with code FailedPrecondition.
 	CommitTimestamp = commitTimestamp
 	commitTimestamp = time.Unix(0, 0).In(time.FixedZone("CommitTimestamp placeholder", 0xDB))
 
@@ -185,6 +224,30 @@ type Decoder interface {
 	DecodeSpanner(input interface{}) error
 }
 
+// ColumnDecoder is the interface implemented by a custom type that decodes
+// itself from a Cloud Spanner value together with its type. Use ColumnDecoder
+// instead of Decoder when the custom type needs the type of the column, for
+// example to decode itself from both a scalar and an array column. The value
+// that is passed to DecodeSpanner can be a NULL value. A code example:
+//
+//	type Money struct {
+//	    Units int64
+//	    Valid bool
+//	}
+//
+//	// Decode a Money value from an INT64 column
+//	func (m *Money) DecodeSpanner(val spanner.GenericColumnValue) error {
+//	    var units spanner.NullInt64
+//	    if err := val.Decode(&units); err != nil {
+//	        return err
+//	    }
+//	    m.Units, m.Valid = units.Int64, units.Valid
+//	    return nil
+//	}
+type ColumnDecoder interface {
+	DecodeSpanner(val GenericColumnValue) error
+}
+
 // NullableValue is the interface implemented by all null value wrapper types.
 type NullableValue interface {
 	// IsNull returns true if the underlying database value is null.
@@ -271,6 +334,90 @@ func (n NullInt64) GormDataType() string {
 	return "INT64"
 }
 
+// NullDuration represents a time.Duration that is stored as the number of
+// nanoseconds in a Cloud Spanner INT64 that may be NULL.
+type NullDuration struct {
+	Duration time.Duration // Duration contains the value when it is non-NULL, and zero when NULL.
+	Valid    bool          // Valid is true if Duration is not NULL.
+}
+
+// IsNull implements NullableValue.IsNull for NullDuration.
+func (n NullDuration) IsNull() bool {
+	return !n.Valid
+}
+
+// String implements Stringer.String for NullDuration
+func (n NullDuration) String() string {
+	if !n.Valid {
+		return nullString
+	}
+	return n.Duration.String()
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON for NullDuration. The
+// duration is marshaled as the number of nanoseconds.
+func (n NullDuration) MarshalJSON() ([]byte, error) {
+	return nulljson(n.Valid, int64(n.Duration))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON for NullDuration.
+func (n *NullDuration) UnmarshalJSON(payload []byte) error {
+	if payload == nil {
+		return fmt.Errorf("payload should not be nil")
+	}
+	if bytes.Equal(payload, jsonNullBytes) {
+		n.Duration = 0
+		n.Valid = false
+		return nil
+	}
+	num, err := strconv.ParseInt(string(payload), 10, 64)
+	if err != nil {
+		return fmt.Errorf("payload cannot be converted to int64: got %v", string(payload))
+	}
+	n.Duration = time.Duration(num)
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullDuration) Value() (driver.Value, error) {
+	if n.IsNull() {
+		return nil, nil
+	}
+	return int64(n.Duration), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullDuration) Scan(value interface{}) error {
+	if value == nil {
+		n.Duration, n.Valid = 0, false
+		return nil
+	}
+	n.Valid = true
+	switch p := value.(type) {
+	default:
+		return spannerErrorf(codes.InvalidArgument, "invalid type for NullDuration: %v", p)
+	case *int64:
+		n.Duration = time.Duration(*p)
+	case int64:
+		n.Duration = time.Duration(p)
+	case time.Duration:
+		n.Duration = p
+	case *NullDuration:
+		n.Duration = p.Duration
+		n.Valid = p.Valid
+	case NullDuration:
+		n.Duration = p.Duration
+		n.Valid = p.Valid
+	}
+	return nil
+}
+
+// GormDataType is used by gorm to determine the default data type for fields with this type.
+func (n NullDuration) GormDataType() string {
+	return "INT64"
+}
+
 // NullString represents a Cloud Spanner STRING that may be NULL.
 type NullString struct {
 	StringVal string // StringVal contains the value when it is non-NULL, and an empty string when NULL.
@@ -954,6 +1101,22 @@ func (n *PGNumeric) UnmarshalJSON(payload []byte) error {
 	return nil
 }
 
+// Numeric is a NUMERIC value in the exact textual form that is returned by
+// Cloud Spanner. Decoding a NUMERIC or PostgreSQL NUMERIC column into a Numeric
+// preserves the value as it was returned, including trailing zeros and
+// digits that could be lost when the value is converted to another type. A
+// Numeric cannot hold a NULL value; use NullNumeric or PGNumeric for columns
+// that can contain NULL.
+//
+// A Numeric is encoded as a NUMERIC value with the string as-is. The string is
+// not validated by the client.
+type Numeric string
+
+// String implements Stringer.String for Numeric.
+func (n Numeric) String() string {
+	return string(n)
+}
+
 // NullProtoMessage represents a Cloud Spanner PROTO that may be NULL.
 // To write a NULL value using NullProtoMessage set ProtoMessageVal to typed nil and set Valid to true.
 type NullProtoMessage struct {
@@ -1133,6 +1296,23 @@ func (v GenericColumnValue) Decode(ptr interface{}) error {
 	return decodeValue(v.Value, v.Type, ptr)
 }
 
+// DecodeProto decodes a GenericColumnValue of type PROTO into m. It returns an
+// error if the value is not of type PROTO, if the fully-qualified name of the
+// proto type of the value is not the name of the message type of m, or if the
+// value is NULL.
+func (v GenericColumnValue) DecodeProto(m proto.Message) error {
+	if m == nil || reflect.ValueOf(m).IsNil() {
+		return errNilDst(m)
+	}
+	if v.Type.GetCode() != sppb.TypeCode_PROTO {
+		return errNotProtoType(v.Type)
+	}
+	if want := string(m.ProtoReflect().Descriptor().FullName()); v.Type.GetProtoTypeFqn() != want {
+		return errProtoTypeNameMismatch(v.Type.GetProtoTypeFqn(), want)
+	}
+	return decodeValue(v.Value, v.Type, m)
+}
+
 // NewGenericColumnValue creates a GenericColumnValue from Go value that is
 // valid for Cloud Spanner.
 func newGenericColumnValue(v interface{}) (*GenericColumnValue, error) {
@@ -1190,6 +1370,18 @@ func errUnsupportedEmbeddedStructFields(fname string) error {
 		"unnamed field, use a `spanner:\"\"` field tag.", fname)
 }
 
+// errNotProtoType returns error for decoding a value that is not of type PROTO
+// with DecodeProto or ColumnProto.
+func errNotProtoType(t *sppb.Type) error {
+	return spannerErrorf(codes.InvalidArgument, "type %v is not PROTO", t.GetCode())
+}
+
+// errProtoTypeNameMismatch returns error for decoding a PROTO value into a
+// message of another type.
+func errProtoTypeNameMismatch(got, want string) error {
+	return spannerErrorf(codes.InvalidArgument, "proto type %q does not match message type %q", got, want)
+}
+
 // errDstNotForNull returns error for decoding a SQL NULL value into a destination which doesn't
 // support NULL values.
 func errDstNotForNull(dst interface{}) error {
@@ -1215,6 +1407,12 @@ func errNilNotAllowed(dst interface{}, name string) error {
 	return spannerErrorf(codes.InvalidArgument, "destination %T does not support Null values. Use %s, an array with pointer type elements to read Null values", dst, name)
 }
 
+// errCommitTimestampPlaceholder returns error for decoding the placeholder
+// value of a commit timestamp that has not yet been committed.
+func errCommitTimestampPlaceholder(v *proto3.Value) error {
+	return spannerErrorf(codes.FailedPrecondition, "%v is the placeholder of a pending commit timestamp; the commit timestamp can only be read after the transaction has been committed", v)
+}
+
 func parseNullTime(v *proto3.Value, p *NullTime, code sppb.TypeCode, isNull bool) error {
 	if p == nil {
 		return errNilDst(p)
@@ -1230,6 +1428,9 @@ func parseNullTime(v *proto3.Value, p *NullTime, code sppb.TypeCode, isNull bool
 	if err != nil {
 		return err
 	}
+	if x == commitTimestampPlaceholderString {
+		return errCommitTimestampPlaceholder(v)
+	}
 	y, err := time.Parse(time.RFC3339Nano, x)
 	if err != nil {
 		return errBadEncoding(v, err)
@@ -1461,6 +1662,46 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		case **int64:
 			*sp = &y
 		}
+	case *NullDuration:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if code != sppb.TypeCode_INT64 {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		if isNull {
+			*p = NullDuration{}
+			break
+		}
+		x, err := getStringValue(v)
+		if err != nil {
+			return err
+		}
+		y, err := strconv.ParseInt(x, 10, 64)
+		if err != nil {
+			return errBadEncoding(v, err)
+		}
+		*p = NullDuration{Duration: time.Duration(y), Valid: true}
+	case *[]NullDuration:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if acode != sppb.TypeCode_INT64 {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		if isNull {
+			*p = nil
+			break
+		}
+		x, err := getListValue(v)
+		if err != nil {
+			return err
+		}
+		y, err := decodeNullDurationArray(x)
+		if err != nil {
+			return err
+		}
+		*p = y
 	case *[]NullInt64, *[]*int64:
 		if p == nil {
 			return errNilDst(p)
@@ -1615,7 +1856,9 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		if p == nil {
 			return errNilDst(p)
 		}
-		if code != sppb.TypeCode_FLOAT64 {
+		// FLOAT32 values can also be decoded into float64 values without
+		// loss of precision.
+		if code != sppb.TypeCode_FLOAT64 && code != sppb.TypeCode_FLOAT32 {
 			return errTypeMismatch(code, acode, ptr)
 		}
 		if isNull {
@@ -1630,7 +1873,7 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		if p == nil {
 			return errNilDst(p)
 		}
-		if code != sppb.TypeCode_FLOAT64 {
+		if code != sppb.TypeCode_FLOAT64 && code != sppb.TypeCode_FLOAT32 {
 			return errTypeMismatch(code, acode, ptr)
 		}
 		if isNull {
@@ -1657,7 +1900,7 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		if p == nil {
 			return errNilDst(p)
 		}
-		if acode != sppb.TypeCode_FLOAT64 {
+		if acode != sppb.TypeCode_FLOAT64 && acode != sppb.TypeCode_FLOAT32 {
 			return errTypeMismatch(code, acode, ptr)
 		}
 		if isNull {
@@ -1691,7 +1934,7 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		if p == nil {
 			return errNilDst(p)
 		}
-		if acode != sppb.TypeCode_FLOAT64 {
+		if acode != sppb.TypeCode_FLOAT64 && acode != sppb.TypeCode_FLOAT32 {
 			return errTypeMismatch(code, acode, ptr)
 		}
 		if isNull {
@@ -1903,6 +2146,48 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 			return errUnexpectedNumericStr(x)
 		}
 		*p = y
+	case *big.Int:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if code != sppb.TypeCode_NUMERIC {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		if isNull {
+			return errDstNotForNull(ptr)
+		}
+		y, err := decodeNumericInt(v.GetStringValue())
+		if err != nil {
+			return err
+		}
+		p.Set(y)
+	case **big.Int:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if code != sppb.TypeCode_NUMERIC {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		if isNull {
+			*p = nil
+			break
+		}
+		y, err := decodeNumericInt(v.GetStringValue())
+		if err != nil {
+			return err
+		}
+		*p = y
+	case *Numeric:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if code != sppb.TypeCode_NUMERIC {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		if isNull {
+			return errDstNotForNull(ptr)
+		}
+		*p = Numeric(v.GetStringValue())
 	case *[]NullNumeric, *[]*big.Rat:
 		if p == nil {
 			return errNilDst(p)
@@ -2233,6 +2518,22 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		*p = y
 	case *GenericColumnValue:
 		*p = GenericColumnValue{Type: t, Value: v}
+	case *interface{}:
+		if p == nil {
+			return errNilDst(p)
+		}
+		if code != sppb.TypeCode_NUMERIC {
+			return errTypeMismatch(code, acode, ptr)
+		}
+		var s decodeSetting
+		for _, opt := range opts {
+			opt.Apply(&s)
+		}
+		y, err := decodeNumericInterface(v, typeAnnotation, s.NumericDecodeMode)
+		if err != nil {
+			return err
+		}
+		*p = y
 	case protoreflect.Enum:
 		if p == nil {
 			return errNilDst(p)
@@ -2322,6 +2623,12 @@ func decodeValue(v *proto3.Value, t *sppb.Type, ptr interface{}, opts ...DecodeO
 		}
 		p.Valid = true
 	default:
+		// Check if the pointer is a custom type that implements
+		// spanner.ColumnDecoder interface.
+		if decodedVal, ok := ptr.(ColumnDecoder); ok {
+			return decodedVal.DecodeSpanner(GenericColumnValue{Type: t, Value: v})
+		}
+
 		// Check if the pointer is a custom type that implements spanner.Decoder
 		// interface.
 		if decodedVal, ok := ptr.(Decoder); ok {
@@ -3340,6 +3647,21 @@ func decodeNullInt64Array(pb *proto3.ListValue) ([]NullInt64, error) {
 	return a, nil
 }
 
+// decodeNullDurationArray decodes proto3.ListValue pb into a NullDuration
+// slice.
+func decodeNullDurationArray(pb *proto3.ListValue) ([]NullDuration, error) {
+	if pb == nil {
+		return nil, errNilListValue("INT64")
+	}
+	a := make([]NullDuration, len(pb.Values))
+	for i, v := range pb.Values {
+		if err := decodeValue(v, intType(), &a[i]); err != nil {
+			return nil, errDecodeArrayElement(i, v, "INT64", err)
+		}
+	}
+	return a, nil
+}
+
 // decodeInt64PointerArray decodes proto3.ListValue pb into a *int64 slice.
 func decodeInt64PointerArray(pb *proto3.ListValue) ([]*int64, error) {
 	if pb == nil {
@@ -3832,6 +4154,18 @@ func errNoOrDupGoField(s interface{}, f string) error {
 	return spannerErrorf(codes.InvalidArgument, "Go struct %+v(type %T) has no or duplicate fields for Cloud Spanner STRUCT field %v", s, s, f)
 }
 
+// errDupRemainderField returns error for a Go struct with more than one field
+// that is tagged with `spanner:",remainder"`.
+func errDupRemainderField(s interface{}) error {
+	return spannerErrorf(codes.InvalidArgument, "Go struct %+v(type %T) has more than one remainder field", s, s)
+}
+
+// errInvalidRemainderField returns error for a remainder field that is not of
+// type map[string]GenericColumnValue.
+func errInvalidRemainderField(s interface{}, f string) error {
+	return spannerErrorf(codes.InvalidArgument, "remainder field %v of Go struct %+v(type %T) must be of type map[string]spanner.GenericColumnValue", f, s, s)
+}
+
 // errDupColNames returns error for duplicated Cloud Spanner STRUCT field names
 // found in decoding a Cloud Spanner STRUCT into a Go struct.
 func errDupSpannerField(f string, ty *sppb.StructType) error {
@@ -3852,7 +4186,8 @@ func errDecodeStructField(ty *sppb.StructType, f string, err error) error {
 
 // decodeSetting contains all the settings for decoding from spanner struct
 type decodeSetting struct {
-	Lenient bool
+	Lenient           bool
+	NumericDecodeMode NumericDecodeMode
 }
 
 // DecodeOptions is the interface to change decode struct settings
@@ -3871,6 +4206,68 @@ func WithLenient() DecodeOptions {
 	return withLenient{lenient: true}
 }
 
+type withNumericDecodeMode struct{ mode NumericDecodeMode }
+
+func (w withNumericDecodeMode) Apply(s *decodeSetting) {
+	s.NumericDecodeMode = w.mode
+}
+
+// NumericDecodeMode determines the Go type that is used for a NUMERIC value
+// that is decoded into an *interface{}. This makes it possible to use the
+// same code for GoogleSQL and PostgreSQL databases, where NUMERIC values are
+// by default decoded into different Go types.
+type NumericDecodeMode int
+
+const (
+	// NumericDecodeModeDefault decodes GoogleSQL NUMERIC values into a
+	// big.Rat and PostgreSQL NUMERIC values into a PGNumeric.
+	NumericDecodeModeDefault NumericDecodeMode = iota
+	// NumericDecodeModeRat decodes all NUMERIC values into a big.Rat.
+	// PostgreSQL NUMERIC values that cannot be represented as a big.Rat,
+	// such as NaN, cannot be decoded in this mode.
+	NumericDecodeModeRat
+	// NumericDecodeModeString decodes all NUMERIC values into a string.
+	NumericDecodeModeString
+	// NumericDecodeModePGNumeric decodes all NUMERIC values into a PGNumeric.
+	NumericDecodeModePGNumeric
+)
+
+// decodeNumericInterface decodes a NUMERIC value into an interface{} using
+// the Go type that is determined by the given mode. A NULL value is decoded
+// into nil, except for NumericDecodeModePGNumeric, which decodes NULL into an
+// invalid PGNumeric.
+func decodeNumericInterface(v *proto3.Value, typeAnnotation sppb.TypeAnnotationCode, mode NumericDecodeMode) (interface{}, error) {
+	if mode == NumericDecodeModeDefault {
+		mode = NumericDecodeModeRat
+		if typeAnnotation == sppb.TypeAnnotationCode_PG_NUMERIC {
+			mode = NumericDecodeModePGNumeric
+		}
+	}
+	_, isNull := v.Kind.(*proto3.Value_NullValue)
+	switch mode {
+	case NumericDecodeModePGNumeric:
+		if isNull {
+			return PGNumeric{}, nil
+		}
+		return PGNumeric{v.GetStringValue(), true}, nil
+	case NumericDecodeModeString:
+		if isNull {
+			return nil, nil
+		}
+		return v.GetStringValue(), nil
+	default:
+		if isNull {
+			return nil, nil
+		}
+		x := v.GetStringValue()
+		y, ok := (&big.Rat{}).SetString(x)
+		if !ok {
+			return nil, errUnexpectedNumericStr(x)
+		}
+		return *y, nil
+	}
+}
+
 // decodeStruct decodes proto3.ListValue pb into struct referenced by pointer
 // ptr, according to
 // the structural information given in sppb.StructType ty.
@@ -3899,12 +4296,31 @@ func decodeStruct(ty *sppb.StructType, pb *proto3.ListValue, ptr interface{}, le
 			}
 		}
 	}
+	rf, err := remainderField(ptr, fields)
+	if err != nil {
+		return err
+	}
 	seen := map[string]bool{}
 	for i, f := range ty.Fields {
 		if f.Name == "" {
 			return errUnnamedField(ty, i)
 		}
 		sf := fields.Match(f.Name)
+		if sf != nil && isRemainderField(sf) {
+			sf = nil
+		}
+		if sf == nil && rf != nil {
+			if seen[f.Name] {
+				return errDupSpannerField(f.Name, ty)
+			}
+			m := v.FieldByIndex(rf.Index)
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(rf.Type))
+			}
+			m.SetMapIndex(reflect.ValueOf(f.Name), reflect.ValueOf(GenericColumnValue{Type: f.Type, Value: pb.Values[i]}))
+			seen[f.Name] = true
+			continue
+		}
 		if sf == nil {
 			if lenient {
 				continue
@@ -4008,6 +4424,69 @@ func getAllFieldNames(v reflect.Value) []string {
 	return names
 }
 
+// errZeroTime returns error for encoding a zero time.Time value with
+// ClientConfig.RejectZeroTime set.
+func errZeroTime(name string) error {
+	return spannerErrorf(codes.InvalidArgument, "%s contains a zero time.Time value; use a NullTime with Valid=false to write a NULL value", name)
+}
+
+// containsZeroTime reports whether v is a zero time.Time value, or a valid
+// NullTime, a pointer or a slice that contains a zero time.Time value.
+func containsZeroTime(v interface{}) bool {
+	switch t := v.(type) {
+	case time.Time:
+		return t.IsZero()
+	case *time.Time:
+		return t != nil && t.IsZero()
+	case NullTime:
+		return t.Valid && t.Time.IsZero()
+	case *NullTime:
+		return t != nil && t.Valid && t.Time.IsZero()
+	case []time.Time:
+		for _, e := range t {
+			if e.IsZero() {
+				return true
+			}
+		}
+	case []*time.Time:
+		for _, e := range t {
+			if e != nil && e.IsZero() {
+				return true
+			}
+		}
+	case []NullTime:
+		for _, e := range t {
+			if e.Valid && e.Time.IsZero() {
+				return true
+			}
+		}
+	case []*NullTime:
+		for _, e := range t {
+			if e != nil && e.Valid && e.Time.IsZero() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emptyStringAsNull returns a NULL NullString if v is an empty string or a
+// pointer to an empty string, and otherwise v. The second return value
+// reports whether v was replaced. NullString values are not replaced.
+func emptyStringAsNull(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case string:
+		if t == "" {
+			return NullString{}, true
+		}
+	case *string:
+		if t != nil && *t == "" {
+			return NullString{}, true
+		}
+	}
+	return v, false
+}
+
 // errEncoderUnsupportedType returns error for not being able to encode a value
 // of certain type.
 func errEncoderUnsupportedType(v interface{}) error {
@@ -4113,6 +4592,19 @@ func encodeValue(v interface{}) (*proto3.Value, *sppb.Type, error) {
 			}
 		}
 		pt = listType(intType())
+	case NullDuration:
+		if v.Valid {
+			return encodeValue(int64(v.Duration))
+		}
+		pt = intType()
+	case []NullDuration:
+		if v != nil {
+			pb, err = encodeArray(len(v), func(i int) interface{} { return v[i] })
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		pt = listType(intType())
 	case *int64:
 		if v != nil {
 			return encodeValue(*v)
@@ -4339,6 +4831,17 @@ func encodeValue(v interface{}) (*proto3.Value, *sppb.Type, error) {
 			}
 		}
 		pt = listType(numericType())
+	case *big.Int:
+		if err := validateNumericInt(v); err != nil {
+			return nil, nil, err
+		}
+		if v != nil {
+			pb.Kind = stringKind(v.String())
+		}
+		pt = numericType()
+	case Numeric:
+		pb.Kind = stringKind(string(v))
+		pt = numericType()
 	case time.Time:
 		if v == commitTimestamp {
 			pb.Kind = stringKind(commitTimestampPlaceholderString)
@@ -4833,12 +5336,13 @@ func isSupportedMutationType(v interface{}) bool {
 	case nil, string, *string, NullString, []string, []*string, []NullString,
 		[]byte, [][]byte,
 		int, []int, int64, *int64, []int64, []*int64, NullInt64, []NullInt64,
+		time.Duration, NullDuration, []NullDuration,
 		bool, *bool, []bool, []*bool, NullBool, []NullBool,
 		float64, *float64, []float64, []*float64, NullFloat64, []NullFloat64,
 		float32, *float32, []float32, []*float32, NullFloat32, []NullFloat32,
 		time.Time, *time.Time, []time.Time, []*time.Time, NullTime, []NullTime,
 		civil.Date, *civil.Date, []civil.Date, []*civil.Date, NullDate, []NullDate,
-		big.Rat, *big.Rat, []big.Rat, []*big.Rat, NullNumeric, []NullNumeric,
+		big.Rat, *big.Rat, []big.Rat, []*big.Rat, NullNumeric, []NullNumeric, *big.Int, Numeric,
 		GenericColumnValue, proto.Message, protoreflect.Enum, NullProtoMessage, NullProtoEnum:
 		return true
 	default:
@@ -4913,16 +5417,49 @@ func encodeProtoEnumArray(len int, at func(int) reflect.Value) (*proto3.Value, e
 	return listProto(vs...), nil
 }
 
+// remainderTag is the ParsedTag of a struct field that is tagged with
+// `spanner:",remainder"`.
+type remainderTag struct{}
+
 func spannerTagParser(t reflect.StructTag) (name string, keep bool, other interface{}, err error) {
 	if s := t.Get("spanner"); s != "" {
 		if s == "-" {
 			return "", false, nil, nil
 		}
+		if s == ",remainder" {
+			return "", true, remainderTag{}, nil
+		}
 		return s, true, nil, nil
 	}
 	return "", true, nil, nil
 }
 
+// isRemainderField returns true if f is tagged with `spanner:",remainder"`.
+func isRemainderField(f *fields.Field) bool {
+	_, ok := f.ParsedTag.(remainderTag)
+	return ok
+}
+
+// remainderField returns the field of fs that is tagged with
+// `spanner:",remainder"`, or nil if there is no such field. The field must be
+// of type map[string]GenericColumnValue.
+func remainderField(ptr interface{}, fs fields.List) (*fields.Field, error) {
+	var rf *fields.Field
+	for i := range fs {
+		if !isRemainderField(&fs[i]) {
+			continue
+		}
+		if rf != nil {
+			return nil, errDupRemainderField(ptr)
+		}
+		if fs[i].Type != reflect.TypeOf(map[string]GenericColumnValue(nil)) {
+			return nil, errInvalidRemainderField(ptr, fs[i].Name)
+		}
+		rf = &fs[i]
+	}
+	return rf, nil
+}
+
 var fieldCache = fields.NewCache(spannerTagParser, nil, nil)
 
 func trimDoubleQuotes(payload []byte) ([]byte, error) {
@@ -32,6 +32,7 @@ import (
 	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
 	pb "cloud.google.com/go/spanner/testdata/protos"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
@@ -2047,6 +2048,230 @@ func TestDecodeValue(t *testing.T) {
 	}
 }
 
+// Test encoding and decoding time.Duration values as INTERVAL values.
+func TestDurationRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		in   interface{}
+		want NullDuration
+	}{
+		{"positive", 90 * time.Minute, NullDuration{Duration: 90 * time.Minute, Valid: true}},
+		{"negative", -1500 * time.Millisecond, NullDuration{Duration: -1500 * time.Millisecond, Valid: true}},
+		{"positive NullDuration", NullDuration{Duration: time.Nanosecond, Valid: true}, NullDuration{Duration: time.Nanosecond, Valid: true}},
+		{"NULL", NullDuration{}, NullDuration{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			v, typ, err := encodeValue(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := typ.Code, sppb.TypeCode_INT64; g != w {
+				t.Fatalf("type mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			// The value is stored as the number of nanoseconds.
+			var n NullInt64
+			if err := decodeValue(v, typ, &n); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := n, (NullInt64{Int64: int64(test.want.Duration), Valid: test.want.Valid}); g != w {
+				t.Fatalf("INT64 value mismatch\nGot: %v\nWant: %v", g, w)
+			}
+			var got NullDuration
+			if err := decodeValue(v, typ, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("NullDuration mismatch\nGot: %v\nWant: %v", got, test.want)
+			}
+			var d time.Duration
+			err = decodeValue(v, typ, &d)
+			if test.want.Valid {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if d != test.want.Duration {
+					t.Fatalf("time.Duration mismatch\nGot: %v\nWant: %v", d, test.want.Duration)
+				}
+			} else if err == nil {
+				t.Fatalf("decoding NULL into time.Duration returned no error")
+			}
+		})
+	}
+
+	in := []NullDuration{{Duration: time.Second, Valid: true}, {}, {Duration: -time.Second, Valid: true}}
+	v, typ, err := encodeValue(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []NullDuration
+	if err := decodeValue(v, typ, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(got, in) {
+		t.Fatalf("[]NullDuration mismatch\nGot: %v\nWant: %v", got, in)
+	}
+	var wrongType NullDuration
+	if err := decodeValue(stringProto("1"), stringType(), &wrongType); err == nil {
+		t.Fatal("decoding a STRING value into NullDuration returned no error")
+	}
+}
+
+func TestBigIntNumeric(t *testing.T) {
+	large, _ := new(big.Int).SetString("-12345678901234567890123456789", 10)
+	for _, in := range []*big.Int{large, big.NewInt(42), big.NewInt(0)} {
+		pb, pt, err := encodeValue(in)
+		if err != nil {
+			t.Fatalf("encodeValue(%v): %v", in, err)
+		}
+		if !testEqual(pt, numericType()) {
+			t.Errorf("type mismatch\nGot: %v\nWant: %v", pt, numericType())
+		}
+		got := new(big.Int)
+		if err := decodeValue(pb, pt, got); err != nil {
+			t.Fatalf("decodeValue(%v): %v", pb, err)
+		}
+		if got.Cmp(in) != 0 {
+			t.Errorf("round trip mismatch\nGot: %v\nWant: %v", got, in)
+		}
+		var gotPtr *big.Int
+		if err := decodeValue(pb, pt, &gotPtr); err != nil {
+			t.Fatalf("decodeValue(%v): %v", pb, err)
+		}
+		if gotPtr.Cmp(in) != 0 {
+			t.Errorf("round trip mismatch\nGot: %v\nWant: %v", gotPtr, in)
+		}
+	}
+
+	// A nil *big.Int is encoded as NULL, and NULL is decoded as a nil *big.Int.
+	var nilInt *big.Int
+	pb, _, err := encodeValue(nilInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !testEqual(pb, nullProto()) {
+		t.Errorf("encoded value mismatch\nGot: %v\nWant: %v", pb, nullProto())
+	}
+	gotPtr := big.NewInt(1)
+	if err := decodeValue(nullProto(), numericType(), &gotPtr); err != nil {
+		t.Fatal(err)
+	}
+	if gotPtr != nil {
+		t.Errorf("decoded value mismatch\nGot: %v\nWant: nil", gotPtr)
+	}
+	if err := decodeValue(nullProto(), numericType(), new(big.Int)); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+
+	// Integers with more than 29 digits do not fit in a NUMERIC.
+	tooLarge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	if _, _, err := encodeValue(tooLarge); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+
+	// A NUMERIC that is not an integer cannot be decoded into a *big.Int.
+	if err := decodeValue(stringProto("1.5"), numericType(), new(big.Int)); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+	var nonIntegral *big.Int
+	if err := decodeValue(stringProto("-0.000000001"), numericType(), &nonIntegral); ErrCode(err) != codes.InvalidArgument {
+		t.Errorf("error code mismatch\nGot: %v\nWant: %v", ErrCode(err), codes.InvalidArgument)
+	}
+	// An integral NUMERIC with a fractional part of zeros can be decoded.
+	got := new(big.Int)
+	if err := decodeValue(stringProto("7.000000000"), numericType(), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Int64() != 7 {
+		t.Errorf("decoded value mismatch\nGot: %v\nWant: %v", got, 7)
+	}
+}
+
+func TestNullableArrayRoundTrip(t *testing.T) {
+	tm := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	d := civil.Date{Year: 2026, Month: 1, Day: 2}
+	for _, test := range []struct {
+		name  string
+		full  interface{}
+		nulls interface{}
+		empty interface{}
+	}{
+		{
+			"NullInt64",
+			[]NullInt64{{1, true}, {2, true}},
+			[]NullInt64{{1, true}, {}, {3, true}},
+			[]NullInt64{},
+		},
+		{
+			"NullFloat64",
+			[]NullFloat64{{1.5, true}, {-2.5, true}},
+			[]NullFloat64{{}, {1.5, true}, {}},
+			[]NullFloat64{},
+		},
+		{
+			"NullBool",
+			[]NullBool{{true, true}, {false, true}},
+			[]NullBool{{true, true}, {}, {false, true}},
+			[]NullBool{},
+		},
+		{
+			"NullString",
+			[]NullString{{"a", true}, {"", true}},
+			[]NullString{{"a", true}, {}, {"", true}},
+			[]NullString{},
+		},
+		{
+			"NullTime",
+			[]NullTime{{tm, true}, {tm.Add(time.Hour), true}},
+			[]NullTime{{}, {tm, true}},
+			[]NullTime{},
+		},
+		{
+			"NullDate",
+			[]NullDate{{d, true}, {d.AddDays(1), true}},
+			[]NullDate{{d, true}, {}},
+			[]NullDate{},
+		},
+		{
+			"NullNumeric",
+			[]NullNumeric{{*big.NewRat(3, 2), true}, {*big.NewRat(-1, 1), true}},
+			[]NullNumeric{{*big.NewRat(3, 2), true}, {}, {*big.NewRat(-1, 4), true}},
+			[]NullNumeric{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, in := range []interface{}{test.full, test.nulls, test.empty} {
+				v, typ, err := encodeValue(in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := reflect.New(reflect.TypeOf(in))
+				if err := decodeValue(v, typ, got.Interface()); err != nil {
+					t.Fatal(err)
+				}
+				if got.Elem().IsNil() {
+					t.Fatalf("decoded %v as a nil slice", in)
+				}
+				if !testEqual(got.Elem().Interface(), in) {
+					t.Fatalf("round-trip mismatch\nGot: %v\nWant: %v", got.Elem().Interface(), in)
+				}
+			}
+			// A NULL array is decoded as a nil slice.
+			_, typ, err := encodeValue(test.full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := reflect.New(reflect.TypeOf(test.full))
+			got.Elem().Set(reflect.ValueOf(test.full))
+			if err := decodeValue(nullProto(), typ, got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Elem().IsNil() {
+				t.Fatalf("NULL array mismatch\nGot: %v\nWant: nil", got.Elem().Interface())
+			}
+		})
+	}
+}
+
 // Test error cases for decodeValue.
 func TestDecodeValueErrors(t *testing.T) {
 	var s string
@@ -2282,6 +2507,65 @@ func TestFloat32NaN(t *testing.T) {
 	}
 }
 
+// Test decoding FLOAT32 values into float64 values.
+func TestFloat32DecodeToFloat64(t *testing.T) {
+	for _, in := range []float32{1.5, -0.25, 0, float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.NaN())} {
+		v, typ, err := encodeValue(in)
+		if err != nil {
+			t.Fatalf("encodeValue(%v) failed: %v", in, err)
+		}
+		if typ.Code != sppb.TypeCode_FLOAT32 {
+			t.Fatalf("type mismatch for %v\nGot: %v\nWant: %v", in, typ.Code, sppb.TypeCode_FLOAT32)
+		}
+		want := float64(in)
+		var f32 float32
+		var f64 float64
+		var nf64 NullFloat64
+		var pf64 *float64
+		for _, ptr := range []interface{}{&f32, &f64, &nf64, &pf64} {
+			if err := decodeValue(v, typ, ptr); err != nil {
+				t.Fatalf("decodeValue(%v) into %T failed: %v", in, ptr, err)
+			}
+		}
+		for _, got := range []float64{float64(f32), f64, nf64.Float64, *pf64} {
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("decoded value mismatch for %v\nGot: %v\nWant: %v", in, got, want)
+			}
+		}
+		if !nf64.Valid {
+			t.Errorf("NullFloat64 for %v is not valid", in)
+		}
+	}
+
+	// Arrays and NULL values.
+	arr := listProto(float32Proto(1.5), nullProto(), float32Proto(float32(math.Inf(-1))))
+	var nfs []NullFloat64
+	if err := decodeValue(arr, listType(float32Type()), &nfs); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := nfs, []NullFloat64{{1.5, true}, {}, {math.Inf(-1), true}}; !testEqual(g, w) {
+		t.Errorf("array mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var fs []float64
+	if err := decodeValue(listProto(float32Proto(1.5), float32Proto(-0.25)), listType(float32Type()), &fs); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fs, []float64{1.5, -0.25}; !testEqual(g, w) {
+		t.Errorf("array mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	nf64 := NullFloat64{Float64: 1, Valid: true}
+	if err := decodeValue(nullProto(), float32Type(), &nf64); err != nil {
+		t.Fatal(err)
+	}
+	if nf64.Valid {
+		t.Errorf("NULL FLOAT32 decoded into valid NullFloat64: %v", nf64)
+	}
+	var f64 float64
+	if err := decodeValue(nullProto(), float32Type(), &f64); err == nil {
+		t.Error("decoding NULL FLOAT32 into float64: missing error")
+	}
+}
+
 func TestGenericColumnValue(t *testing.T) {
 	for _, test := range []struct {
 		in   GenericColumnValue